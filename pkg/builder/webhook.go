@@ -150,6 +150,9 @@ func (blder *WebhookBuilder) registerDefaultingWebhook() {
 	mwh := blder.getDefaultingWebhook()
 	if mwh != nil {
 		mwh.LogConstructor = blder.logConstructor
+		if getter, ok := blder.mgr.(manager.TracerProviderGetter); ok {
+			mwh.TracerProvider = getter.GetTracerProvider()
+		}
 		path := generateMutatePath(blder.gvk)
 
 		// Checking if the path is already registered.
@@ -181,6 +184,9 @@ func (blder *WebhookBuilder) registerValidatingWebhook() {
 	vwh := blder.getValidatingWebhook()
 	if vwh != nil {
 		vwh.LogConstructor = blder.logConstructor
+		if getter, ok := blder.mgr.(manager.TracerProviderGetter); ok {
+			vwh.TracerProvider = getter.GetTracerProvider()
+		}
 		path := generateValidatePath(blder.gvk)
 
 		// Checking if the path is already registered.
@@ -187,6 +187,10 @@ type Options struct {
 	// ByObject restricts the cache's ListWatch to the desired fields per GVK at the specified object.
 	// object, this will fall through to Default* settings.
 	ByObject map[client.Object]ByObject
+
+	// client, if set, lists and watches objects instead of the clients
+	// created from the rest config. It is set by NewFromClient.
+	client client.WithWatch
 }
 
 // ByObject offers more fine-grained control over the cache's ListWatch by object.
@@ -301,6 +305,28 @@ func New(cfg *rest.Config, opts Options) (Cache, error) {
 	return delegating, nil
 }
 
+// NewFromClient returns a Cache whose informers list and watch objects with c
+// instead of an API server, so that controllers can be run end to end against
+// the in-memory objects of a fake client in unit tests. The Scheme and Mapper
+// default to the ones of c. To use it in a manager, set NewCache to
+//
+//	func(_ *rest.Config, opts cache.Options) (cache.Cache, error) {
+//		return cache.NewFromClient(c, opts)
+//	}
+//
+// and MapperProvider to a function that returns c.RESTMapper(), as the default
+// mapper of a manager needs an API server.
+func NewFromClient(c client.WithWatch, opts Options) (Cache, error) {
+	if opts.Scheme == nil {
+		opts.Scheme = c.Scheme()
+	}
+	if opts.Mapper == nil {
+		opts.Mapper = c.RESTMapper()
+	}
+	opts.client = c
+	return New(&rest.Config{}, opts)
+}
+
 func optionDefaultsToConfig(opts *Options) Config {
 	return Config{
 		LabelSelector:         opts.DefaultLabelSelector,
@@ -337,6 +363,7 @@ func newCache(restConfig *rest.Config, opts Options) newCacheFunc {
 				},
 				Transform:             config.Transform,
 				UnsafeDisableDeepCopy: pointer.BoolDeref(config.UnsafeDisableDeepCopy, false),
+				Client:                opts.client,
 			}),
 			readerFailOnMissingInformer: opts.ReaderFailOnMissingInformer,
 		}
@@ -109,6 +109,15 @@ func (dbt *delegatingByGVKCache) IndexField(ctx context.Context, obj client.Obje
 	return cache.IndexField(ctx, obj, field, extractValue)
 }
 
+// InScope implements client.ScopedReader.
+func (dbt *delegatingByGVKCache) InScope(obj client.Object) client.Scope {
+	cache, err := dbt.cacheForObject(obj)
+	if err != nil {
+		return client.ScopeUnknown
+	}
+	return inScope(cache, obj)
+}
+
 func (dbt *delegatingByGVKCache) cacheForObject(o runtime.Object) (Cache, error) {
 	gvk, err := apiutil.GVKForObject(o, dbt.scheme)
 	if err != nil {
@@ -34,9 +34,10 @@ import (
 )
 
 var (
-	_ Informers     = &informerCache{}
-	_ client.Reader = &informerCache{}
-	_ Cache         = &informerCache{}
+	_ Informers           = &informerCache{}
+	_ client.Reader       = &informerCache{}
+	_ Cache               = &informerCache{}
+	_ client.ScopedReader = &informerCache{}
 )
 
 // ErrCacheNotStarted is returned when trying to read from the cache that wasn't started.
@@ -70,6 +71,16 @@ type informerCache struct {
 	readerFailOnMissingInformer bool
 }
 
+// inScope returns whether cache holds obj if it exists. Caches that don't
+// implement client.ScopedReader are assumed to hold all objects.
+func inScope(cache Cache, obj client.Object) client.Scope {
+	scoped, ok := cache.(client.ScopedReader)
+	if !ok {
+		return client.ScopeIn
+	}
+	return scoped.InScope(obj)
+}
+
 // Get implements Reader.
 func (ic *informerCache) Get(ctx context.Context, key client.ObjectKey, out client.Object, opts ...client.GetOption) error {
 	gvk, err := apiutil.GVKForObject(out, ic.scheme)
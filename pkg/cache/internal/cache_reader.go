@@ -111,10 +111,6 @@ func (c *CacheReader) List(_ context.Context, out client.ObjectList, opts ...cli
 	listOpts := client.ListOptions{}
 	listOpts.ApplyOptions(opts)
 
-	if listOpts.Continue != "" {
-		return fmt.Errorf("continue list option is not supported by the cache")
-	}
-
 	switch {
 	case listOpts.FieldSelector != nil:
 		// TODO(directxman12): support more complicated field selectors by
@@ -140,15 +136,8 @@ func (c *CacheReader) List(_ context.Context, out client.ObjectList, opts ...cli
 		labelSel = listOpts.LabelSelector
 	}
 
-	limitSet := listOpts.Limit > 0
-
-	runtimeObjs := make([]runtime.Object, 0, len(objs))
+	matchingObjs := make([]runtime.Object, 0, len(objs))
 	for _, item := range objs {
-		// if the Limit option is set and the number of items
-		// listed exceeds this limit, then stop reading.
-		if limitSet && int64(len(runtimeObjs)) >= listOpts.Limit {
-			break
-		}
 		obj, isObj := item.(runtime.Object)
 		if !isObj {
 			return fmt.Errorf("cache contained %T, which is not an Object", item)
@@ -163,7 +152,17 @@ func (c *CacheReader) List(_ context.Context, out client.ObjectList, opts ...cli
 				continue
 			}
 		}
+		matchingObjs = append(matchingObjs, obj)
+	}
+
+	// Paginate before copying, so only the objects of the page are copied.
+	page, continueToken, err := Paginate(matchingObjs, listOpts.Limit, listOpts.Continue, false)
+	if err != nil {
+		return err
+	}
 
+	runtimeObjs := make([]runtime.Object, 0, len(page))
+	for _, obj := range page {
 		var outObj runtime.Object
 		if c.disableDeepCopy || (listOpts.UnsafeDisableDeepCopy != nil && *listOpts.UnsafeDisableDeepCopy) {
 			// skip deep copy which might be unsafe
@@ -175,6 +174,7 @@ func (c *CacheReader) List(_ context.Context, out client.ObjectList, opts ...cli
 		}
 		runtimeObjs = append(runtimeObjs, outObj)
 	}
+	out.SetContinue(continueToken)
 	return apimeta.SetList(out, runtimeObjs)
 }
 
@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// makeClientListWatcher returns a ListWatch that lists and watches the objects
+// of gvk with ip.client.
+//
+// Clients that don't support resourceVersions, like the fake client, can't
+// resume a watch where a list ended. To not miss events between the list and
+// the watch, the watch is started before the first page of a list is requested
+// and returned by the next call of WatchFunc.
+func (ip *Informers) makeClientListWatcher(gvk schema.GroupVersionKind, obj runtime.Object, namespace string) (*cache.ListWatch, error) {
+	newList, err := ip.newListFunc(gvk, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu      sync.Mutex
+		pending watch.Interface
+	)
+	watchObjects := func(opts metav1.ListOptions) (watch.Interface, error) {
+		opts.Watch = true
+		listOpts, err := clientListOptions(namespace, opts)
+		if err != nil {
+			return nil, err
+		}
+		w, err := ip.client.Watch(ip.ctx, newList(), listOpts)
+		if err != nil {
+			return nil, err
+		}
+		switch obj.(type) {
+		case runtime.Unstructured:
+			return newUnstructuredWatcher(gvk, w), nil
+		case *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
+			return newGVKFixupWatcher(gvk, w), nil
+		default:
+			return w, nil
+		}
+	}
+
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			var w watch.Interface
+			if opts.Continue == "" {
+				var err error
+				w, err = watchObjects(metav1.ListOptions{LabelSelector: opts.LabelSelector, FieldSelector: opts.FieldSelector})
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			listOpts, err := clientListOptions(namespace, opts)
+			if err != nil {
+				return nil, err
+			}
+			list := newList()
+			if err := ip.client.List(ip.ctx, list, listOpts); err != nil {
+				if w != nil {
+					w.Stop()
+				}
+				return nil, err
+			}
+			if list, isMetadataList := list.(*metav1.PartialObjectMetadataList); isMetadataList {
+				for i := range list.Items {
+					list.Items[i].SetGroupVersionKind(gvk)
+				}
+			}
+
+			if w != nil {
+				mu.Lock()
+				if pending != nil {
+					pending.Stop()
+				}
+				pending = w
+				mu.Unlock()
+			}
+			return list, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			mu.Lock()
+			w := pending
+			pending = nil
+			mu.Unlock()
+			if w != nil {
+				return w, nil
+			}
+			return watchObjects(opts)
+		},
+	}, nil
+}
+
+// newListFunc returns a function that returns empty lists of the objects of
+// gvk, of the same type as obj.
+func (ip *Informers) newListFunc(gvk schema.GroupVersionKind, obj runtime.Object) (func() client.ObjectList, error) {
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	switch obj.(type) {
+	case runtime.Unstructured:
+		return func() client.ObjectList {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(listGVK)
+			return list
+		}, nil
+	case *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
+		return func() client.ObjectList {
+			list := &metav1.PartialObjectMetadataList{}
+			list.SetGroupVersionKind(listGVK)
+			return list
+		}, nil
+	default:
+		listObj, err := ip.scheme.New(listGVK)
+		if err != nil {
+			return nil, err
+		}
+		if _, isObjectList := listObj.(client.ObjectList); !isObjectList {
+			return nil, fmt.Errorf("%T is not a client.ObjectList", listObj)
+		}
+		return func() client.ObjectList {
+			return listObj.DeepCopyObject().(client.ObjectList)
+		}, nil
+	}
+}
+
+// clientListOptions converts the options of a ListWatch to the options of a
+// client.
+func clientListOptions(namespace string, opts metav1.ListOptions) (*client.ListOptions, error) {
+	listOpts := &client.ListOptions{
+		Namespace: namespace,
+		Limit:     opts.Limit,
+		Continue:  opts.Continue,
+		Raw:       &opts,
+	}
+	if opts.LabelSelector != "" {
+		selector, err := labels.Parse(opts.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+		listOpts.LabelSelector = selector
+	}
+	if opts.FieldSelector != "" {
+		selector, err := fields.ParseSelector(opts.FieldSelector)
+		if err != nil {
+			return nil, err
+		}
+		listOpts.FieldSelector = selector
+	}
+	return listOpts, nil
+}
+
+// newUnstructuredWatcher converts the objects of the events of watcher to
+// unstructured objects of gvk, as clients may return typed objects for kinds
+// that are registered in their scheme.
+func newUnstructuredWatcher(gvk schema.GroupVersionKind, watcher watch.Interface) watch.Interface {
+	return watch.Filter(watcher, func(in watch.Event) (watch.Event, bool) {
+		if _, isUnstructured := in.Object.(runtime.Unstructured); isUnstructured || in.Type == watch.Error {
+			return in, true
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(in.Object)
+		if err != nil {
+			status := apierrors.NewInternalError(err).Status()
+			return watch.Event{Type: watch.Error, Object: &status}, true
+		}
+		u := &unstructured.Unstructured{Object: content}
+		u.SetGroupVersionKind(gvk)
+		in.Object = u
+		return in, true
+	})
+}
@@ -35,6 +35,7 @@ import (
 	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
 
@@ -48,6 +49,10 @@ type InformersOpts struct {
 	Selector              Selector
 	Transform             cache.TransformFunc
 	UnsafeDisableDeepCopy bool
+
+	// Client, if set, is used to list and watch objects instead of the
+	// REST clients created from the config.
+	Client client.WithWatch
 }
 
 // NewInformers creates a new InformersMap that can create informers under the hood.
@@ -70,6 +75,7 @@ func NewInformers(config *rest.Config, options *InformersOpts) *Informers {
 		selector:              options.Selector,
 		transform:             options.Transform,
 		unsafeDisableDeepCopy: options.UnsafeDisableDeepCopy,
+		client:                options.Client,
 	}
 }
 
@@ -143,6 +149,18 @@ type Informers struct {
 	selector              Selector
 	transform             cache.TransformFunc
 	unsafeDisableDeepCopy bool
+
+	// client lists and watches objects instead of REST clients, if set
+	client client.WithWatch
+}
+
+// InScope returns whether the informers hold obj if it exists, i.e. whether it
+// is in the namespace and matches the selector the informers are restricted to.
+func (ip *Informers) InScope(obj client.Object) client.Scope {
+	if ip.namespace != "" && obj.GetNamespace() != "" && obj.GetNamespace() != ip.namespace {
+		return client.ScopeOut
+	}
+	return ip.selector.Matches(obj)
 }
 
 // Start calls Run on each of the informers and sets started to true. Blocks on the context.
@@ -346,6 +364,10 @@ func (ip *Informers) makeListWatcher(gvk schema.GroupVersionKind, obj runtime.Ob
 		namespace = restrictNamespaceBySelector(ip.namespace, ip.selector)
 	}
 
+	if ip.client != nil {
+		return ip.makeClientListWatcher(gvk, obj, namespace)
+	}
+
 	switch obj.(type) {
 	//
 	// Unstructured
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// continueToken is the content of the continue tokens of the cache. Unlike the
+// tokens of the API server, they don't refer to a consistent snapshot: the
+// next page starts after the last object of the previous one in the cache as
+// it is at the time of the next List.
+type continueToken struct {
+	StartKey string `json:"start"`
+}
+
+// Paginate returns the page of items the limit and continue options of a List
+// select and the continue token of the next page, if there is one. Like the
+// API server, it orders the items by namespace and name. If neither limit nor
+// continue is set, items are returned as they are. hasMore tells that more
+// objects follow the items, even if there are no more than limit of them.
+func Paginate(items []runtime.Object, limit int64, continueValue string, hasMore bool) ([]runtime.Object, string, error) {
+	if limit <= 0 && continueValue == "" {
+		return items, "", nil
+	}
+
+	keys := make(map[runtime.Object]string, len(items))
+	for _, item := range items {
+		accessor, err := apimeta.Accessor(item)
+		if err != nil {
+			return nil, "", err
+		}
+		keys[item] = accessor.GetNamespace() + "/" + accessor.GetName()
+	}
+	sort.SliceStable(items, func(i, j int) bool { return keys[items[i]] < keys[items[j]] })
+
+	if continueValue != "" {
+		token, err := decodeContinueToken(continueValue)
+		if err != nil {
+			return nil, "", apierrors.NewBadRequest(fmt.Sprintf("continue key is not valid: %v", err))
+		}
+		start := sort.Search(len(items), func(i int) bool { return keys[items[i]] >= token.StartKey })
+		items = items[start:]
+	}
+
+	if limit <= 0 || len(items) == 0 || (int64(len(items)) <= limit && !hasMore) {
+		return items, "", nil
+	}
+	if int64(len(items)) > limit {
+		items = items[:limit]
+	}
+	next, err := encodeContinueToken(continueToken{StartKey: keys[items[len(items)-1]] + "\x00"})
+	if err != nil {
+		return nil, "", err
+	}
+	return items, next, nil
+}
+
+func encodeContinueToken(token continueToken) (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeContinueToken(value string) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	token := &continueToken{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+	if token.StartKey == "" {
+		return nil, fmt.Errorf("unsupported continue token %q", value)
+	}
+	return token, nil
+}
@@ -20,6 +20,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Selector specify the label/field selector to fill in ListOptions.
@@ -37,3 +40,33 @@ func (s Selector) ApplyToList(listOpts *metav1.ListOptions) {
 		listOpts.FieldSelector = s.Field.String()
 	}
 }
+
+// Matches returns whether obj matches the selector. Field selectors can only be
+// evaluated for metadata.name and metadata.namespace, so it's unknown whether
+// objects match selectors on other fields, unless they don't match the rest of
+// the selector.
+func (s Selector) Matches(obj client.Object) client.Scope {
+	if s.Label != nil && !s.Label.Matches(labels.Set(obj.GetLabels())) {
+		return client.ScopeOut
+	}
+	if s.Field == nil {
+		return client.ScopeIn
+	}
+	scope := client.ScopeIn
+	for _, req := range s.Field.Requirements() {
+		var value string
+		switch req.Field {
+		case "metadata.name":
+			value = obj.GetName()
+		case "metadata.namespace":
+			value = obj.GetNamespace()
+		default:
+			scope = client.ScopeUnknown
+			continue
+		}
+		if (req.Operator == selection.NotEquals) == (value == req.Value) {
+			return client.ScopeOut
+		}
+	}
+	return scope
+}
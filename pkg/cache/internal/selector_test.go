@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Selector", func() {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", Labels: map[string]string{"app": "test"}}}
+
+	DescribeTable("should tell whether an object matches",
+		func(selector Selector, expected client.Scope) {
+			Expect(selector.Matches(pod)).To(Equal(expected))
+		},
+		Entry("without selectors", Selector{}, client.ScopeIn),
+		Entry("with a matching label selector", Selector{Label: labels.SelectorFromSet(labels.Set{"app": "test"})}, client.ScopeIn),
+		Entry("with a label selector that doesn't match", Selector{Label: labels.SelectorFromSet(labels.Set{"app": "other"})}, client.ScopeOut),
+		Entry("with a matching field selector on the name", Selector{Field: fields.OneTermEqualSelector("metadata.name", "pod")}, client.ScopeIn),
+		Entry("with a field selector on the namespace that doesn't match", Selector{Field: fields.OneTermNotEqualSelector("metadata.namespace", "default")}, client.ScopeOut),
+		Entry("with a field selector on another field", Selector{Field: fields.OneTermEqualSelector("spec.nodeName", "node")}, client.ScopeUnknown),
+		Entry("with a field selector on another field and the name that doesn't match",
+			Selector{Field: fields.AndSelectors(fields.OneTermEqualSelector("spec.nodeName", "node"), fields.OneTermEqualSelector("metadata.name", "other"))}, client.ScopeOut),
+		Entry("with a field selector on another field and a label selector that doesn't match",
+			Selector{Label: labels.SelectorFromSet(labels.Set{"app": "other"}), Field: fields.OneTermEqualSelector("spec.nodeName", "node")}, client.ScopeOut),
+	)
+})
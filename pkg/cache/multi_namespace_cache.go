@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	toolscache "k8s.io/client-go/tools/cache"
 
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
@@ -73,6 +74,7 @@ type multiNamespaceCache struct {
 }
 
 var _ Cache = &multiNamespaceCache{}
+var _ client.ScopedReader = &multiNamespaceCache{}
 
 // Methods for multiNamespaceCache to conform to the Informers interface.
 
@@ -215,6 +217,25 @@ func (c *multiNamespaceCache) Get(ctx context.Context, key client.ObjectKey, obj
 	return cache.Get(ctx, key, obj, opts...)
 }
 
+// InScope implements client.ScopedReader.
+func (c *multiNamespaceCache) InScope(obj client.Object) client.Scope {
+	isNamespaced, err := apiutil.IsObjectNamespaced(obj, c.Scheme, c.RESTMapper)
+	if err != nil {
+		return client.ScopeUnknown
+	}
+	if !isNamespaced {
+		if c.clusterCache == nil {
+			return client.ScopeOut
+		}
+		return inScope(c.clusterCache, obj)
+	}
+	cache, ok := c.namespaceToCache[obj.GetNamespace()]
+	if !ok {
+		return client.ScopeOut
+	}
+	return inScope(cache, obj)
+}
+
 // List multi namespace cache will get all the objects in the namespaces that the cache is watching if asked for all namespaces.
 func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
 	listOpts := client.ListOptions{}
@@ -248,9 +269,10 @@ func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList,
 		return err
 	}
 
-	limitSet := listOpts.Limit > 0
-
+	// Every cache returns the first Limit objects after the Continue option, so
+	// the first Limit objects of all of them are the page of the whole list.
 	var resourceVersion string
+	hasMore := false
 	for _, cache := range c.namespaceToCache {
 		listObj := list.DeepCopyObject().(client.ObjectList)
 		err = cache.List(ctx, listObj, &listOpts)
@@ -266,24 +288,21 @@ func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList,
 			return fmt.Errorf("object: %T must be a list type", list)
 		}
 		allItems = append(allItems, items...)
+		if accessor.GetContinue() != "" {
+			hasMore = true
+		}
 
 		// The last list call should have the most correct resource version.
 		resourceVersion = accessor.GetResourceVersion()
-		if limitSet {
-			// decrement Limit by the number of items
-			// fetched from the current namespace.
-			listOpts.Limit -= int64(len(items))
-
-			// if a Limit was set and the number of
-			// items read has reached this set limit,
-			// then stop reading.
-			if listOpts.Limit == 0 {
-				break
-			}
-		}
 	}
 	listAccessor.SetResourceVersion(resourceVersion)
 
+	allItems, continueToken, err := internal.Paginate(allItems, listOpts.Limit, listOpts.Continue, hasMore)
+	if err != nil {
+		return err
+	}
+	listAccessor.SetContinue(continueToken)
+
 	return apimeta.SetList(list, allItems)
 }
 
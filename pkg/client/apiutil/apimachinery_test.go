@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiutil_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var _ = Describe("RESTClientForGVK", func() {
+	var server *httptest.Server
+	var accept string
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept = r.Header.Get("Accept")
+			w.WriteHeader(http.StatusNotFound)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	acceptFor := func(gvk schema.GroupVersionKind, isUnstructured bool, config *rest.Config) string {
+		config.Host = server.URL
+		c, err := apiutil.RESTClientForGVK(gvk, isUnstructured, config, scheme.Codecs, server.Client())
+		Expect(err).NotTo(HaveOccurred())
+		_ = c.Get().Resource("things").Do(context.Background()).Error()
+		return accept
+	}
+
+	It("should use protobuf for built-in types", func() {
+		Expect(acceptFor(corev1.SchemeGroupVersion.WithKind("ConfigMap"), false, &rest.Config{})).To(HavePrefix(runtime.ContentTypeProtobuf))
+	})
+
+	It("should use JSON for unstructured objects and types that don't support protobuf", func() {
+		Expect(acceptFor(corev1.SchemeGroupVersion.WithKind("ConfigMap"), true, &rest.Config{})).To(HavePrefix(runtime.ContentTypeJSON))
+		Expect(acceptFor(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, false, &rest.Config{})).To(HavePrefix(runtime.ContentTypeJSON))
+	})
+
+	It("should use the content type of the config if it is set", func() {
+		Expect(acceptFor(corev1.SchemeGroupVersion.WithKind("ConfigMap"), false, &rest.Config{
+			ContentConfig: rest.ContentConfig{ContentType: runtime.ContentTypeJSON},
+		})).To(HavePrefix(runtime.ContentTypeJSON))
+	})
+})
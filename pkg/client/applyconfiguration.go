@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// decodeApplyResult decodes the server response of an apply request into
+// the given apply configuration, replacing its previous content.
+func decodeApplyResult(data []byte, obj ApplyConfiguration) error {
+	v := reflect.ValueOf(obj).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	return json.Unmarshal(data, obj)
+}
+
+// setApplyConfigurationNamespace sets the namespace of the given apply
+// configuration. The generated apply configurations don't implement
+// metav1.Object, so their WithNamespace builder is used instead.
+func setApplyConfigurationNamespace(obj ApplyConfiguration, namespace string) error {
+	if o, ok := obj.(metav1.Object); ok {
+		o.SetNamespace(namespace)
+		return nil
+	}
+
+	withNamespace := reflect.ValueOf(obj).MethodByName("WithNamespace")
+	if !withNamespace.IsValid() {
+		return fmt.Errorf("apply configuration %T does not have a WithNamespace method", obj)
+	}
+	if t := withNamespace.Type(); t.NumIn() != 1 || t.In(0).Kind() != reflect.String {
+		return fmt.Errorf("WithNamespace method of apply configuration %T must take a single string argument", obj)
+	}
+	withNamespace.Call([]reflect.Value{reflect.ValueOf(namespace)})
+	return nil
+}
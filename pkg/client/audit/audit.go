@@ -0,0 +1,425 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit provides a client that records every write it makes, to tell
+// which controller changed what.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/internal/applyconfiguration"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Operation is a write operation of a client.
+type Operation string
+
+const (
+	// OperationCreate is a Create call.
+	OperationCreate Operation = "Create"
+	// OperationUpdate is an Update call.
+	OperationUpdate Operation = "Update"
+	// OperationPatch is a Patch call.
+	OperationPatch Operation = "Patch"
+	// OperationApply is an Apply call.
+	OperationApply Operation = "Apply"
+	// OperationDelete is a Delete call.
+	OperationDelete Operation = "Delete"
+	// OperationDeleteAllOf is a DeleteAllOf call.
+	OperationDeleteAllOf Operation = "DeleteAllOf"
+)
+
+// Result is the outcome of a write.
+type Result string
+
+const (
+	// ResultSuccess is the Result of a successful write.
+	ResultSuccess Result = "Success"
+	// ResultFailure is the Result of a failed write.
+	ResultFailure Result = "Failure"
+)
+
+// Entry describes a single write of the audit client.
+type Entry struct {
+	// Time is the time the write finished.
+	Time time.Time `json:"time"`
+	// Controller is the name of the controller that made the write, if the
+	// write happened during a reconciliation.
+	Controller string `json:"controller,omitempty"`
+	// ReconcileID is the ID of the reconciliation that made the write, if any.
+	ReconcileID types.UID `json:"reconcileID,omitempty"`
+
+	// Operation is the write operation.
+	Operation Operation `json:"operation"`
+	// SubResource is the subresource written to, if any.
+	SubResource string `json:"subResource,omitempty"`
+	// APIVersion and Kind are the type of the written object.
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	// Namespace and Name identify the written object. For DeleteAllOf, Name
+	// is empty and Namespace is the namespace of the deleted objects, if any.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// LabelSelector and FieldSelector select the objects deleted by DeleteAllOf.
+	LabelSelector string `json:"labelSelector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
+	// Object is the object sent to the server by Create.
+	Object json.RawMessage `json:"object,omitempty"`
+	// Diff is a JSON merge patch from the object before an Update to the
+	// object sent to the server. It is only set if Options.DiffUpdates is
+	// true, and empty if the object couldn't be read before the Update.
+	Diff json.RawMessage `json:"diff,omitempty"`
+	// PatchType and Patch are the patch sent to the server by Patch and Apply.
+	PatchType types.PatchType `json:"patchType,omitempty"`
+	Patch     json.RawMessage `json:"patch,omitempty"`
+
+	// Result is the outcome of the write.
+	Result Result `json:"result"`
+	// Error is the error of a failed write.
+	Error string `json:"error,omitempty"`
+	// ResourceVersion is the resourceVersion of the object after a
+	// successful write.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// Options are the options of an audit client.
+type Options struct {
+	// DiffUpdates records the diff of every Update in the entry. To compute
+	// it, the object is read using the wrapped client before the Update, which
+	// costs an additional request if that client doesn't read from a cache.
+	DiffUpdates bool
+}
+
+// NewClient returns a client that records every Create, Update, Patch, Apply,
+// Delete and DeleteAllOf, including those of subresources, to the given sink.
+// All calls are made using the given client.
+//
+// Entries contain the objects and patches sent to the server, which might
+// include sensitive data like the contents of Secrets. The controller and
+// reconcileID of an entry are taken from the context, see WithController.
+func NewClient(c client.Client, sink Sink, opts Options) client.Client {
+	return &auditClient{client: c, sink: sink, diffUpdates: opts.DiffUpdates}
+}
+
+var _ client.Client = &auditClient{}
+
+type auditClient struct {
+	client      client.Client
+	sink        Sink
+	diffUpdates bool
+}
+
+// newEntry returns an entry for the given operation on obj, which may be nil.
+func (c *auditClient) newEntry(ctx context.Context, operation Operation, obj client.Object) Entry {
+	entry := Entry{Operation: operation}
+	if value, ok := ctx.Value(controllerKey{}).(controllerValue); ok {
+		entry.Controller = value.name
+		entry.ReconcileID = value.reconcileID
+	}
+	if obj != nil {
+		if gvk, err := c.client.GroupVersionKindFor(obj); err == nil {
+			entry.APIVersion, entry.Kind = gvk.ToAPIVersionAndKind()
+		}
+		entry.Namespace = obj.GetNamespace()
+		entry.Name = obj.GetName()
+	}
+	return entry
+}
+
+// record completes the entry with the outcome of the write and records it.
+// obj is the written object, if any.
+func (c *auditClient) record(ctx context.Context, entry Entry, obj client.Object, err error) {
+	entry.Time = time.Now()
+	if err != nil {
+		entry.Result = ResultFailure
+		entry.Error = err.Error()
+	} else {
+		entry.Result = ResultSuccess
+		if obj != nil {
+			entry.ResourceVersion = obj.GetResourceVersion()
+		}
+	}
+	if recordErr := c.sink.Record(ctx, entry); recordErr != nil {
+		logf.FromContext(ctx).Error(recordErr, "Failed to record mutation", "operation", entry.Operation, "kind", entry.Kind, "namespace", entry.Namespace, "name", entry.Name)
+	}
+}
+
+// diff returns a JSON merge patch from the current state of obj to obj, or
+// nil if diffs are disabled or the current state can't be read.
+func (c *auditClient) diff(ctx context.Context, obj client.Object) json.RawMessage {
+	if !c.diffUpdates {
+		return nil
+	}
+	gvk, err := c.client.GroupVersionKindFor(obj)
+	if err != nil {
+		return nil
+	}
+	current := newObject(obj, gvk)
+	if err := c.client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return nil
+	}
+	// Only diff the contents, not whether the type information is set.
+	current.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return nil
+	}
+	modifiedJSON, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	diff, err := jsonpatch.CreateMergePatch(currentJSON, modifiedJSON)
+	if err != nil {
+		return nil
+	}
+	return diff
+}
+
+// newObject returns an empty object of the same type as obj.
+func newObject(obj client.Object, gvk schema.GroupVersionKind) client.Object {
+	o := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(client.Object)
+	o.GetObjectKind().SetGroupVersionKind(gvk)
+	return o
+}
+
+// rawJSON marshals obj, returning nil if that fails.
+func rawJSON(obj interface{}) json.RawMessage {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// patchData returns the data of the given patch for obj, if it is valid JSON.
+func patchData(patch client.Patch, obj client.Object) json.RawMessage {
+	data, err := patch.Data(obj)
+	if err != nil || !json.Valid(data) {
+		return nil
+	}
+	return data
+}
+
+// applyEntry returns an entry for applying obj.
+func (c *auditClient) applyEntry(ctx context.Context, obj client.ApplyConfiguration) Entry {
+	entry := c.newEntry(ctx, OperationApply, nil)
+	entry.PatchType = types.ApplyPatchType
+
+	u, err := applyconfiguration.ToUnstructured(obj)
+	if err != nil {
+		return entry
+	}
+	entry.Patch = rawJSON(u.Object)
+	entry.APIVersion, entry.Kind = u.GetAPIVersion(), u.GetKind()
+	entry.Namespace, entry.Name = u.GetNamespace(), u.GetName()
+	return entry
+}
+
+// appliedObject returns obj, which clients update with the response of the
+// server, as an object to record its resourceVersion. It returns nil if obj
+// can't be converted.
+func appliedObject(obj client.ApplyConfiguration, err error) client.Object {
+	if err != nil {
+		return nil
+	}
+	u, err := applyconfiguration.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// Scheme returns the scheme this client is using.
+func (c *auditClient) Scheme() *runtime.Scheme {
+	return c.client.Scheme()
+}
+
+// RESTMapper returns the rest mapper this client is using.
+func (c *auditClient) RESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GroupVersionKindFor returns the GroupVersionKind for the given object.
+func (c *auditClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return c.client.GroupVersionKindFor(obj)
+}
+
+// IsObjectNamespaced returns true if the GroupVersionKind of the object is namespaced.
+func (c *auditClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return c.client.IsObjectNamespaced(obj)
+}
+
+// Create implements client.Client.
+func (c *auditClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	entry := c.newEntry(ctx, OperationCreate, obj)
+	entry.Object = rawJSON(obj)
+	err := c.client.Create(ctx, obj, opts...)
+	c.record(ctx, entry, obj, err)
+	return err
+}
+
+// Update implements client.Client.
+func (c *auditClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	entry := c.newEntry(ctx, OperationUpdate, obj)
+	entry.Diff = c.diff(ctx, obj)
+	err := c.client.Update(ctx, obj, opts...)
+	c.record(ctx, entry, obj, err)
+	return err
+}
+
+// Delete implements client.Client.
+func (c *auditClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	entry := c.newEntry(ctx, OperationDelete, obj)
+	err := c.client.Delete(ctx, obj, opts...)
+	c.record(ctx, entry, nil, err)
+	return err
+}
+
+// DeleteAllOf implements client.Client.
+func (c *auditClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	entry := c.newEntry(ctx, OperationDeleteAllOf, obj)
+	deleteAllOfOpts := (&client.DeleteAllOfOptions{}).ApplyOptions(opts)
+	entry.Namespace = deleteAllOfOpts.Namespace
+	entry.Name = ""
+	if deleteAllOfOpts.LabelSelector != nil {
+		entry.LabelSelector = deleteAllOfOpts.LabelSelector.String()
+	}
+	if deleteAllOfOpts.FieldSelector != nil {
+		entry.FieldSelector = deleteAllOfOpts.FieldSelector.String()
+	}
+	err := c.client.DeleteAllOf(ctx, obj, opts...)
+	c.record(ctx, entry, nil, err)
+	return err
+}
+
+// Patch implements client.Client.
+func (c *auditClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	entry := c.newEntry(ctx, OperationPatch, obj)
+	entry.PatchType = patch.Type()
+	entry.Patch = patchData(patch, obj)
+	err := c.client.Patch(ctx, obj, patch, opts...)
+	c.record(ctx, entry, obj, err)
+	return err
+}
+
+// Apply implements client.Client.
+func (c *auditClient) Apply(ctx context.Context, obj client.ApplyConfiguration, opts ...client.ApplyOption) error {
+	entry := c.applyEntry(ctx, obj)
+	err := c.client.Apply(ctx, obj, opts...)
+	c.record(ctx, entry, appliedObject(obj, err), err)
+	return err
+}
+
+// Get implements client.Client.
+func (c *auditClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Client.
+func (c *auditClient) List(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+	return c.client.List(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient.
+func (c *auditClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClientConstructor.
+func (c *auditClient) SubResource(subResource string) client.SubResourceClient {
+	return &auditSubResourceClient{
+		client:      c,
+		subResource: subResource,
+		wrapped:     c.client.SubResource(subResource),
+	}
+}
+
+var _ client.SubResourceClient = &auditSubResourceClient{}
+var _ client.SubResourceStreamer = &auditSubResourceClient{}
+
+// auditSubResourceClient is a SubResourceClient that records all writes.
+type auditSubResourceClient struct {
+	client      *auditClient
+	subResource string
+	wrapped     client.SubResourceClient
+}
+
+// newEntry returns an entry for the given operation on the subresource of obj.
+func (sc *auditSubResourceClient) newEntry(ctx context.Context, operation Operation, obj client.Object) Entry {
+	entry := sc.client.newEntry(ctx, operation, obj)
+	entry.SubResource = sc.subResource
+	return entry
+}
+
+// Get implements client.SubResourceReader.
+func (sc *auditSubResourceClient) Get(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceGetOption) error {
+	return sc.wrapped.Get(ctx, obj, subResource, opts...)
+}
+
+// Stream implements client.SubResourceStreamer.
+func (sc *auditSubResourceClient) Stream(ctx context.Context, obj client.Object, opts ...client.SubResourceStreamOption) (io.ReadCloser, error) {
+	return client.StreamSubResource(ctx, sc.wrapped, obj, opts...)
+}
+
+// Create implements client.SubResourceWriter.
+func (sc *auditSubResourceClient) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	entry := sc.newEntry(ctx, OperationCreate, obj)
+	entry.Object = rawJSON(subResource)
+	err := sc.wrapped.Create(ctx, obj, subResource, opts...)
+	sc.client.record(ctx, entry, obj, err)
+	return err
+}
+
+// Update implements client.SubResourceWriter.
+func (sc *auditSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	entry := sc.newEntry(ctx, OperationUpdate, obj)
+	entry.Diff = sc.client.diff(ctx, obj)
+	err := sc.wrapped.Update(ctx, obj, opts...)
+	sc.client.record(ctx, entry, obj, err)
+	return err
+}
+
+// Patch implements client.SubResourceWriter.
+func (sc *auditSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	entry := sc.newEntry(ctx, OperationPatch, obj)
+	entry.PatchType = patch.Type()
+	entry.Patch = patchData(patch, obj)
+	err := sc.wrapped.Patch(ctx, obj, patch, opts...)
+	sc.client.record(ctx, entry, obj, err)
+	return err
+}
+
+// Apply implements client.SubResourceWriter.
+func (sc *auditSubResourceClient) Apply(ctx context.Context, obj client.ApplyConfiguration, opts ...client.SubResourceApplyOption) error {
+	entry := sc.client.applyEntry(ctx, obj)
+	entry.SubResource = sc.subResource
+	err := sc.wrapped.Apply(ctx, obj, opts...)
+	sc.client.record(ctx, entry, appliedObject(obj, err), err)
+	return err
+}
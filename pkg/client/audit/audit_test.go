@@ -0,0 +1,295 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	appsv1applyconfigurations "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1applyconfigurations "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/audit"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/internal/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("NewClient", func() {
+	var sink *audit.MemorySink
+	var cm *corev1.ConfigMap
+	var dep *appsv1.Deployment
+	var c client.Client
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		sink = &audit.MemorySink{}
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+			Data:       map[string]string{"a": "1"},
+		}
+		dep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "default"},
+		}
+		c = audit.NewClient(fake.NewClientBuilder().WithObjects(dep).WithStatusSubresource(dep).Build(), sink, audit.Options{DiffUpdates: true})
+	})
+
+	It("should record creates with the object", func() {
+		Expect(c.Create(ctx, cm)).To(Succeed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Operation).To(Equal(audit.OperationCreate))
+		Expect(entries[0].APIVersion).To(Equal("v1"))
+		Expect(entries[0].Kind).To(Equal("ConfigMap"))
+		Expect(entries[0].Namespace).To(Equal("default"))
+		Expect(entries[0].Name).To(Equal("cm"))
+		Expect(entries[0].Result).To(Equal(audit.ResultSuccess))
+		Expect(entries[0].ResourceVersion).To(Equal(cm.ResourceVersion))
+		Expect(entries[0].Time).NotTo(BeZero())
+
+		var object map[string]interface{}
+		Expect(json.Unmarshal(entries[0].Object, &object)).To(Succeed())
+		Expect(object).To(HaveKeyWithValue("data", map[string]interface{}{"a": "1"}))
+	})
+
+	It("should record updates with a diff", func() {
+		Expect(c.Create(ctx, cm)).To(Succeed())
+		sink.Reset()
+
+		cm.Data = map[string]string{"b": "2"}
+		Expect(c.Update(ctx, cm)).To(Succeed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Operation).To(Equal(audit.OperationUpdate))
+		Expect(entries[0].Diff).To(MatchJSON(`{"data":{"a":null,"b":"2"}}`))
+		Expect(entries[0].ResourceVersion).To(Equal(cm.ResourceVersion))
+	})
+
+	It("should not read the object before updates unless diffs are enabled", func() {
+		gets := 0
+		c = audit.NewClient(interceptor.NewClient(fake.NewClientBuilder().WithObjects(cm).Build(), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				gets++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}), sink, audit.Options{})
+
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		cm.Data = map[string]string{"b": "2"}
+		Expect(c.Update(ctx, cm)).To(Succeed())
+		Expect(gets).To(Equal(1))
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Operation).To(Equal(audit.OperationUpdate))
+		Expect(entries[0].Diff).To(BeNil())
+	})
+
+	It("should record patches with the patch", func() {
+		Expect(c.Create(ctx, cm)).To(Succeed())
+		sink.Reset()
+
+		patch := client.MergeFrom(cm.DeepCopy())
+		cm.Data["a"] = "2"
+		Expect(c.Patch(ctx, cm, patch)).To(Succeed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Operation).To(Equal(audit.OperationPatch))
+		Expect(entries[0].PatchType).To(Equal(types.MergePatchType))
+		Expect(entries[0].Patch).To(MatchJSON(`{"data":{"a":"2"}}`))
+	})
+
+	It("should record applies with the apply configuration", func() {
+		obj := corev1applyconfigurations.ConfigMap("cm", "default").WithData(map[string]string{"a": "1"})
+		Expect(c.Apply(ctx, obj, client.FieldOwner("test"))).To(Succeed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Operation).To(Equal(audit.OperationApply))
+		Expect(entries[0].Kind).To(Equal("ConfigMap"))
+		Expect(entries[0].Name).To(Equal("cm"))
+		Expect(entries[0].PatchType).To(Equal(types.ApplyPatchType))
+		Expect(entries[0].Patch).To(MatchJSON(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"default"},"data":{"a":"1"}}`))
+		Expect(obj.ResourceVersion).NotTo(BeNil())
+		Expect(entries[0].ResourceVersion).To(Equal(*obj.ResourceVersion))
+	})
+
+	It("should record deletes", func() {
+		Expect(c.Create(ctx, cm)).To(Succeed())
+		sink.Reset()
+
+		Expect(c.Delete(ctx, cm)).To(Succeed())
+		Expect(c.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default"), client.MatchingLabels{"app": "foo"})).To(Succeed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Operation).To(Equal(audit.OperationDelete))
+		Expect(entries[0].Name).To(Equal("cm"))
+		Expect(entries[1].Operation).To(Equal(audit.OperationDeleteAllOf))
+		Expect(entries[1].Namespace).To(Equal("default"))
+		Expect(entries[1].Name).To(BeEmpty())
+		Expect(entries[1].LabelSelector).To(Equal("app=foo"))
+	})
+
+	It("should record subresource writes", func() {
+		dep.Status.Replicas = 2
+		Expect(c.Status().Update(ctx, dep)).To(Succeed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Operation).To(Equal(audit.OperationUpdate))
+		Expect(entries[0].SubResource).To(Equal("status"))
+		Expect(entries[0].Kind).To(Equal("Deployment"))
+		Expect(entries[0].Diff).To(MatchJSON(`{"status":{"replicas":2}}`))
+	})
+
+	It("should record subresource applies with the resourceVersion", func() {
+		obj := appsv1applyconfigurations.Deployment(dep.Name, dep.Namespace).
+			WithStatus(appsv1applyconfigurations.DeploymentStatus().WithReplicas(2))
+		Expect(c.Status().Apply(ctx, obj, client.FieldOwner("test"))).To(Succeed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Operation).To(Equal(audit.OperationApply))
+		Expect(entries[0].SubResource).To(Equal("status"))
+		Expect(entries[0].Kind).To(Equal("Deployment"))
+		Expect(obj.ResourceVersion).NotTo(BeNil())
+		Expect(entries[0].ResourceVersion).To(Equal(*obj.ResourceVersion))
+	})
+
+	It("should record failed writes", func() {
+		err := c.Update(ctx, cm)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Result).To(Equal(audit.ResultFailure))
+		Expect(entries[0].Error).To(Equal(err.Error()))
+		Expect(entries[0].Diff).To(BeNil())
+	})
+
+	It("should not fail writes if recording fails", func() {
+		c = audit.NewClient(fake.NewClientBuilder().Build(), audit.SinkFunc(func(context.Context, audit.Entry) error {
+			return errors.New("sink is full")
+		}), audit.Options{})
+		Expect(c.Create(ctx, cm)).To(Succeed())
+	})
+
+	It("should record the controller and reconcileID of writes during a reconciliation", func() {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		done := make(chan struct{})
+		queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		ctrl := &controller.Controller{
+			Name:                    "test-controller",
+			MaxConcurrentReconciles: 1,
+			Do: reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				defer close(done)
+				return reconcile.Result{}, c.Create(ctx, cm)
+			}),
+			MakeQueue:      func() workqueue.RateLimitingInterface { return queue },
+			LogConstructor: func(*reconcile.Request) logr.Logger { return logr.Discard() },
+		}
+		go func() {
+			defer GinkgoRecover()
+			Expect(ctrl.Start(ctx)).To(Succeed())
+		}()
+		queue.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cm)})
+		Eventually(done).Should(BeClosed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Controller).To(Equal("test-controller"))
+		Expect(entries[0].ReconcileID).NotTo(BeEmpty())
+	})
+
+	It("should record the controller and reconcileID of the context", func() {
+		ctx := audit.WithController(ctx, "test-controller", "id")
+		Expect(c.Create(ctx, cm)).To(Succeed())
+
+		entries := sink.Entries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Controller).To(Equal("test-controller"))
+		Expect(entries[0].ReconcileID).To(BeEquivalentTo("id"))
+	})
+})
+
+var _ = Describe("Sinks", func() {
+	entry := audit.Entry{Operation: audit.OperationDelete, Kind: "ConfigMap", Namespace: "default", Name: "cm", Result: audit.ResultSuccess}
+
+	It("should write newline delimited JSON", func() {
+		buf := &bytes.Buffer{}
+		sink := audit.NewWriterSink(buf)
+		Expect(sink.Record(context.Background(), entry)).To(Succeed())
+		Expect(sink.Record(context.Background(), entry)).To(Succeed())
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(2))
+		var decoded audit.Entry
+		Expect(json.Unmarshal(lines[1], &decoded)).To(Succeed())
+		Expect(decoded.Operation).To(Equal(audit.OperationDelete))
+		Expect(decoded.Name).To(Equal("cm"))
+	})
+
+	It("should log entries", func() {
+		var logged []string
+		log := funcr.New(func(prefix, args string) { logged = append(logged, args) }, funcr.Options{})
+		Expect(audit.NewLogSink(log).Record(context.Background(), entry)).To(Succeed())
+
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0]).To(ContainSubstring(`"msg"="Mutation"`))
+		Expect(logged[0]).To(ContainSubstring(`"name"="cm"`))
+		Expect(logged[0]).To(ContainSubstring(`"operation"="Delete"`))
+	})
+
+	It("should log the fields of entries in a fixed order", func() {
+		entry := entry
+		entry.Controller = "test-controller"
+		entry.Diff = []byte(`{"data":{"a":"2"}}`)
+
+		var logged []string
+		log := funcr.New(func(prefix, args string) { logged = append(logged, args) }, funcr.Options{})
+		sink := audit.NewLogSink(log)
+		for i := 0; i < 10; i++ {
+			Expect(sink.Record(context.Background(), entry)).To(Succeed())
+		}
+
+		Expect(logged).To(HaveLen(10))
+		Expect(logged[0]).To(HaveSuffix(`"controller"="test-controller" "operation"="Delete" "kind"="ConfigMap" "namespace"="default" "name"="cm" "diff"={"data":{"a":"2"}} "result"="Success"`))
+		for _, line := range logged {
+			Expect(line).To(Equal(logged[0]))
+		}
+	})
+})
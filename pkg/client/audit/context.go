@@ -0,0 +1,40 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// controllerKey is a context.Context Value key. Its associated value should
+// be a controllerValue.
+type controllerKey struct{}
+
+// controllerValue identifies the reconciliation that makes a write.
+type controllerValue struct {
+	name        string
+	reconcileID types.UID
+}
+
+// WithController returns a copy of ctx that attributes the writes made with it
+// to the given controller and reconciliation. Controllers do this for the
+// context of every reconciliation.
+func WithController(ctx context.Context, name string, reconcileID types.UID) context.Context {
+	return context.WithValue(ctx, controllerKey{}, controllerValue{name: name, reconcileID: reconcileID})
+}
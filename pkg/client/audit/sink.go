@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/go-logr/logr"
+)
+
+// Sink records the entries of an audit client.
+type Sink interface {
+	// Record records the given entry. It is called synchronously after every
+	// write, so it should be fast. Errors are logged, but don't fail the write.
+	Record(ctx context.Context, entry Entry) error
+}
+
+// SinkFunc implements Sink with a function.
+type SinkFunc func(ctx context.Context, entry Entry) error
+
+// Record implements Sink.
+func (f SinkFunc) Record(ctx context.Context, entry Entry) error {
+	return f(ctx, entry)
+}
+
+// NewWriterSink returns a Sink that writes entries as newline delimited JSON
+// to w, e.g. a file. Writes to w are serialized.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{encoder: json.NewEncoder(w)}
+}
+
+type writerSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// Record implements Sink.
+func (s *writerSink) Record(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(entry)
+}
+
+// NewLogSink returns a Sink that logs entries to the given logger, with the
+// fields of the entry that are set as key/value pairs. The keys are the JSON
+// field names of the entry and always logged in the same order.
+func NewLogSink(log logr.Logger) Sink {
+	return SinkFunc(func(_ context.Context, entry Entry) error {
+		var keysAndValues []interface{}
+		add := func(key string, value string) {
+			if value != "" {
+				keysAndValues = append(keysAndValues, key, value)
+			}
+		}
+		addJSON := func(key string, value json.RawMessage) error {
+			if len(value) == 0 {
+				return nil
+			}
+			var decoded interface{}
+			if err := json.Unmarshal(value, &decoded); err != nil {
+				return err
+			}
+			keysAndValues = append(keysAndValues, key, decoded)
+			return nil
+		}
+
+		add("controller", entry.Controller)
+		add("reconcileID", string(entry.ReconcileID))
+		add("operation", string(entry.Operation))
+		add("subResource", entry.SubResource)
+		add("apiVersion", entry.APIVersion)
+		add("kind", entry.Kind)
+		add("namespace", entry.Namespace)
+		add("name", entry.Name)
+		add("labelSelector", entry.LabelSelector)
+		add("fieldSelector", entry.FieldSelector)
+		if err := addJSON("object", entry.Object); err != nil {
+			return err
+		}
+		if err := addJSON("diff", entry.Diff); err != nil {
+			return err
+		}
+		add("patchType", string(entry.PatchType))
+		if err := addJSON("patch", entry.Patch); err != nil {
+			return err
+		}
+		add("result", string(entry.Result))
+		add("error", entry.Error)
+		add("resourceVersion", entry.ResourceVersion)
+		log.Info("Mutation", keysAndValues...)
+		return nil
+	})
+}
+
+// MemorySink is a Sink that keeps all entries in memory, e.g. to inspect them
+// in tests. The zero value is ready to use.
+type MemorySink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Record implements Sink.
+func (s *MemorySink) Record(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// Entries returns a copy of the recorded entries, oldest first.
+func (s *MemorySink) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.entries...)
+}
+
+// Reset removes all recorded entries.
+func (s *MemorySink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = nil
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DefaultBatchParallelism is the number of operations a Batch runs
+// concurrently if no BatchParallelism option is given.
+const DefaultBatchParallelism = 10
+
+// BatchOperation is a single write of a Batch. Use BatchCreate, BatchUpdate,
+// BatchPatch, BatchDelete or BatchApply to construct one.
+type BatchOperation struct {
+	object Object
+	run    func(ctx context.Context, w Writer) error
+}
+
+// BatchCreate returns an operation that creates obj.
+func BatchCreate(obj Object, opts ...CreateOption) BatchOperation {
+	return BatchOperation{object: obj, run: func(ctx context.Context, w Writer) error {
+		return w.Create(ctx, obj, opts...)
+	}}
+}
+
+// BatchUpdate returns an operation that updates obj.
+func BatchUpdate(obj Object, opts ...UpdateOption) BatchOperation {
+	return BatchOperation{object: obj, run: func(ctx context.Context, w Writer) error {
+		return w.Update(ctx, obj, opts...)
+	}}
+}
+
+// BatchPatch returns an operation that patches obj with the given patch.
+func BatchPatch(obj Object, patch Patch, opts ...PatchOption) BatchOperation {
+	return BatchOperation{object: obj, run: func(ctx context.Context, w Writer) error {
+		return w.Patch(ctx, obj, patch, opts...)
+	}}
+}
+
+// BatchDelete returns an operation that deletes obj.
+func BatchDelete(obj Object, opts ...DeleteOption) BatchOperation {
+	return BatchOperation{object: obj, run: func(ctx context.Context, w Writer) error {
+		return w.Delete(ctx, obj, opts...)
+	}}
+}
+
+// BatchApply returns an operation that applies obj.
+func BatchApply(obj ApplyConfiguration, opts ...ApplyOption) BatchOperation {
+	return BatchOperation{run: func(ctx context.Context, w Writer) error {
+		return w.Apply(ctx, obj, opts...)
+	}}
+}
+
+// BatchResult is the result of a single operation of a Batch.
+type BatchResult struct {
+	// Object is the object of the operation, updated with the response of
+	// the API server. It is nil for Apply operations.
+	Object Object
+
+	// Err is the error of the operation, or nil if it succeeded. Operations
+	// that weren't started because the context was cancelled have the error
+	// of the context.
+	Err error
+}
+
+// BatchOption is some configuration that modifies a Batch.
+type BatchOption interface {
+	// ApplyToBatch applies this configuration to the given batch options.
+	ApplyToBatch(*BatchOptions)
+}
+
+// BatchOptions contains options for Batch.
+type BatchOptions struct {
+	// Parallelism is the maximum number of operations that run concurrently.
+	// Defaults to DefaultBatchParallelism.
+	Parallelism int
+}
+
+// ApplyOptions applies the given batch options on these options,
+// and then returns itself (for convenient chaining).
+func (o *BatchOptions) ApplyOptions(opts []BatchOption) *BatchOptions {
+	for _, opt := range opts {
+		opt.ApplyToBatch(o)
+	}
+	return o
+}
+
+// ApplyToBatch implements BatchOption.
+func (o *BatchOptions) ApplyToBatch(bo *BatchOptions) {
+	if o.Parallelism > 0 {
+		bo.Parallelism = o.Parallelism
+	}
+}
+
+// BatchParallelism sets the maximum number of operations of a Batch that run
+// concurrently.
+type BatchParallelism int
+
+// ApplyToBatch implements BatchOption.
+func (p BatchParallelism) ApplyToBatch(opts *BatchOptions) {
+	opts.Parallelism = int(p)
+}
+
+// Batch runs the given operations against w, running up to
+// BatchOptions.Parallelism of them concurrently. The order in which the
+// operations run is not defined, so operations must not depend on each other.
+//
+// Once ctx is cancelled, no further operations are started. Batch waits for
+// all started operations to finish before it returns.
+//
+// The returned results have the same order as ops. If any operation failed,
+// a *BatchError with the errors of all failed operations is returned as well.
+func Batch(ctx context.Context, w Writer, ops []BatchOperation, opts ...BatchOption) ([]BatchResult, error) {
+	options := (&BatchOptions{Parallelism: DefaultBatchParallelism}).ApplyOptions(opts)
+	if options.Parallelism <= 0 {
+		options.Parallelism = DefaultBatchParallelism
+	}
+
+	results := make([]BatchResult, len(ops))
+	sem := make(chan struct{}, options.Parallelism)
+	wg := sync.WaitGroup{}
+	for i, op := range ops {
+		results[i].Object = op.object
+
+		select {
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+		// select chooses randomly if both cases are ready, so check ctx again.
+		if err := ctx.Err(); err != nil {
+			<-sem
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, op BatchOperation) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Err = op.run(ctx, w)
+		}(i, op)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	if len(errs) > 0 {
+		return results, &BatchError{Errors: errs}
+	}
+	return results, nil
+}
+
+// BatchError is the error returned by Batch if any of its operations failed.
+//
+// errors.Is and errors.As consider all errors of the failed operations. In
+// addition, errors.Is reports whether any of them is an API error with the
+// same reason as the target, e.g.
+//
+//	errors.Is(err, apierrors.NewNotFound(schema.GroupResource{}, ""))
+//
+// is true if any operation failed because its object was not found. Note that
+// apierrors.IsNotFound and similar functions only check the first error.
+type BatchError struct {
+	// Errors are the errors of the failed operations, in the order of the
+	// operations.
+	Errors []error
+}
+
+// Error implements error.
+func (e *BatchError) Error() string {
+	return kerrors.NewAggregate(e.Errors).Error()
+}
+
+// Unwrap returns the errors of the failed operations.
+func (e *BatchError) Unwrap() []error {
+	return e.Errors
+}
+
+// Is reports whether any of the errors of the failed operations is an API
+// error with the same reason as target.
+func (e *BatchError) Is(target error) bool {
+	reason := apierrors.ReasonForError(target)
+	if reason == metav1.StatusReasonUnknown {
+		return false
+	}
+	for _, err := range e.Errors {
+		if apierrors.ReasonForError(err) == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1applyconfigurations "k8s.io/client-go/applyconfigurations/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Batch", func() {
+	ctx := context.Background()
+
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	It("should run all operations and return their results in order", func() {
+		existing := configMap("existing")
+		c := fake.NewClientBuilder().WithObjects(existing.DeepCopy(), configMap("deleted")).Build()
+
+		updated := existing.DeepCopy()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(updated), updated)).To(Succeed())
+		updated.Data = map[string]string{"a": "1"}
+
+		patched := updated.DeepCopy()
+		patch := client.MergeFrom(patched.DeepCopy())
+		patched.Labels = map[string]string{"b": "2"}
+
+		results, err := client.Batch(ctx, c, []client.BatchOperation{
+			client.BatchCreate(configMap("created")),
+			client.BatchUpdate(updated),
+			client.BatchDelete(configMap("deleted")),
+			client.BatchApply(corev1applyconfigurations.ConfigMap("applied", "default"), client.FieldOwner("test")),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(4))
+		Expect(results[0].Object.GetName()).To(Equal("created"))
+		Expect(results[0].Object.GetResourceVersion()).NotTo(BeEmpty())
+		Expect(results[1].Object).To(BeIdenticalTo(updated))
+		Expect(results[3].Object).To(BeNil())
+		for _, result := range results {
+			Expect(result.Err).NotTo(HaveOccurred())
+		}
+
+		_, err = client.Batch(ctx, c, []client.BatchOperation{client.BatchPatch(patched, patch)})
+		Expect(err).NotTo(HaveOccurred())
+
+		actual := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "existing"}, actual)).To(Succeed())
+		Expect(actual.Data).To(Equal(map[string]string{"a": "1"}))
+		Expect(actual.Labels).To(Equal(map[string]string{"b": "2"}))
+		Expect(apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "deleted"}, actual))).To(BeTrue())
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "applied"}, actual)).To(Succeed())
+	})
+
+	It("should return an error that matches the reasons of all failed operations", func() {
+		c := fake.NewClientBuilder().WithObjects(configMap("existing")).Build()
+
+		results, err := client.Batch(ctx, c, []client.BatchOperation{
+			client.BatchCreate(configMap("existing")),
+			client.BatchCreate(configMap("new")),
+			client.BatchDelete(configMap("missing")),
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(apierrors.IsAlreadyExists(results[0].Err)).To(BeTrue())
+		Expect(results[1].Err).NotTo(HaveOccurred())
+		Expect(apierrors.IsNotFound(results[2].Err)).To(BeTrue())
+
+		var batchErr *client.BatchError
+		Expect(errors.As(err, &batchErr)).To(BeTrue())
+		Expect(batchErr.Errors).To(HaveLen(2))
+		Expect(errors.Is(err, apierrors.NewAlreadyExists(schema.GroupResource{}, ""))).To(BeTrue())
+		Expect(errors.Is(err, apierrors.NewNotFound(schema.GroupResource{}, ""))).To(BeTrue())
+		Expect(errors.Is(err, apierrors.NewConflict(schema.GroupResource{}, "", nil))).To(BeFalse())
+		Expect(errors.Is(err, results[2].Err)).To(BeTrue())
+	})
+
+	It("should not run more operations concurrently than the parallelism", func() {
+		var running, maxRunning int32
+		c := interceptor.NewClient(fake.NewClientBuilder().Build(), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					current := atomic.LoadInt32(&maxRunning)
+					if n <= current || atomic.CompareAndSwapInt32(&maxRunning, current, n) {
+						break
+					}
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		var ops []client.BatchOperation
+		for i := 0; i < 20; i++ {
+			ops = append(ops, client.BatchCreate(configMap(fmt.Sprintf("cm-%d", i))))
+		}
+		_, err := client.Batch(ctx, c, ops, client.BatchParallelism(3))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(atomic.LoadInt32(&maxRunning)).To(BeNumerically("<=", 3))
+
+		list := &corev1.ConfigMapList{}
+		Expect(c.List(ctx, list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(20))
+	})
+
+	It("should not start operations once the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(ctx)
+		var started int32
+		c := interceptor.NewClient(fake.NewClientBuilder().Build(), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				atomic.AddInt32(&started, 1)
+				cancel()
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		results, err := client.Batch(ctx, c, []client.BatchOperation{
+			client.BatchCreate(configMap("first")),
+			client.BatchCreate(configMap("second")),
+			client.BatchCreate(configMap("third")),
+		}, client.BatchParallelism(1))
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		Expect(atomic.LoadInt32(&started)).To(BeEquivalentTo(1))
+		Expect(results[0].Err).NotTo(HaveOccurred())
+		Expect(results[1].Err).To(MatchError(context.Canceled))
+		Expect(results[2].Err).To(MatchError(context.Canceled))
+	})
+})
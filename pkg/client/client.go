@@ -20,8 +20,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -56,6 +58,23 @@ type Options struct {
 
 	// DryRun instructs the client to only perform dry run requests.
 	DryRun *bool
+
+	// SkipNoOpWrites makes the client skip writes that wouldn't change the
+	// object, saving the request to the API server:
+	//  - Patches created by MergeFrom or StrategicMergeFrom whose data is
+	//    empty. obj is updated with the current state of the object instead.
+	//  - Updates of objects that are read from the cache, if obj semantically
+	//    equals the cached object, including its resourceVersion.
+	// The same applies to patches and updates of the status subresource.
+	// Skipped writes are counted in the
+	// controller_runtime_client_skipped_noop_writes_total metric.
+	SkipNoOpWrites bool
+
+	// NamespacePolicy, if set, makes the client reject all requests outside
+	// of the policy with a Forbidden error, see NewNamespacePolicyClient.
+	// Setting it in the client options of a manager surfaces requests that
+	// RBAC would forbid in production already in tests.
+	NamespacePolicy *NamespacePolicy
 }
 
 // WarningHandlerOptions are options for configuring a
@@ -84,6 +103,24 @@ type CacheOptions struct {
 	// read unstructured objects or lists from the cache.
 	// If false, unstructured objects will always result in a live lookup.
 	Unstructured bool
+	// ReadYourWrites makes reads from the cache observe the writes of this client.
+	// The client remembers the resourceVersion the server returns for each create,
+	// update, patch, apply and delete, and a cached Get or List that includes a
+	// written object waits until the cache has observed that resourceVersion.
+	// If the cache doesn't catch up within ReadYourWritesTimeout, the read is
+	// performed against the API server instead, as it is if the cache fails to
+	// read a written object.
+	// Lists only wait for writes of objects that match their selectors, and
+	// no read waits for writes of objects a ScopedReader cache doesn't hold. Reads
+	// that include writes of objects the cache might or might not hold, e.g. as
+	// it is restricted by a field selector, are performed against the API server.
+	// Writes made by other clients, including other clients sharing the same
+	// cache, are not taken into account.
+	ReadYourWrites bool
+	// ReadYourWritesTimeout is the maximum time a cached read waits for the cache
+	// to observe the writes of this client if ReadYourWrites is enabled.
+	// Defaults to 5 seconds.
+	ReadYourWritesTimeout time.Duration
 }
 
 // NewClientFunc allows a user to define how to create a client.
@@ -99,11 +136,20 @@ type NewClientFunc func(config *rest.Config, options Options) (Client, error)
 // corresponding group, version, and kind for the given type.  In the
 // case of unstructured types, the group, version, and kind will be extracted
 // from the corresponding fields on the object.
+//
+// If ContentType in the given config is not set, the protobuf wire format
+// ("application/vnd.kubernetes.protobuf") is used for built-in types and types
+// added with apiutil.AddToProtobufScheme, and JSON for all other types,
+// including CRDs and unstructured objects. Informers of the cache use the same
+// wire format. Set ContentType to "application/json" to always use JSON.
 func New(config *rest.Config, options Options) (c Client, err error) {
 	c, err = newClient(config, options)
 	if err == nil && options.DryRun != nil && *options.DryRun {
 		c = NewDryRunClient(c)
 	}
+	if err == nil && options.NamespacePolicy != nil {
+		c = NewNamespacePolicyClient(c, *options.NamespacePolicy)
+	}
 	return c, err
 }
 
@@ -186,6 +232,8 @@ func newClient(config *rest.Config, options Options) (*client, error) {
 		},
 		scheme: options.Scheme,
 		mapper: options.Mapper,
+
+		skipNoOpWrites: options.SkipNoOpWrites,
 	}
 	if options.Cache == nil || options.Cache.Reader == nil {
 		return c, nil
@@ -197,6 +245,9 @@ func newClient(config *rest.Config, options Options) (*client, error) {
 
 	// Load uncached GVKs.
 	c.cacheUnstructured = options.Cache.Unstructured
+	if options.Cache.ReadYourWrites {
+		c.writes = newWriteTracker(options.Cache.ReadYourWritesTimeout)
+	}
 	c.uncachedGVKs = map[schema.GroupVersionKind]struct{}{}
 	for _, obj := range options.Cache.DisableFor {
 		gvk, err := c.GroupVersionKindFor(obj)
@@ -222,6 +273,12 @@ type client struct {
 	cache             Reader
 	uncachedGVKs      map[schema.GroupVersionKind]struct{}
 	cacheUnstructured bool
+
+	// writes tracks the writes of the client if read-your-writes is enabled.
+	writes *writeTracker
+
+	// skipNoOpWrites makes the client skip writes that wouldn't change the object.
+	skipNoOpWrites bool
 }
 
 func (c *client) shouldBypassCache(obj runtime.Object) (bool, error) {
@@ -278,7 +335,8 @@ func (c *client) RESTMapper() meta.RESTMapper {
 }
 
 // Create implements client.Client.
-func (c *client) Create(ctx context.Context, obj Object, opts ...CreateOption) error {
+func (c *client) Create(ctx context.Context, obj Object, opts ...CreateOption) (err error) {
+	defer func() { c.recordWrite(obj, err) }()
 	switch obj.(type) {
 	case runtime.Unstructured:
 		return c.unstructuredClient.Create(ctx, obj, opts...)
@@ -290,7 +348,11 @@ func (c *client) Create(ctx context.Context, obj Object, opts ...CreateOption) e
 }
 
 // Update implements client.Client.
-func (c *client) Update(ctx context.Context, obj Object, opts ...UpdateOption) error {
+func (c *client) Update(ctx context.Context, obj Object, opts ...UpdateOption) (err error) {
+	if c.isNoOpUpdate(ctx, obj, "Update") {
+		return nil
+	}
+	defer func() { c.recordWrite(obj, err) }()
 	defer c.resetGroupVersionKind(obj, obj.GetObjectKind().GroupVersionKind())
 	switch obj.(type) {
 	case runtime.Unstructured:
@@ -303,7 +365,8 @@ func (c *client) Update(ctx context.Context, obj Object, opts ...UpdateOption) e
 }
 
 // Delete implements client.Client.
-func (c *client) Delete(ctx context.Context, obj Object, opts ...DeleteOption) error {
+func (c *client) Delete(ctx context.Context, obj Object, opts ...DeleteOption) (err error) {
+	defer func() { c.recordDelete(obj, err) }()
 	switch obj.(type) {
 	case runtime.Unstructured:
 		return c.unstructuredClient.Delete(ctx, obj, opts...)
@@ -327,7 +390,11 @@ func (c *client) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllO
 }
 
 // Patch implements client.Client.
-func (c *client) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error {
+func (c *client) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) (err error) {
+	if c.isNoOpPatch(obj, patch, "Patch") {
+		return c.Get(ctx, ObjectKeyFromObject(obj), obj)
+	}
+	defer func() { c.recordWrite(obj, err) }()
 	defer c.resetGroupVersionKind(obj, obj.GetObjectKind().GroupVersionKind())
 	switch obj.(type) {
 	case runtime.Unstructured:
@@ -339,13 +406,31 @@ func (c *client) Patch(ctx context.Context, obj Object, patch Patch, opts ...Pat
 	}
 }
 
+// Apply implements client.Client.
+func (c *client) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) (err error) {
+	defer func() { c.recordApply(obj, err) }()
+	switch o := obj.(type) {
+	case runtime.Unstructured:
+		return c.unstructuredClient.Apply(ctx, obj, opts...)
+	case *metav1.PartialObjectMetadata:
+		defer c.resetGroupVersionKind(o, o.GetObjectKind().GroupVersionKind())
+		return c.metadataClient.Apply(ctx, obj, opts...)
+	default:
+		return c.typedClient.Apply(ctx, obj, opts...)
+	}
+}
+
 // Get implements client.Client.
 func (c *client) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
 	if isUncached, err := c.shouldBypassCache(obj); err != nil {
 		return err
 	} else if !isUncached {
-		// Attempt to get from the cache.
-		return c.cache.Get(ctx, key, obj, opts...)
+		if cacheUpToDate, err := c.waitForCachedObject(ctx, key, obj); err != nil {
+			return err
+		} else if cacheUpToDate {
+			// Attempt to get from the cache.
+			return c.cache.Get(ctx, key, obj, opts...)
+		}
 	}
 
 	// Perform a live lookup.
@@ -366,8 +451,12 @@ func (c *client) List(ctx context.Context, obj ObjectList, opts ...ListOption) e
 	if isUncached, err := c.shouldBypassCache(obj); err != nil {
 		return err
 	} else if !isUncached {
-		// Attempt to get from the cache.
-		return c.cache.List(ctx, obj, opts...)
+		if cacheUpToDate, err := c.waitForCachedList(ctx, obj, opts...); err != nil {
+			return err
+		} else if cacheUpToDate {
+			// Attempt to get from the cache.
+			return c.cache.List(ctx, obj, opts...)
+		}
 	}
 
 	// Perform a live lookup.
@@ -420,6 +509,7 @@ type subResourceClient struct {
 
 // ensure subResourceClient implements client.SubResourceClient.
 var _ SubResourceClient = &subResourceClient{}
+var _ SubResourceStreamer = &subResourceClient{}
 
 // SubResourceGetOptions holds all the possible configuration
 // for a subresource Get request.
@@ -451,6 +541,30 @@ func (getOpt *SubResourceGetOptions) AsGetOptions() *metav1.GetOptions {
 	return getOpt.Raw
 }
 
+// SubResourceStreamOptions holds all the possible configuration
+// for a subresource Stream request.
+type SubResourceStreamOptions struct {
+	// Raw are the options of the subresource, e.g. *corev1.PodLogOptions
+	// for the log subresource of pods. They are encoded as query parameters.
+	Raw runtime.Object
+}
+
+// ApplyToSubResourceStream updates the configuration to the given stream options.
+func (streamOpt *SubResourceStreamOptions) ApplyToSubResourceStream(o *SubResourceStreamOptions) {
+	if streamOpt.Raw != nil {
+		o.Raw = streamOpt.Raw
+	}
+}
+
+// ApplyOptions applies the given options.
+func (streamOpt *SubResourceStreamOptions) ApplyOptions(opts []SubResourceStreamOption) *SubResourceStreamOptions {
+	for _, o := range opts {
+		o.ApplyToSubResourceStream(streamOpt)
+	}
+
+	return streamOpt
+}
+
 // SubResourceUpdateOptions holds all the possible configuration
 // for a subresource update request.
 type SubResourceUpdateOptions struct {
@@ -544,6 +658,31 @@ func (po *SubResourcePatchOptions) ApplyToSubResourcePatch(o *SubResourcePatchOp
 	}
 }
 
+// SubResourceApplyOptions holds all possible configurations for a subresource
+// apply request.
+type SubResourceApplyOptions struct {
+	ApplyOptions
+	SubResourceBody ApplyConfiguration
+}
+
+// ApplyOpts applies the given options. It is not called ApplyOptions as
+// that name is taken by the embedded ApplyOptions.
+func (ao *SubResourceApplyOptions) ApplyOpts(opts []SubResourceApplyOption) *SubResourceApplyOptions {
+	for _, o := range opts {
+		o.ApplyToSubResourceApply(ao)
+	}
+
+	return ao
+}
+
+// ApplyToSubResourceApply applies the configuration on the given apply options.
+func (ao *SubResourceApplyOptions) ApplyToSubResourceApply(o *SubResourceApplyOptions) {
+	ao.ApplyOptions.ApplyToApply(&o.ApplyOptions)
+	if ao.SubResourceBody != nil {
+		o.SubResourceBody = ao.SubResourceBody
+	}
+}
+
 func (sc *subResourceClient) Get(ctx context.Context, obj Object, subResource Object, opts ...SubResourceGetOption) error {
 	switch obj.(type) {
 	case runtime.Unstructured:
@@ -555,6 +694,18 @@ func (sc *subResourceClient) Get(ctx context.Context, obj Object, subResource Ob
 	}
 }
 
+// Stream implements client.SubResourceClient.
+func (sc *subResourceClient) Stream(ctx context.Context, obj Object, opts ...SubResourceStreamOption) (io.ReadCloser, error) {
+	switch obj.(type) {
+	case runtime.Unstructured:
+		return sc.client.unstructuredClient.StreamSubResource(ctx, obj, sc.subResource, opts...)
+	case *metav1.PartialObjectMetadata:
+		return nil, errors.New("can not stream subresource using only metadata")
+	default:
+		return sc.client.typedClient.StreamSubResource(ctx, obj, sc.subResource, opts...)
+	}
+}
+
 // Create implements client.SubResourceClient
 func (sc *subResourceClient) Create(ctx context.Context, obj Object, subResource Object, opts ...SubResourceCreateOption) error {
 	defer sc.client.resetGroupVersionKind(obj, obj.GetObjectKind().GroupVersionKind())
@@ -571,7 +722,12 @@ func (sc *subResourceClient) Create(ctx context.Context, obj Object, subResource
 }
 
 // Update implements client.SubResourceClient
-func (sc *subResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) error {
+func (sc *subResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) (err error) {
+	if sc.subResource == "status" && (&SubResourceUpdateOptions{}).ApplyOptions(opts).SubResourceBody == nil &&
+		sc.client.isNoOpUpdate(ctx, obj, "SubResourceUpdate") {
+		return nil
+	}
+	defer func() { sc.client.recordWrite(obj, err) }()
 	defer sc.client.resetGroupVersionKind(obj, obj.GetObjectKind().GroupVersionKind())
 	switch obj.(type) {
 	case runtime.Unstructured:
@@ -584,7 +740,12 @@ func (sc *subResourceClient) Update(ctx context.Context, obj Object, opts ...Sub
 }
 
 // Patch implements client.SubResourceWriter.
-func (sc *subResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
+func (sc *subResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) (err error) {
+	if sc.subResource == "status" && (&SubResourcePatchOptions{}).ApplyOptions(opts).SubResourceBody == nil &&
+		sc.client.isNoOpPatch(obj, patch, "SubResourcePatch") {
+		return sc.client.Get(ctx, ObjectKeyFromObject(obj), obj)
+	}
+	defer func() { sc.client.recordWrite(obj, err) }()
 	defer sc.client.resetGroupVersionKind(obj, obj.GetObjectKind().GroupVersionKind())
 	switch obj.(type) {
 	case runtime.Unstructured:
@@ -595,3 +756,16 @@ func (sc *subResourceClient) Patch(ctx context.Context, obj Object, patch Patch,
 		return sc.client.typedClient.PatchSubResource(ctx, obj, sc.subResource, patch, opts...)
 	}
 }
+
+// Apply implements client.SubResourceWriter.
+func (sc *subResourceClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...SubResourceApplyOption) (err error) {
+	defer func() { sc.client.recordApply(obj, err) }()
+	switch obj.(type) {
+	case runtime.Unstructured:
+		return sc.client.unstructuredClient.ApplySubResource(ctx, obj, sc.subResource, opts...)
+	case *metav1.PartialObjectMetadata:
+		return fmt.Errorf("cannot apply subresource using only metadata")
+	default:
+		return sc.client.typedClient.ApplySubResource(ctx, obj, sc.subResource, opts...)
+	}
+}
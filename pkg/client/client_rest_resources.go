@@ -52,6 +52,30 @@ type clientRestResources struct {
 	// unstructuredResourceByType stores unstructured type metadata
 	unstructuredResourceByType map[schema.GroupVersionKind]*resourceMeta
 	mu                         sync.RWMutex
+
+	// parent, if set, stores the type metadata and rest clients that are
+	// used instead of the ones above, with impersonationHeaders added to
+	// all requests
+	parent               *clientRestResources
+	impersonationHeaders http.Header
+}
+
+// impersonating returns clientRestResources that share the type metadata and
+// rest clients of c, but add the given impersonation headers to all requests.
+func (c *clientRestResources) impersonating(headers http.Header) *clientRestResources {
+	parent := c
+	if c.parent != nil {
+		parent = c.parent
+	}
+	return &clientRestResources{
+		httpClient:           c.httpClient,
+		config:               c.config,
+		scheme:               c.scheme,
+		mapper:               c.mapper,
+		codecs:               c.codecs,
+		parent:               parent,
+		impersonationHeaders: headers,
+	}
 }
 
 // newResource maps obj to a Kubernetes Resource and constructs a client for that Resource.
@@ -76,6 +100,18 @@ func (c *clientRestResources) newResource(gvk schema.GroupVersionKind, isList, i
 // getResource returns the resource meta information for the given type of object.
 // If the object is a list, the resource represents the item's type instead.
 func (c *clientRestResources) getResource(obj runtime.Object) (*resourceMeta, error) {
+	if c.parent != nil {
+		r, err := c.parent.getResource(obj)
+		if err != nil {
+			return nil, err
+		}
+		return &resourceMeta{
+			Interface: &impersonatingRESTClient{Interface: r.Interface, headers: c.impersonationHeaders},
+			gvk:       r.gvk,
+			mapping:   r.mapping,
+		}, nil
+	}
+
 	gvk, err := apiutil.GVKForObject(obj, c.scheme)
 	if err != nil {
 		return nil, err
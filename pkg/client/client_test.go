@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -37,15 +38,21 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/controller-runtime/examples/crd/pkg"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func deleteDeployment(ctx context.Context, dep *appsv1.Deployment, ns string) {
@@ -796,6 +803,123 @@ U5wwSivyi7vmegHKmblOzNVKA5qPO8zWzqBC
 		})
 	})
 
+	Describe("Apply", func() {
+		Context("with apply configurations", func() {
+			It("should create an object that doesn't exist and return the server result", func() {
+				cl, err := client.New(cfg, client.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				deployment := appsv1ac.Deployment(dep.Name, ns).
+					WithSpec(appsv1ac.DeploymentSpec().
+						WithReplicas(1).
+						WithSelector(metav1ac.LabelSelector().WithMatchLabels(map[string]string{"foo": "bar"})).
+						WithTemplate(corev1ac.PodTemplateSpec().
+							WithLabels(map[string]string{"foo": "bar"}).
+							WithSpec(corev1ac.PodSpec().WithContainers(corev1ac.Container().WithName("nginx").WithImage("nginx")))))
+				Expect(cl.Apply(ctx, deployment, client.FieldOwner("test-owner"))).To(Succeed())
+				Expect(deployment.UID).NotTo(BeNil())
+				Expect(deployment.ResourceVersion).NotTo(BeNil())
+
+				actual, err := clientset.AppsV1().Deployments(ns).Get(ctx, dep.Name, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*actual.Spec.Replicas).To(BeEquivalentTo(1))
+			})
+
+			It("should only take ownership of the fields that are set", func() {
+				cl, err := client.New(cfg, client.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("initially creating a Deployment")
+				dep, err := clientset.AppsV1().Deployments(ns).Create(ctx, dep, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("applying a label")
+				deployment := appsv1ac.Deployment(dep.Name, ns).WithLabels(map[string]string{"applied": "label"})
+				Expect(cl.Apply(ctx, deployment, client.FieldOwner("test-owner"))).To(Succeed())
+
+				actual, err := clientset.AppsV1().Deployments(ns).Get(ctx, dep.Name, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actual.Labels).To(HaveKeyWithValue("applied", "label"))
+				Expect(*actual.Spec.Replicas).To(Equal(replicaCount))
+			})
+
+			It("should return a conflict when another manager owns the field", func() {
+				cl, err := client.New(cfg, client.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				deployment := appsv1ac.Deployment(dep.Name, ns).WithLabels(map[string]string{"applied": "first"})
+				Expect(cl.Apply(ctx, deployment, client.FieldOwner("first-owner"))).To(Succeed())
+
+				deployment = appsv1ac.Deployment(dep.Name, ns).WithLabels(map[string]string{"applied": "second"})
+				err = cl.Apply(ctx, deployment, client.FieldOwner("second-owner"))
+				Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+				Expect(cl.Apply(ctx, deployment, client.FieldOwner("second-owner"), client.ForceOwnership)).To(Succeed())
+				Expect(deployment.Labels).To(HaveKeyWithValue("applied", "second"))
+			})
+
+			It("should apply the status subresource", func() {
+				cl, err := client.New(cfg, client.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("initially creating a Deployment")
+				dep, err := clientset.AppsV1().Deployments(ns).Create(ctx, dep, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				deployment := appsv1ac.Deployment(dep.Name, ns).WithStatus(appsv1ac.DeploymentStatus().WithReplicas(3))
+				Expect(cl.Status().Apply(ctx, deployment, client.FieldOwner("test-owner"))).To(Succeed())
+				Expect(*deployment.Status.Replicas).To(BeEquivalentTo(3))
+
+				actual, err := clientset.AppsV1().Deployments(ns).Get(ctx, dep.Name, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actual.Status.Replicas).To(BeEquivalentTo(3))
+			})
+		})
+
+		Context("with unstructured objects", func() {
+			It("should apply and preserve type information", func() {
+				cl, err := client.New(cfg, client.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("initially creating a Deployment")
+				dep, err := clientset.AppsV1().Deployments(ns).Create(ctx, dep, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				u := &unstructured.Unstructured{}
+				u.SetGroupVersionKind(depGvk)
+				u.SetNamespace(ns)
+				u.SetName(dep.Name)
+				u.SetLabels(map[string]string{"applied": "label"})
+				Expect(cl.Apply(ctx, u, client.FieldOwner("test-owner"))).To(Succeed())
+				Expect(u.GroupVersionKind()).To(Equal(depGvk))
+				Expect(u.GetLabels()).To(HaveKeyWithValue("applied", "label"))
+			})
+		})
+
+		Context("with metadata objects", func() {
+			It("should apply metadata", func() {
+				cl, err := client.New(cfg, client.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("initially creating a Deployment")
+				dep, err := clientset.AppsV1().Deployments(ns).Create(ctx, dep, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				metadata := &metav1.PartialObjectMetadata{}
+				metadata.SetGroupVersionKind(depGvk)
+				metadata.SetNamespace(ns)
+				metadata.SetName(dep.Name)
+				metadata.SetAnnotations(map[string]string{"applied": "annotation"})
+				Expect(cl.Apply(ctx, metadata, client.FieldOwner("test-owner"))).To(Succeed())
+				Expect(metadata.GroupVersionKind()).To(Equal(depGvk))
+
+				actual, err := clientset.AppsV1().Deployments(ns).Get(ctx, dep.Name, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actual.Annotations).To(HaveKeyWithValue("applied", "annotation"))
+			})
+		})
+	})
+
 	Describe("SubResourceClient", func() {
 		Context("with structured objects", func() {
 			It("should be able to read the Scale subresource", func() {
@@ -3741,6 +3865,197 @@ var _ = Describe("ClientWithCache", func() {
 	})
 })
 
+var _ = Describe("ClientWithReadYourWrites", func() {
+	var cachedReader *staleReader
+	var cm *corev1.ConfigMap
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		cachedReader = &staleReader{}
+		cachedReader.Sync()
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "read-your-writes", Namespace: "default"},
+			Data:       map[string]string{"key": "value"},
+		}
+	})
+
+	AfterEach(func() {
+		err := clientset.CoreV1().ConfigMaps(cm.Namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{})
+		Expect(client.IgnoreNotFound(err)).To(Succeed())
+	})
+
+	It("should wait until the cache has observed a write before getting the object", func() {
+		cl, err := client.New(cfg, client.Options{Cache: &client.CacheOptions{Reader: cachedReader, ReadYourWrites: true}})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the object")
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+
+		By("syncing the cache with a delay")
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(100 * time.Millisecond)
+			cachedReader.Sync(cm.DeepCopy())
+		}()
+
+		By("getting the object from the cache")
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), actual)).To(Succeed())
+		Expect(actual.ResourceVersion).To(Equal(cm.ResourceVersion))
+		Expect(cachedReader.Called()).To(BeNumerically(">", 1))
+	})
+
+	It("should wait until the cache has observed a write before listing the objects", func() {
+		cl, err := client.New(cfg, client.Options{Cache: &client.CacheOptions{Reader: cachedReader, ReadYourWrites: true}})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the object")
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+
+		By("syncing the cache with a delay")
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(100 * time.Millisecond)
+			cachedReader.Sync(cm.DeepCopy())
+		}()
+
+		By("listing the objects from the cache")
+		actual := &corev1.ConfigMapList{}
+		Expect(cl.List(ctx, actual, client.InNamespace(cm.Namespace))).To(Succeed())
+		Expect(actual.Items).To(ConsistOf(HaveField("ObjectMeta.ResourceVersion", cm.ResourceVersion)))
+	})
+
+	It("should wait until the cache has observed a deletion", func() {
+		cl, err := client.New(cfg, client.Options{Cache: &client.CacheOptions{Reader: cachedReader, ReadYourWrites: true}})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the object and syncing the cache")
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+		cachedReader.Sync(cm.DeepCopy())
+
+		By("deleting the object and syncing the cache with a delay")
+		Expect(cl.Delete(ctx, cm)).To(Succeed())
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(100 * time.Millisecond)
+			cachedReader.Sync()
+		}()
+
+		By("getting the object from the cache")
+		err = cl.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should fall back to a live read if the cache doesn't observe a write in time", func() {
+		cl, err := client.New(cfg, client.Options{Cache: &client.CacheOptions{
+			Reader:                cachedReader,
+			ReadYourWrites:        true,
+			ReadYourWritesTimeout: 100 * time.Millisecond,
+		}})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the object")
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+
+		By("getting the object without syncing the cache")
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), actual)).To(Succeed())
+		Expect(actual.ResourceVersion).To(Equal(cm.ResourceVersion))
+
+		By("reading from the cache again once the write was read")
+		err = cl.Get(ctx, client.ObjectKeyFromObject(cm), actual)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	Context("with a cache that doesn't hold all objects", func() {
+		var cacheCtx context.Context
+		var cancel context.CancelFunc
+
+		BeforeEach(func() {
+			cacheCtx, cancel = context.WithCancel(ctx)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		newClientWithCache := func(opts cache.Options) client.Client {
+			c, err := cache.New(cfg, opts)
+			Expect(err).NotTo(HaveOccurred())
+			go func() {
+				defer GinkgoRecover()
+				Expect(c.Start(cacheCtx)).To(Succeed())
+			}()
+			Expect(c.WaitForCacheSync(cacheCtx)).To(BeTrue())
+
+			cl, err := client.New(cfg, client.Options{Cache: &client.CacheOptions{Reader: c, ReadYourWrites: true}})
+			Expect(err).NotTo(HaveOccurred())
+			return cl
+		}
+
+		It("should not wait for writes in namespaces the cache doesn't hold", func() {
+			cl := newClientWithCache(cache.Options{DefaultNamespaces: map[string]cache.Config{"kube-public": {}}})
+
+			By("creating the object outside of the namespaces of the cache")
+			Expect(cl.Create(ctx, cm)).To(Succeed())
+
+			By("listing the objects of all namespaces from the cache")
+			actual := &corev1.ConfigMapList{}
+			Expect(cl.List(ctx, actual)).To(Succeed())
+			Expect(actual.Items).NotTo(ContainElement(HaveField("ObjectMeta.Name", cm.Name)))
+		})
+
+		It("should not wait for writes of objects that don't match the label selector of the cache", func() {
+			cl := newClientWithCache(cache.Options{DefaultLabelSelector: labels.SelectorFromSet(labels.Set{"cached": "true"})})
+
+			By("creating an object that doesn't match the label selector")
+			Expect(cl.Create(ctx, cm)).To(Succeed())
+
+			By("listing the objects from the cache without waiting")
+			start := time.Now()
+			actual := &corev1.ConfigMapList{}
+			Expect(cl.List(ctx, actual, client.InNamespace(cm.Namespace))).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(actual.Items).To(BeEmpty())
+		})
+
+		It("should read live if it's unknown whether the cache holds a written object", func() {
+			cl := newClientWithCache(cache.Options{DefaultFieldSelector: fields.OneTermEqualSelector("spec.nodeName", "read-your-writes")})
+
+			By("creating an object that matches the field selector")
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "read-your-writes", Namespace: "default"},
+				Spec:       corev1.PodSpec{NodeName: "read-your-writes", Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+			}
+			Expect(cl.Create(ctx, pod)).To(Succeed())
+			defer func() {
+				Expect(client.IgnoreNotFound(cl.Delete(ctx, pod))).To(Succeed())
+			}()
+
+			By("getting and listing the object right away")
+			actual := &corev1.Pod{}
+			Expect(cl.Get(ctx, client.ObjectKeyFromObject(pod), actual)).To(Succeed())
+			Expect(actual.ResourceVersion).To(Equal(pod.ResourceVersion))
+
+			pod.Labels = map[string]string{"updated": "true"}
+			Expect(cl.Update(ctx, pod)).To(Succeed())
+			list := &corev1.PodList{}
+			Expect(cl.List(ctx, list, client.InNamespace(pod.Namespace))).To(Succeed())
+			Expect(list.Items).To(ContainElement(HaveField("ObjectMeta.ResourceVersion", pod.ResourceVersion)))
+		})
+	})
+
+	It("should read stale objects from the cache if disabled", func() {
+		cl, err := client.New(cfg, client.Options{Cache: &client.CacheOptions{Reader: cachedReader}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+		err = cl.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(cachedReader.Called()).To(Equal(1))
+	})
+})
+
 var _ = Describe("Patch", func() {
 	Describe("MergeFrom", func() {
 		var cm *corev1.ConfigMap
@@ -3951,6 +4266,42 @@ func (f *fakeReader) List(ctx context.Context, list client.ObjectList, opts ...c
 	return nil
 }
 
+// staleReader is a cache reader whose content is only updated by calling Sync.
+type staleReader struct {
+	mu     sync.Mutex
+	reader client.Reader
+	called int
+}
+
+// Sync replaces the content of the reader with the given objects.
+func (r *staleReader) Sync(objs ...client.Object) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reader = fake.NewClientBuilder().WithObjects(objs...).Build()
+}
+
+func (r *staleReader) Called() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.called
+}
+
+func (r *staleReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.mu.Lock()
+	r.called++
+	reader := r.reader
+	r.mu.Unlock()
+	return reader.Get(ctx, key, obj, opts...)
+}
+
+func (r *staleReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	r.mu.Lock()
+	r.called++
+	reader := r.reader
+	r.mu.Unlock()
+	return reader.List(ctx, list, opts...)
+}
+
 type fakeUncachedReader struct {
 	Called int
 }
@@ -0,0 +1,286 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/internal/metrics"
+)
+
+// OnConflict is an option for Update, Patch and their subresource equivalents
+// that is used by the client returned by WithConflictRetry. If the write fails
+// with a conflict, the client re-reads the object, calls the function with it
+// to re-apply the intended changes and then retries the write.
+//
+// OnConflict has no effect on writes of any other client.
+type OnConflict func(obj Object) error
+
+// ApplyToUpdate implements UpdateOption.
+func (f OnConflict) ApplyToUpdate(*UpdateOptions) {}
+
+// ApplyToPatch implements PatchOption.
+func (f OnConflict) ApplyToPatch(*PatchOptions) {}
+
+// ApplyToSubResourceUpdate implements SubResourceUpdateOption.
+func (f OnConflict) ApplyToSubResourceUpdate(*SubResourceUpdateOptions) {}
+
+// ApplyToSubResourcePatch implements SubResourcePatchOption.
+func (f OnConflict) ApplyToSubResourcePatch(*SubResourcePatchOptions) {}
+
+// onConflictFrom returns the last OnConflict in the given options, if any.
+func onConflictFrom[T any](opts []T) OnConflict {
+	var mutate OnConflict
+	for _, opt := range opts {
+		if f, ok := any(opt).(OnConflict); ok {
+			mutate = f
+		}
+	}
+	return mutate
+}
+
+// WithConflictRetry wraps an existing client and retries Update, Patch and
+// their subresource equivalents that fail with a conflict, using the given
+// backoff. retry.DefaultRetry from k8s.io/client-go/util/retry is a sensible
+// default.
+//
+// Only writes passed an OnConflict option are retried: Before every retry, the
+// object is re-read using the wrapped client and the OnConflict function is
+// called with it. Patches are only retried if they were created by MergeFrom
+// or one of its variants, in which case the patch is recomputed from the
+// re-read object. Other writes are passed through unchanged.
+//
+// If the wrapped client reads from a cache, the re-read object might still be
+// outdated, causing another conflict.
+//
+// Retries are counted in the controller_runtime_client_conflict_retries_total
+// metric of the controller-runtime metrics registry.
+func WithConflictRetry(c Client, backoff wait.Backoff) Client {
+	return &conflictRetryClient{client: c, backoff: backoff}
+}
+
+var _ Client = &conflictRetryClient{}
+
+// conflictRetryClient is a Client that wraps another Client in order to retry
+// writes that fail with a conflict.
+type conflictRetryClient struct {
+	client  Client
+	backoff wait.Backoff
+}
+
+// retryOnConflict calls write until it doesn't fail with a conflict or the
+// backoff is exhausted. Before every retry, obj is re-read and mutated.
+func (c *conflictRetryClient) retryOnConflict(ctx context.Context, operation string, obj Object, mutate OnConflict, write func() error) error {
+	gvk, _ := c.client.GroupVersionKindFor(obj)
+
+	attempt := 0
+	err := retry.OnError(c.backoff, apierrors.IsConflict, func() error {
+		if attempt > 0 {
+			metrics.ConflictRetries.WithLabelValues(gvk.Group, gvk.Kind, operation).Inc()
+			if err := c.reread(ctx, obj, gvk); err != nil {
+				return err
+			}
+			if err := mutate(obj); err != nil {
+				return err
+			}
+		}
+		attempt++
+		return write()
+	})
+	if apierrors.IsConflict(err) {
+		metrics.ConflictRetriesExhausted.WithLabelValues(gvk.Group, gvk.Kind, operation).Inc()
+	}
+	return err
+}
+
+// reread replaces obj with its current state. obj is reset first, as decoding
+// into an existing object keeps fields that were removed on the server.
+func (c *conflictRetryClient) reread(ctx context.Context, obj Object, gvk schema.GroupVersionKind) error {
+	key := ObjectKeyFromObject(obj)
+	typeMeta := obj.GetObjectKind().GroupVersionKind()
+	v := reflect.ValueOf(obj).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	if typeMeta.Empty() {
+		typeMeta = gvk
+	}
+	obj.GetObjectKind().SetGroupVersionKind(typeMeta)
+	return c.client.Get(ctx, key, obj)
+}
+
+// rebasePatch returns an OnConflict that calls mutate and replaces the merge
+// patch *patch with one from the re-read object to the mutated object.
+func rebasePatch(patch *Patch, mergeFrom *mergeFromPatch, mutate OnConflict) OnConflict {
+	return func(obj Object) error {
+		rebased := *mergeFrom
+		rebased.from = obj.DeepCopyObject().(Object)
+		if err := mutate(obj); err != nil {
+			return err
+		}
+		*patch = &rebased
+		return nil
+	}
+}
+
+// Scheme returns the scheme this client is using.
+func (c *conflictRetryClient) Scheme() *runtime.Scheme {
+	return c.client.Scheme()
+}
+
+// RESTMapper returns the rest mapper this client is using.
+func (c *conflictRetryClient) RESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GroupVersionKindFor returns the GroupVersionKind for the given object.
+func (c *conflictRetryClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return c.client.GroupVersionKindFor(obj)
+}
+
+// IsObjectNamespaced returns true if the GroupVersionKind of the object is namespaced.
+func (c *conflictRetryClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return c.client.IsObjectNamespaced(obj)
+}
+
+// Create implements client.Client.
+func (c *conflictRetryClient) Create(ctx context.Context, obj Object, opts ...CreateOption) error {
+	return c.client.Create(ctx, obj, opts...)
+}
+
+// Update implements client.Client.
+func (c *conflictRetryClient) Update(ctx context.Context, obj Object, opts ...UpdateOption) error {
+	mutate := onConflictFrom(opts)
+	if mutate == nil {
+		return c.client.Update(ctx, obj, opts...)
+	}
+	return c.retryOnConflict(ctx, "Update", obj, mutate, func() error {
+		return c.client.Update(ctx, obj, opts...)
+	})
+}
+
+// Delete implements client.Client.
+func (c *conflictRetryClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption) error {
+	return c.client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf implements client.Client.
+func (c *conflictRetryClient) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) error {
+	return c.client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Patch implements client.Client.
+func (c *conflictRetryClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error {
+	mutate := onConflictFrom(opts)
+	mergeFrom, ok := patch.(*mergeFromPatch)
+	if mutate == nil || !ok {
+		return c.client.Patch(ctx, obj, patch, opts...)
+	}
+	return c.retryOnConflict(ctx, "Patch", obj, rebasePatch(&patch, mergeFrom, mutate), func() error {
+		return c.client.Patch(ctx, obj, patch, opts...)
+	})
+}
+
+// Apply implements client.Client.
+func (c *conflictRetryClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) error {
+	return c.client.Apply(ctx, obj, opts...)
+}
+
+// Get implements client.Client.
+func (c *conflictRetryClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
+	return c.client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Client.
+func (c *conflictRetryClient) List(ctx context.Context, obj ObjectList, opts ...ListOption) error {
+	return c.client.List(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient.
+func (c *conflictRetryClient) Status() SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClientConstructor.
+func (c *conflictRetryClient) SubResource(subResource string) SubResourceClient {
+	return &conflictRetrySubResourceClient{
+		client:      c,
+		subResource: subResource,
+		wrapped:     c.client.SubResource(subResource),
+	}
+}
+
+var _ SubResourceClient = &conflictRetrySubResourceClient{}
+var _ SubResourceStreamer = &conflictRetrySubResourceClient{}
+
+// conflictRetrySubResourceClient is a SubResourceClient that retries writes
+// that fail with a conflict. The object is re-read using the main resource.
+type conflictRetrySubResourceClient struct {
+	client      *conflictRetryClient
+	subResource string
+	wrapped     SubResourceClient
+}
+
+// Get implements client.SubResourceReader.
+func (sc *conflictRetrySubResourceClient) Get(ctx context.Context, obj, subResource Object, opts ...SubResourceGetOption) error {
+	return sc.wrapped.Get(ctx, obj, subResource, opts...)
+}
+
+// Stream implements client.SubResourceStreamer.
+func (sc *conflictRetrySubResourceClient) Stream(ctx context.Context, obj Object, opts ...SubResourceStreamOption) (io.ReadCloser, error) {
+	return StreamSubResource(ctx, sc.wrapped, obj, opts...)
+}
+
+// Create implements client.SubResourceWriter.
+func (sc *conflictRetrySubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
+	return sc.wrapped.Create(ctx, obj, subResource, opts...)
+}
+
+// Update implements client.SubResourceWriter.
+func (sc *conflictRetrySubResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) error {
+	mutate := onConflictFrom(opts)
+	if mutate == nil {
+		return sc.wrapped.Update(ctx, obj, opts...)
+	}
+	return sc.client.retryOnConflict(ctx, "SubResourceUpdate", obj, mutate, func() error {
+		return sc.wrapped.Update(ctx, obj, opts...)
+	})
+}
+
+// Patch implements client.SubResourceWriter.
+func (sc *conflictRetrySubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
+	mutate := onConflictFrom(opts)
+	mergeFrom, ok := patch.(*mergeFromPatch)
+	if mutate == nil || !ok {
+		return sc.wrapped.Patch(ctx, obj, patch, opts...)
+	}
+	return sc.client.retryOnConflict(ctx, "SubResourcePatch", obj, rebasePatch(&patch, mergeFrom, mutate), func() error {
+		return sc.wrapped.Patch(ctx, obj, patch, opts...)
+	})
+}
+
+// Apply implements client.SubResourceWriter.
+func (sc *conflictRetrySubResourceClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...SubResourceApplyOption) error {
+	return sc.wrapped.Apply(ctx, obj, opts...)
+}
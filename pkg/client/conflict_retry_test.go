@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/client/internal/metrics"
+)
+
+var _ = Describe("ConflictRetryClient", func() {
+	var cm *corev1.ConfigMap
+	var dep *appsv1.Deployment
+	var fakeClient client.WithWatch
+	var c client.Client
+	backoff := wait.Backoff{Steps: 3}
+	ctx := context.Background()
+
+	// bumpResourceVersion updates the object behind the back of the retrying
+	// client, so that the next write of a copy read before conflicts.
+	bumpResourceVersion := func(obj client.Object) {
+		current := obj.DeepCopyObject().(client.Object)
+		ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKeyFromObject(obj), current)).To(Succeed())
+		current.SetLabels(map[string]string{"bumped": "true"})
+		ExpectWithOffset(1, fakeClient.Update(ctx, current)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+			Data:       map[string]string{"a": "1"},
+		}
+		dep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "default"},
+		}
+		fakeClient = fake.NewClientBuilder().WithObjects(cm, dep).WithStatusSubresource(dep).Build()
+		c = client.WithConflictRetry(fakeClient, backoff)
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(dep), dep)).To(Succeed())
+	})
+
+	It("should re-read the object and re-apply the mutation on Update conflicts", func() {
+		retries := testutil.ToFloat64(metrics.ConflictRetries.WithLabelValues("", "ConfigMap", "Update"))
+		bumpResourceVersion(cm)
+
+		mutate := func(obj client.Object) error {
+			obj.(*corev1.ConfigMap).Data["b"] = "2"
+			return nil
+		}
+		Expect(mutate(cm)).To(Succeed())
+		Expect(c.Update(ctx, cm, client.OnConflict(mutate))).To(Succeed())
+
+		actual := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), actual)).To(Succeed())
+		Expect(actual.Labels).To(HaveKeyWithValue("bumped", "true"))
+		Expect(actual.Data).To(Equal(map[string]string{"a": "1", "b": "2"}))
+		Expect(testutil.ToFloat64(metrics.ConflictRetries.WithLabelValues("", "ConfigMap", "Update"))).To(Equal(retries + 1))
+	})
+
+	It("should not retry Updates without OnConflict", func() {
+		bumpResourceVersion(cm)
+		cm.Data["b"] = "2"
+		Expect(apierrors.IsConflict(c.Update(ctx, cm))).To(BeTrue())
+	})
+
+	It("should retry status updates", func() {
+		bumpResourceVersion(dep)
+
+		mutate := func(obj client.Object) error {
+			obj.(*appsv1.Deployment).Status.Replicas = 3
+			return nil
+		}
+		Expect(mutate(dep)).To(Succeed())
+		Expect(c.Status().Update(ctx, dep, client.OnConflict(mutate))).To(Succeed())
+
+		actual := &appsv1.Deployment{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(dep), actual)).To(Succeed())
+		Expect(actual.Labels).To(HaveKeyWithValue("bumped", "true"))
+		Expect(actual.Status.Replicas).To(BeEquivalentTo(3))
+	})
+
+	It("should recompute merge patches with optimistic lock", func() {
+		bumpResourceVersion(cm)
+
+		mutate := func(obj client.Object) error {
+			obj.(*corev1.ConfigMap).Data["b"] = "2"
+			return nil
+		}
+		patch := client.MergeFromWithOptions(cm.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		Expect(mutate(cm)).To(Succeed())
+		Expect(c.Patch(ctx, cm, patch, client.OnConflict(mutate))).To(Succeed())
+
+		actual := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), actual)).To(Succeed())
+		Expect(actual.Labels).To(HaveKeyWithValue("bumped", "true"))
+		Expect(actual.Data).To(Equal(map[string]string{"a": "1", "b": "2"}))
+	})
+
+	It("should return the error of the mutation", func() {
+		bumpResourceVersion(cm)
+
+		mutateErr := errors.New("mutation failed")
+		err := c.Update(ctx, cm, client.OnConflict(func(client.Object) error { return mutateErr }))
+		Expect(err).To(MatchError(mutateErr))
+	})
+
+	It("should give up after the backoff is exhausted", func() {
+		exhausted := testutil.ToFloat64(metrics.ConflictRetriesExhausted.WithLabelValues("", "ConfigMap", "Update"))
+		updates := 0
+		c = client.WithConflictRetry(interceptor.NewClient(fakeClient, interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				updates++
+				return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(), errors.New("conflict"))
+			},
+		}), backoff)
+
+		err := c.Update(ctx, cm, client.OnConflict(func(client.Object) error { return nil }))
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(updates).To(Equal(backoff.Steps))
+		Expect(testutil.ToFloat64(metrics.ConflictRetriesExhausted.WithLabelValues("", "ConfigMap", "Update"))).To(Equal(exhausted + 1))
+	})
+})
@@ -28,6 +28,11 @@ limitations under the License.
 // It is a common pattern in Kubernetes to read from a cache and write to the API
 // server.  This pattern is covered by the creating the Client with a Cache.
 //
+// Writers also support server-side apply through Apply, which takes the apply
+// configurations generated in k8s.io/client-go/applyconfigurations (or
+// unstructured objects) so that only the fields that are set are owned by
+// the given field manager.
+//
 // # Options
 //
 // Many client operations in Kubernetes support options.  These options are
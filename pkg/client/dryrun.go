@@ -18,6 +18,7 @@ package client
 
 import (
 	"context"
+	"io"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -82,6 +83,11 @@ func (c *dryRunClient) Patch(ctx context.Context, obj Object, patch Patch, opts
 	return c.client.Patch(ctx, obj, patch, append(opts, DryRunAll)...)
 }
 
+// Apply implements client.Client.
+func (c *dryRunClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) error {
+	return c.client.Apply(ctx, obj, append(opts, DryRunAll)...)
+}
+
 // Get implements client.Client.
 func (c *dryRunClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
 	return c.client.Get(ctx, key, obj, opts...)
@@ -104,6 +110,7 @@ func (c *dryRunClient) SubResource(subResource string) SubResourceClient {
 
 // ensure dryRunSubResourceWriter implements client.SubResourceWriter.
 var _ SubResourceWriter = &dryRunSubResourceClient{}
+var _ SubResourceStreamer = &dryRunSubResourceClient{}
 
 // dryRunSubResourceClient is client.SubResourceWriter that writes status subresource with dryRun mode
 // enforced.
@@ -115,6 +122,11 @@ func (sw *dryRunSubResourceClient) Get(ctx context.Context, obj, subResource Obj
 	return sw.client.Get(ctx, obj, subResource, opts...)
 }
 
+// Stream implements client.SubResourceStreamer.
+func (sw *dryRunSubResourceClient) Stream(ctx context.Context, obj Object, opts ...SubResourceStreamOption) (io.ReadCloser, error) {
+	return StreamSubResource(ctx, sw.client, obj, opts...)
+}
+
 func (sw *dryRunSubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
 	return sw.client.Create(ctx, obj, subResource, append(opts, DryRunAll)...)
 }
@@ -128,3 +140,8 @@ func (sw *dryRunSubResourceClient) Update(ctx context.Context, obj Object, opts
 func (sw *dryRunSubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
 	return sw.client.Patch(ctx, obj, patch, append(opts, DryRunAll)...)
 }
+
+// Apply implements client.SubResourceWriter.
+func (sw *dryRunSubResourceClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...SubResourceApplyOption) error {
+	return sw.client.Apply(ctx, obj, append(opts, DryRunAll)...)
+}
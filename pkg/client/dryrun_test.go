@@ -28,6 +28,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -115,6 +116,17 @@ var _ = Describe("DryRunClient", func() {
 		Expect(apierrors.IsNotFound(err)).To(BeTrue())
 	})
 
+	It("should not apply an object", func() {
+		deployment := appsv1ac.Deployment(dep.Name, ns).WithLabels(map[string]string{"applied": "label"})
+
+		Expect(getClient().Apply(ctx, deployment, client.FieldOwner("test-owner"))).NotTo(HaveOccurred())
+		Expect(deployment.Labels).To(HaveKeyWithValue("applied", "label"))
+
+		actual, err := clientset.AppsV1().Deployments(ns).Get(ctx, dep.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.Labels).NotTo(HaveKey("applied"))
+	})
+
 	It("should refuse a create request for an invalid object", func() {
 		changedDep := dep.DeepCopy()
 		changedDep.Spec.Template.Spec.Containers = nil
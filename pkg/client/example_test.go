@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -188,6 +189,16 @@ func ExampleClient_patch() {
 	}, client.RawPatch(types.StrategicMergePatchType, patch))
 }
 
+// This example shows how to use the client with apply configurations to
+// server-side apply objects. Only the fields that are set are owned by the
+// field manager.
+func ExampleClient_apply() {
+	cm := corev1ac.ConfigMap("name", "namespace").
+		WithLabels(map[string]string{"app": "example"}).
+		WithData(map[string]string{"key": "value"})
+	_ = c.Apply(context.Background(), cm, client.FieldOwner("example-controller"), client.ForceOwnership)
+}
+
 // This example shows how to use the client with typed and unstructured objects to patch objects' status.
 func ExampleClient_patchStatus() {
 	u := &unstructured.Unstructured{}
@@ -246,6 +257,28 @@ func ExampleClient_deleteAllOf() {
 	_ = c.DeleteAllOf(context.Background(), u, client.InNamespace("foo"), client.MatchingLabels{"app": "foo"})
 }
 
+// This example shows how to use a TypedClient to read and write objects without
+// allocating them upfront.
+func ExampleNewTypedClient() {
+	// c is a created client.
+	pods := client.NewTypedClient[*corev1.Pod, *corev1.PodList](c)
+
+	pod, err := pods.Get(context.Background(), client.ObjectKey{Namespace: "namespace", Name: "name"})
+	if err != nil {
+		return
+	}
+	pod.Labels = map[string]string{"app": "foo"}
+	_ = pods.Update(context.Background(), pod)
+
+	list, err := pods.List(context.Background(), client.InNamespace("namespace"))
+	if err != nil {
+		return
+	}
+	for _, pod := range list {
+		fmt.Println(pod.Name)
+	}
+}
+
 // This example shows how to set up and consume a field selector over a pod's volumes' secretName field.
 func ExampleFieldIndexer_secretName() {
 	// someIndexer is a FieldIndexer over a Cache
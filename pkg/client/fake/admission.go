@@ -0,0 +1,296 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// WithDefaulter registers a defaulter that the client calls for objects of the
+// same kind as obj, like an API server calls a mutating admission webhook.
+//
+// On Create, Update, Patch, Apply, Delete and DeleteAllOf, all defaulters and
+// admission handlers are called first, in the order they were registered, and
+// the patches they return are applied to the object. Then all validators are
+// called. If one of them denies the request, nothing is persisted and the
+// client returns a StatusError like the API server does. Warnings are passed
+// to the warning handler of the client. Requests for subresources, e.g. status
+// updates, are not admitted.
+//
+// Dry-run requests for kinds with admission plugins are admitted like the API
+// server does, so unlike for other kinds, a dry-run Create sets the generated
+// name and the defaults on the object, and a dry-run Delete of an object that
+// doesn't exist or doesn't match the preconditions fails.
+func (f *ClientBuilder) WithDefaulter(obj client.Object, defaulter admission.CustomDefaulter) *ClientBuilder {
+	f.admissionPlugins = append(f.admissionPlugins, admissionRegistration{
+		object:   obj,
+		mutating: true,
+		handler: func(scheme *runtime.Scheme) admission.Handler {
+			return admission.WithCustomDefaulter(scheme, obj, defaulter)
+		},
+	})
+	return f
+}
+
+// WithValidator registers a validator that the client calls for objects of
+// the same kind as obj, like an API server calls a validating admission
+// webhook. See WithDefaulter for how admission plugins are called.
+func (f *ClientBuilder) WithValidator(obj client.Object, validator admission.CustomValidator) *ClientBuilder {
+	f.admissionPlugins = append(f.admissionPlugins, admissionRegistration{
+		object: obj,
+		handler: func(scheme *runtime.Scheme) admission.Handler {
+			return admission.WithCustomValidator(scheme, obj, validator)
+		},
+	})
+	return f
+}
+
+// WithAdmissionHandler registers an admission handler that the client calls
+// for objects of the same kind as obj, like an API server calls a mutating
+// admission webhook. See WithDefaulter for how admission plugins are called.
+func (f *ClientBuilder) WithAdmissionHandler(obj client.Object, handler admission.Handler) *ClientBuilder {
+	f.admissionPlugins = append(f.admissionPlugins, admissionRegistration{
+		object:   obj,
+		mutating: true,
+		handler: func(*runtime.Scheme) admission.Handler {
+			return &admission.Webhook{Handler: handler}
+		},
+	})
+	return f
+}
+
+// WithWarningHandler sets the handler for the warnings returned by admission
+// plugins. Defaults to logging them, like the warning handler of client.New.
+func (f *ClientBuilder) WithWarningHandler(handler rest.WarningHandler) *ClientBuilder {
+	f.warningHandler = handler
+	return f
+}
+
+// admissionRegistration is a plugin registered with WithDefaulter,
+// WithValidator or WithAdmissionHandler.
+type admissionRegistration struct {
+	object   client.Object
+	mutating bool
+	// handler returns the handler of the plugin for the scheme of the client.
+	handler func(*runtime.Scheme) admission.Handler
+}
+
+// admissionWebhook is an admission plugin together with the name it is
+// reported as in errors.
+type admissionWebhook struct {
+	name    string
+	handler admission.Handler
+}
+
+// admissionChain are the admission plugins of a kind.
+type admissionChain struct {
+	mutating   []admissionWebhook
+	validating []admissionWebhook
+}
+
+// buildAdmissionChains turns the registered plugins into admission webhooks
+// by kind. The webhooks are named like the paths the webhook builder serves
+// them at.
+func buildAdmissionChains(scheme *runtime.Scheme, registrations []admissionRegistration) map[schema.GroupVersionKind]*admissionChain {
+	chains := map[schema.GroupVersionKind]*admissionChain{}
+	for _, registration := range registrations {
+		gvk, err := apiutil.GVKForObject(registration.object, scheme)
+		if err != nil {
+			panic(fmt.Errorf("failed to get gvk for admission object %T: %w", registration.object, err))
+		}
+		chain := chains[gvk]
+		if chain == nil {
+			chain = &admissionChain{}
+			chains[gvk] = chain
+		}
+		suffix := strings.ReplaceAll(gvk.Group, ".", "-") + "-" + gvk.Version + "-" + strings.ToLower(gvk.Kind)
+
+		if registration.mutating {
+			chain.mutating = append(chain.mutating, admissionWebhook{name: "mutate-" + suffix, handler: registration.handler(scheme)})
+		} else {
+			chain.validating = append(chain.validating, admissionWebhook{name: "validate-" + suffix, handler: registration.handler(scheme)})
+		}
+	}
+	return chains
+}
+
+// hasAdmission returns whether admission plugins are registered for the kind
+// of obj.
+func (c *fakeClient) hasAdmission(obj runtime.Object) bool {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	return err == nil && c.admissionChains[gvk] != nil
+}
+
+// admit calls the admission plugins registered for the kind of obj for a
+// request with the given operation on the object namespace/name, before it is
+// persisted. The patches of mutating plugins are applied to obj. For Update
+// and Delete, the old object is read from the tracker.
+func (c *fakeClient) admit(ctx context.Context, operation admissionv1.Operation, gvr schema.GroupVersionResource, obj runtime.Object, namespace, name string, options runtime.Object, dryRun bool) error {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	chain := c.admissionChains[gvk]
+	if chain == nil {
+		return nil
+	}
+
+	metaGVK := metav1.GroupVersionKind(gvk)
+	metaGVR := metav1.GroupVersionResource(gvr)
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		UID:             uuid.NewUUID(),
+		Kind:            metaGVK,
+		Resource:        metaGVR,
+		RequestKind:     &metaGVK,
+		RequestResource: &metaGVR,
+		Name:            name,
+		Namespace:       namespace,
+		Operation:       operation,
+		DryRun:          &dryRun,
+	}}
+	if options != nil {
+		optionsKind := metav1.SchemeGroupVersion.WithKind(reflect.Indirect(reflect.ValueOf(options)).Type().Name())
+		if req.Options.Raw, err = marshalWithGVK(options, optionsKind); err != nil {
+			return err
+		}
+	}
+	if operation == admissionv1.Update || operation == admissionv1.Delete {
+		oldObj, err := c.tracker.Get(gvr, namespace, name)
+		if apierrors.IsNotFound(err) && !dryRun {
+			// The request itself fails with the same error.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if req.OldObject.Raw, err = marshalWithGVK(oldObj, gvk); err != nil {
+			return err
+		}
+	}
+	if operation != admissionv1.Delete {
+		if req.Object.Raw, err = marshalWithGVK(obj, gvk); err != nil {
+			return err
+		}
+	}
+
+	mutated := false
+	for _, webhook := range chain.mutating {
+		resp := c.callAdmissionWebhook(ctx, webhook, req)
+		if !resp.Allowed {
+			return admissionDeniedError(webhook.name, resp.Result)
+		}
+		if len(resp.Patch) == 0 || operation == admissionv1.Delete {
+			continue
+		}
+		patch, err := jsonpatch.DecodePatch(resp.Patch)
+		if err != nil {
+			return apierrors.NewInternalError(fmt.Errorf("admission webhook %q returned an invalid patch: %w", webhook.name, err))
+		}
+		if req.Object.Raw, err = patch.Apply(req.Object.Raw); err != nil {
+			return apierrors.NewInternalError(fmt.Errorf("failed to apply the patch of admission webhook %q: %w", webhook.name, err))
+		}
+		mutated = true
+	}
+	for _, webhook := range chain.validating {
+		resp := c.callAdmissionWebhook(ctx, webhook, req)
+		if !resp.Allowed {
+			return admissionDeniedError(webhook.name, resp.Result)
+		}
+	}
+
+	if !mutated {
+		return nil
+	}
+	// Keep the TypeMeta of typed objects as it was, the fake client doesn't
+	// set it on them either.
+	typeMeta := obj.GetObjectKind().GroupVersionKind()
+	zero(obj)
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return err
+	}
+	if _, isUnstructured := obj.(runtime.Unstructured); !isUnstructured {
+		obj.GetObjectKind().SetGroupVersionKind(typeMeta)
+	}
+	return nil
+}
+
+// callAdmissionWebhook calls webhook and passes the warnings it returns to the
+// warning handler of the client.
+func (c *fakeClient) callAdmissionWebhook(ctx context.Context, webhook admissionWebhook, req admission.Request) admission.Response {
+	resp := webhook.handler.Handle(ctx, req)
+	for _, warning := range resp.Warnings {
+		c.warningHandler.HandleWarningHeader(299, "-", warning)
+	}
+	return resp
+}
+
+// admissionDeniedError returns the error the API server returns when an
+// admission webhook denies a request.
+func admissionDeniedError(name string, result *metav1.Status) error {
+	status := metav1.Status{Status: metav1.StatusFailure}
+	if result != nil {
+		status = *result
+	}
+	if status.Code < http.StatusBadRequest {
+		status.Code = http.StatusBadRequest
+	}
+	if status.Status == "" || status.Status == metav1.StatusSuccess {
+		status.Status = metav1.StatusFailure
+	}
+
+	deniedBy := fmt.Sprintf("admission webhook %q denied the request", name)
+	switch {
+	case status.Message != "":
+		status.Message = fmt.Sprintf("%s: %s", deniedBy, status.Message)
+	case status.Reason != "":
+		status.Message = fmt.Sprintf("%s: %s", deniedBy, status.Reason)
+	default:
+		status.Message = deniedBy + " without explanation"
+	}
+	return &apierrors.StatusError{ErrStatus: status}
+}
+
+// marshalWithGVK marshals obj to JSON with its apiVersion and kind set to gvk,
+// which typed objects usually don't have set.
+func marshalWithGVK(obj runtime.Object, gvk schema.GroupVersionKind) ([]byte, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructuredObj := &unstructured.Unstructured{Object: u}
+	unstructuredObj.SetGroupVersionKind(gvk)
+	return json.Marshal(unstructuredObj)
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// configMapWebhook defaults and validates ConfigMaps like a webhook built
+// with the webhook builder.
+type configMapWebhook struct {
+	deletes int
+}
+
+func (w *configMapWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	cm := obj.(*corev1.ConfigMap)
+	if cm.Labels == nil {
+		cm.Labels = map[string]string{}
+	}
+	cm.Labels["defaulted"] = "true"
+	return nil
+}
+
+func (w *configMapWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(obj.(*corev1.ConfigMap))
+}
+
+func (w *configMapWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	if oldObj.(*corev1.ConfigMap).Data["immutable"] != newObj.(*corev1.ConfigMap).Data["immutable"] {
+		return nil, apierrors.NewInvalid(corev1.SchemeGroupVersion.WithKind("ConfigMap").GroupKind(), newObj.(*corev1.ConfigMap).Name, nil)
+	}
+	return w.validate(newObj.(*corev1.ConfigMap))
+}
+
+func (w *configMapWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	w.deletes++
+	if obj.(*corev1.ConfigMap).Labels["protected"] == "true" {
+		return nil, errors.New("configmap is protected")
+	}
+	return nil, nil
+}
+
+func (w *configMapWebhook) validate(cm *corev1.ConfigMap) (admission.Warnings, error) {
+	if cm.Labels["defaulted"] != "true" {
+		return nil, errors.New("configmap was not defaulted")
+	}
+	if cm.Data["forbidden"] != "" {
+		return nil, errors.New("forbidden key is set")
+	}
+	if cm.Data["deprecated"] != "" {
+		return admission.Warnings{"deprecated key is set"}, nil
+	}
+	return nil, nil
+}
+
+type warningRecorder []string
+
+func (r *warningRecorder) HandleWarningHeader(code int, agent string, text string) {
+	*r = append(*r, text)
+}
+
+var _ = Describe("Fake client admission", func() {
+	var (
+		ctx      context.Context
+		webhook  *configMapWebhook
+		warnings *warningRecorder
+		cl       client.WithWatch
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		webhook = &configMapWebhook{}
+		warnings = &warningRecorder{}
+		cl = NewClientBuilder().
+			WithObjects(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default", Labels: map[string]string{"defaulted": "true"}},
+				Data:       map[string]string{"immutable": "a"},
+			}).
+			WithDefaulter(&corev1.ConfigMap{}, webhook).
+			WithValidator(&corev1.ConfigMap{}, webhook).
+			WithWarningHandler(warnings).
+			Build()
+	})
+
+	It("should default and validate creates", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default"}}
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+		Expect(cm.Labels).To(HaveKeyWithValue("defaulted", "true"))
+		Expect(cm.TypeMeta).To(Equal(metav1.TypeMeta{}))
+
+		stored := &corev1.ConfigMap{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), stored)).To(Succeed())
+		Expect(stored.Labels).To(HaveKeyWithValue("defaulted", "true"))
+	})
+
+	It("should return the error of the API server if a validator denies a request", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default"},
+			Data:       map[string]string{"forbidden": "x"},
+		}
+		err := cl.Create(ctx, cm)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(Equal(`admission webhook "validate--v1-configmap" denied the request: forbidden key is set`))
+		Expect(apierrors.IsNotFound(cl.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}))).To(BeTrue())
+	})
+
+	It("should keep the status of errors returned by validators", func() {
+		cm := &corev1.ConfigMap{}
+		Expect(cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "existing"}, cm)).To(Succeed())
+		cm.Data["immutable"] = "b"
+		Expect(apierrors.IsInvalid(cl.Update(ctx, cm))).To(BeTrue())
+	})
+
+	It("should admit patches with the patched object", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}
+		err := cl.Patch(ctx, cm, client.RawPatch(types.MergePatchType, []byte(`{"data":{"immutable":"b"}}`)))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+		Expect(cl.Patch(ctx, cm, client.RawPatch(types.MergePatchType, []byte(`{"metadata":{"labels":null},"data":{"other":"x"}}`)))).To(Succeed())
+		Expect(cm.Labels).To(HaveKeyWithValue("defaulted", "true"))
+		Expect(cm.Data).To(HaveKeyWithValue("other", "x"))
+	})
+
+	It("should admit applies", func() {
+		Expect(cl.Apply(ctx, corev1ac.ConfigMap("applied", "default").WithData(map[string]string{"forbidden": "x"}), client.FieldOwner("test"))).
+			To(MatchError(ContainSubstring("forbidden key is set")))
+
+		cm := corev1ac.ConfigMap("applied", "default")
+		Expect(cl.Apply(ctx, cm, client.FieldOwner("test"))).To(Succeed())
+		Expect(cm.Labels).To(HaveKeyWithValue("defaulted", "true"))
+	})
+
+	It("should validate deletes", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "protected", Namespace: "default", Labels: map[string]string{"protected": "true"}},
+		}
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+
+		Expect(cl.Delete(ctx, cm)).To(MatchError(ContainSubstring("configmap is protected")))
+		Expect(cl.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default"))).To(MatchError(ContainSubstring("configmap is protected")))
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})).To(Succeed())
+		Expect(webhook.deletes).To(BeNumerically(">=", 2))
+	})
+
+	It("should admit dry run requests without persisting them", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default"}}
+		Expect(cl.Create(ctx, cm, client.DryRunAll)).To(Succeed())
+		Expect(cm.Labels).To(HaveKeyWithValue("defaulted", "true"))
+		Expect(apierrors.IsNotFound(cl.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}))).To(BeTrue())
+
+		cm.Data = map[string]string{"forbidden": "x"}
+		Expect(apierrors.IsForbidden(cl.Create(ctx, cm, client.DryRunAll))).To(BeTrue())
+	})
+
+	It("should process dry run requests like the API server", func() {
+		By("setting the generated name on dry run creates")
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{GenerateName: "new-", Namespace: "default"}}
+		Expect(cl.Create(ctx, cm, client.DryRunAll)).To(Succeed())
+		Expect(cm.Name).To(HavePrefix("new-"))
+
+		By("failing dry run deletes of objects that don't exist")
+		missing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "default"}}
+		Expect(apierrors.IsNotFound(cl.Delete(ctx, missing, client.DryRunAll))).To(BeTrue())
+
+		By("failing dry run deletes with preconditions that don't match")
+		existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}
+		bogusRV := "bogus"
+		Expect(apierrors.IsConflict(cl.Delete(ctx, existing, client.DryRunAll, client.Preconditions{ResourceVersion: &bogusRV}))).To(BeTrue())
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(existing), existing)).To(Succeed())
+	})
+
+	It("should pass warnings to the warning handler", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default"},
+			Data:       map[string]string{"deprecated": "x"},
+		}
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+		Expect(*warnings).To(Equal(warningRecorder{"deprecated key is set"}))
+	})
+
+	It("should not admit status updates", func() {
+		cm := &corev1.ConfigMap{}
+		Expect(cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "existing"}, cm)).To(Succeed())
+		cm.Data["forbidden"] = "x"
+		Expect(cl.Status().Update(ctx, cm)).NotTo(MatchError(ContainSubstring("forbidden key is set")))
+	})
+
+	It("should call admission handlers with the request", func() {
+		var requests []admission.Request
+		cl := NewClientBuilder().
+			WithAdmissionHandler(&corev1.ConfigMap{}, admission.HandlerFunc(func(ctx context.Context, req admission.Request) admission.Response {
+				requests = append(requests, req)
+				if req.Operation == admissionv1.Delete {
+					return admission.Errored(http.StatusConflict, errors.New("still in use"))
+				}
+				return admission.Allowed("")
+			})).
+			Build()
+
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+		Expect(cl.Create(ctx, cm)).To(Succeed())
+		err := cl.Delete(ctx, cm, client.DryRunAll)
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0].Operation).To(Equal(admissionv1.Create))
+		Expect(requests[0].Kind).To(Equal(metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}))
+		Expect(requests[0].Resource).To(Equal(metav1.GroupVersionResource{Version: "v1", Resource: "configmaps"}))
+		Expect(requests[0].Namespace).To(Equal("default"))
+		Expect(requests[0].Name).To(Equal("cm"))
+		Expect(*requests[0].DryRun).To(BeFalse())
+		Expect(requests[1].Operation).To(Equal(admissionv1.Delete))
+		Expect(requests[1].Object.Raw).To(BeEmpty())
+		Expect(requests[1].OldObject.Raw).NotTo(BeEmpty())
+		Expect(*requests[1].DryRun).To(BeTrue())
+	})
+})
@@ -0,0 +1,280 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Cache backed by a client", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		cl     client.WithWatch
+	)
+
+	newConfigMap := func(name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": name}},
+			Data:       data,
+		}
+	}
+	newCache := func(opts cache.Options) cache.Cache {
+		c, err := cache.NewFromClient(cl, opts)
+		Expect(err).NotTo(HaveOccurred())
+		return c
+	}
+	start := func(c cache.Cache) cache.Cache {
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(ctx)).To(Succeed())
+		}()
+		Expect(c.WaitForCacheSync(ctx)).To(BeTrue())
+		return c
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		cl = fake.NewClientBuilder().
+			WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)).
+			WithObjects(newConfigMap("initial", nil)).
+			Build()
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should list and watch objects with the client", func() {
+		c := start(newCache(cache.Options{}))
+
+		cm := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "initial"}, cm)).To(Succeed())
+
+		Expect(cl.Create(ctx, newConfigMap("created", map[string]string{"key": "value"}))).To(Succeed())
+		Eventually(func() error {
+			return c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "created"}, cm)
+		}).Should(Succeed())
+		Expect(cm.Data).To(Equal(map[string]string{"key": "value"}))
+
+		cm.Data["key"] = "updated"
+		Expect(cl.Update(ctx, cm)).To(Succeed())
+		Eventually(func() map[string]string {
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+			return cm.Data
+		}).Should(HaveKeyWithValue("key", "updated"))
+
+		Expect(cl.Delete(ctx, cm)).To(Succeed())
+		Eventually(func() []corev1.ConfigMap {
+			list := &corev1.ConfigMapList{}
+			Expect(c.List(ctx, list)).To(Succeed())
+			return list.Items
+		}).Should(HaveLen(1))
+	})
+
+	It("should page through the objects of the cache", func() {
+		var want []string
+		for i := 0; i < 7; i++ {
+			cm := newConfigMap(fmt.Sprintf("cm-%d", i), nil)
+			Expect(cl.Create(ctx, cm)).To(Succeed())
+			want = append(want, cm.Name)
+		}
+		want = append(want, "initial")
+		c := start(newCache(cache.Options{}))
+
+		pages := 0
+		var names []string
+		pager := &client.ListPager{Reader: c, PageSize: 3}
+		Expect(pager.EachPage(ctx, &corev1.ConfigMapList{}, func(page client.ObjectList) error {
+			pages++
+			for _, cm := range page.(*corev1.ConfigMapList).Items {
+				names = append(names, cm.Name)
+			}
+			return nil
+		})).To(Succeed())
+		Expect(pages).To(Equal(3))
+		Expect(names).To(Equal(want))
+	})
+
+	It("should page through the objects of a cache for several namespaces", func() {
+		var want []string
+		for _, namespace := range []string{"default", "other", "unwatched"} {
+			for i := 0; i < 3; i++ {
+				cm := newConfigMap(fmt.Sprintf("cm-%d", i), nil)
+				cm.Namespace = namespace
+				Expect(cl.Create(ctx, cm)).To(Succeed())
+				if namespace != "unwatched" {
+					want = append(want, namespace+"/"+cm.Name)
+				}
+			}
+		}
+		want = append(want[:3], append([]string{"default/initial"}, want[3:]...)...)
+		c := start(newCache(cache.Options{DefaultNamespaces: map[string]cache.Config{"default": {}, "other": {}}}))
+
+		for _, pageSize := range []int64{1, 3, 4, 7, 8} {
+			var keys []string
+			Expect(client.ListItems(ctx, c, &corev1.ConfigMapList{}, func(obj client.Object) error {
+				keys = append(keys, client.ObjectKeyFromObject(obj).String())
+				return nil
+			}, client.Limit(pageSize))).To(Succeed())
+			Expect(keys).To(Equal(want), "page size %d", pageSize)
+		}
+	})
+
+	It("should call event handlers and maintain indexes", func() {
+		c := newCache(cache.Options{})
+		Expect(c.IndexField(ctx, &corev1.ConfigMap{}, "data.key", func(obj client.Object) []string {
+			return []string{obj.(*corev1.ConfigMap).Data["key"]}
+		})).To(Succeed())
+		start(c)
+
+		informer, err := c.GetInformer(ctx, &corev1.ConfigMap{})
+		Expect(err).NotTo(HaveOccurred())
+		added := make(chan string, 10)
+		_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) { added <- obj.(client.Object).GetName() },
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(added).Should(Receive(Equal("initial")))
+
+		Expect(cl.Create(ctx, newConfigMap("indexed", map[string]string{"key": "value"}))).To(Succeed())
+		Eventually(added).Should(Receive(Equal("indexed")))
+
+		list := &corev1.ConfigMapList{}
+		Expect(c.List(ctx, list, client.MatchingFields{"data.key": "value"})).To(Succeed())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Name).To(Equal("indexed"))
+	})
+
+	It("should support unstructured and metadata informers", func() {
+		c := start(newCache(cache.Options{}))
+
+		u := &unstructured.UnstructuredList{}
+		u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMapList"))
+		Expect(c.List(ctx, u)).To(Succeed())
+		Expect(u.Items).To(HaveLen(1))
+		Expect(u.Items[0].GetKind()).To(Equal("ConfigMap"))
+
+		m := &metav1.PartialObjectMetadataList{}
+		m.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMapList"))
+		Expect(c.List(ctx, m)).To(Succeed())
+		Expect(m.Items).To(HaveLen(1))
+
+		Expect(cl.Create(ctx, newConfigMap("created", nil))).To(Succeed())
+		Eventually(func() int {
+			Expect(c.List(ctx, u)).To(Succeed())
+			return len(u.Items)
+		}).Should(Equal(2))
+		Eventually(func() int {
+			Expect(c.List(ctx, m)).To(Succeed())
+			return len(m.Items)
+		}).Should(Equal(2))
+	})
+
+	It("should only cache objects matching the label selector", func() {
+		c := start(newCache(cache.Options{
+			DefaultLabelSelector: labels.SelectorFromSet(labels.Set{"app": "selected"}),
+		}))
+
+		Expect(cl.Create(ctx, newConfigMap("selected", nil))).To(Succeed())
+		list := &corev1.ConfigMapList{}
+		Eventually(func() []corev1.ConfigMap {
+			Expect(c.List(ctx, list)).To(Succeed())
+			return list.Items
+		}).Should(HaveLen(1))
+		Expect(list.Items[0].Name).To(Equal("selected"))
+
+		selected := &list.Items[0]
+		selected.Labels = nil
+		Expect(cl.Update(ctx, selected)).To(Succeed())
+		Eventually(func() []corev1.ConfigMap {
+			Expect(c.List(ctx, list)).To(Succeed())
+			return list.Items
+		}).Should(BeEmpty())
+	})
+
+	It("should run controllers of a manager end to end", func() {
+		mgr, err := manager.New(&rest.Config{}, manager.Options{
+			Scheme:  cl.Scheme(),
+			Metrics: metricsserver.Options{BindAddress: "0"},
+			MapperProvider: func(*rest.Config, *http.Client) (meta.RESTMapper, error) {
+				return cl.RESTMapper(), nil
+			},
+			NewCache: func(_ *rest.Config, opts cache.Options) (cache.Cache, error) {
+				return cache.NewFromClient(cl, opts)
+			},
+			NewClient: func(*rest.Config, client.Options) (client.Client, error) {
+				return cl, nil
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = builder.ControllerManagedBy(mgr).
+			For(&corev1.ConfigMap{}).
+			Complete(reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				cm := &corev1.ConfigMap{}
+				if err := mgr.GetClient().Get(ctx, req.NamespacedName, cm); err != nil {
+					return reconcile.Result{}, client.IgnoreNotFound(err)
+				}
+				if cm.Data["reconciled"] == "true" {
+					return reconcile.Result{}, nil
+				}
+				if cm.Data == nil {
+					cm.Data = map[string]string{}
+				}
+				cm.Data["reconciled"] = "true"
+				return reconcile.Result{}, mgr.GetClient().Update(ctx, cm)
+			}))
+		Expect(err).NotTo(HaveOccurred())
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(ctx)).To(Succeed())
+		}()
+
+		Expect(cl.Create(ctx, newConfigMap("created", nil))).To(Succeed())
+		for _, name := range []string{"initial", "created"} {
+			cm := &corev1.ConfigMap{}
+			Eventually(func() map[string]string {
+				Expect(cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, cm)).To(Succeed())
+				return cm.Data
+			}).Should(HaveKeyWithValue("reconciled", "true"))
+		}
+	})
+})
@@ -17,20 +17,22 @@ limitations under the License.
 package fake
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"reflect"
-	"runtime/debug"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	// Using v4 to match upstream
 	jsonpatch "github.com/evanphx/json-patch"
+	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
@@ -43,25 +45,39 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/managedfields"
 	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/testing"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/internal/applyconfiguration"
 	"sigs.k8s.io/controller-runtime/pkg/internal/field/selector"
 	"sigs.k8s.io/controller-runtime/pkg/internal/objectutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type versionedTracker struct {
 	testing.ObjectTracker
 	scheme                *runtime.Scheme
 	withStatusSubresource sets.Set[schema.GroupVersionKind]
+	typeConverter         managedfields.TypeConverter
+	returnManagedFields   bool
+	kinds                 *trackedKinds
+	schemas               ObjectSchemas
+
+	// garbage is set by writes that might leave garbage to collect, see
+	// markGarbage.
+	garbage *atomic.Bool
 }
 
 type fakeClient struct {
@@ -69,12 +85,18 @@ type fakeClient struct {
 	scheme                *runtime.Scheme
 	restMapper            meta.RESTMapper
 	withStatusSubresource sets.Set[schema.GroupVersionKind]
+	streamFunc            StreamFunc
+	admissionChains       map[schema.GroupVersionKind]*admissionChain
+	warningHandler        rest.WarningHandler
+	garbageCollection     bool
+	continueTokenExpiry   func(gvk schema.GroupVersionKind) bool
 
 	// indexes maps each GroupVersionKind (GVK) to the indexes registered for that GVK.
 	// The inner map maps from index name to IndexerFunc.
 	indexes map[schema.GroupVersionKind]map[string]client.IndexerFunc
 
-	schemeWriteLock sync.Mutex
+	schemeWriteLock       sync.Mutex
+	garbageCollectionLock sync.Mutex
 }
 
 var _ client.WithWatch = &fakeClient{}
@@ -85,6 +107,10 @@ const (
 	maxGeneratedNameLength = maxNameLength - randomLength
 )
 
+// defaultFieldManager is the field manager that changes are attributed to when
+// a request doesn't set one. Like the apiserver, it is derived from the user agent.
+var defaultFieldManager = strings.Split(rest.DefaultKubernetesUserAgent(), "/")[0]
+
 // NewFakeClient creates a new fake client for testing.
 // You can choose to initialize it with a slice of runtime.Object.
 func NewFakeClient(initObjs ...runtime.Object) client.WithWatch {
@@ -106,6 +132,14 @@ type ClientBuilder struct {
 	withStatusSubresource []client.Object
 	objectTracker         testing.ObjectTracker
 	interceptorFuncs      *interceptor.Funcs
+	returnManagedFields   bool
+	streamFunc            StreamFunc
+	admissionPlugins      []admissionRegistration
+	warningHandler        rest.WarningHandler
+	garbageCollection     bool
+	actionRecorder        *ActionRecorder
+	continueTokenExpiry   func(gvk schema.GroupVersionKind) bool
+	objectSchemas         ObjectSchemas
 
 	// indexes maps each GroupVersionKind (GVK) to the indexes registered for that GVK.
 	// The inner map maps from index name to IndexerFunc.
@@ -203,6 +237,27 @@ func (f *ClientBuilder) WithInterceptorFuncs(interceptorFuncs interceptor.Funcs)
 	return f
 }
 
+// StreamFunc returns the content of a streaming subresource of obj, such as
+// the logs of a pod. obj is the object as stored in the fake client.
+type StreamFunc func(ctx context.Context, obj client.Object, subResource string, opts *client.SubResourceStreamOptions) (io.ReadCloser, error)
+
+// WithStreamFunc sets the function that serves the streams opened with
+// SubResource(...).Stream. The streamed object must exist in the fake client.
+// If no function is set, all streams are empty.
+func (f *ClientBuilder) WithStreamFunc(streamFunc StreamFunc) *ClientBuilder {
+	f.streamFunc = streamFunc
+	return f
+}
+
+// WithReturnManagedFields configures the fake client to return the managedFields of
+// objects. The managedFields are always tracked to implement server-side apply, but
+// they are stripped from the objects returned by the client unless this is set,
+// which keeps tests that compare objects free of them.
+func (f *ClientBuilder) WithReturnManagedFields() *ClientBuilder {
+	f.returnManagedFields = true
+	return f
+}
+
 // Build builds and returns a new fake client.
 func (f *ClientBuilder) Build() client.WithWatch {
 	if f.scheme == nil {
@@ -210,11 +265,17 @@ func (f *ClientBuilder) Build() client.WithWatch {
 	}
 	if f.restMapper == nil {
 		f.restMapper = meta.NewDefaultRESTMapper([]schema.GroupVersion{})
+		if f.objectSchemas != nil {
+			f.restMapper = f.objectSchemas.RESTMapper()
+		}
 	}
 
 	var tracker versionedTracker
 
 	withStatusSubResource := sets.New(inTreeResourcesWithStatus()...)
+	if f.objectSchemas != nil {
+		withStatusSubResource.Insert(f.objectSchemas.StatusSubresources()...)
+	}
 	for _, o := range f.withStatusSubresource {
 		gvk, err := apiutil.GVKForObject(o, f.scheme)
 		if err != nil {
@@ -223,10 +284,18 @@ func (f *ClientBuilder) Build() client.WithWatch {
 		withStatusSubResource.Insert(gvk)
 	}
 
-	if f.objectTracker == nil {
-		tracker = versionedTracker{ObjectTracker: testing.NewObjectTracker(f.scheme, scheme.Codecs.UniversalDecoder()), scheme: f.scheme, withStatusSubresource: withStatusSubResource}
-	} else {
-		tracker = versionedTracker{ObjectTracker: f.objectTracker, scheme: f.scheme, withStatusSubresource: withStatusSubResource}
+	tracker = versionedTracker{
+		ObjectTracker:         f.objectTracker,
+		scheme:                f.scheme,
+		withStatusSubresource: withStatusSubResource,
+		typeConverter:         newSchemeTypeConverter(f.scheme),
+		returnManagedFields:   f.returnManagedFields,
+		kinds:                 newTrackedKinds(),
+		garbage:               &atomic.Bool{},
+		schemas:               f.objectSchemas,
+	}
+	if tracker.ObjectTracker == nil {
+		tracker.ObjectTracker = testing.NewObjectTracker(f.scheme, scheme.Codecs.UniversalDecoder())
 	}
 
 	for _, obj := range f.initObject {
@@ -245,17 +314,37 @@ func (f *ClientBuilder) Build() client.WithWatch {
 		}
 	}
 
-	var result client.WithWatch = &fakeClient{
+	warningHandler := f.warningHandler
+	if warningHandler == nil {
+		warningHandler = log.NewKubeAPIWarningLogger(
+			log.Log.WithName("KubeAPIWarningLogger"),
+			log.KubeAPIWarningLoggerOptions{Deduplicate: true},
+		)
+	}
+
+	c := &fakeClient{
 		tracker:               tracker,
 		scheme:                f.scheme,
 		restMapper:            f.restMapper,
 		indexes:               f.indexes,
 		withStatusSubresource: withStatusSubResource,
+		streamFunc:            f.streamFunc,
+		admissionChains:       buildAdmissionChains(f.scheme, f.admissionPlugins),
+		warningHandler:        warningHandler,
+		garbageCollection:     f.garbageCollection,
+		continueTokenExpiry:   f.continueTokenExpiry,
 	}
 
+	var result client.WithWatch = c
 	if f.interceptorFuncs != nil {
 		result = interceptor.NewClient(result, *f.interceptorFuncs)
 	}
+	if f.actionRecorder != nil {
+		result = newRecordingClient(result, f.actionRecorder)
+	}
+	if result != client.WithWatch(c) {
+		result = &snapshotClient{WithWatch: result, Snapshotter: c}
+	}
 
 	return result
 }
@@ -289,6 +378,11 @@ func (t versionedTracker) Add(obj runtime.Object) error {
 			accessor.SetResourceVersion(trackerAddResourceVersion)
 		}
 
+		// Like the API server does when it reads objects from etcd, initial objects
+		// are defaulted but not validated.
+		if err := t.applySchema(obj, nil, false); err != nil {
+			return err
+		}
 		obj, err = convertFromUnstructuredIfNecessary(t.scheme, obj)
 		if err != nil {
 			return err
@@ -296,12 +390,21 @@ func (t versionedTracker) Add(obj runtime.Object) error {
 		if err := t.ObjectTracker.Add(obj); err != nil {
 			return err
 		}
+		t.recordKind(obj)
+		t.markGarbage(nil, accessor)
 	}
 
 	return nil
 }
 
 func (t versionedTracker) Create(gvr schema.GroupVersionResource, obj runtime.Object, ns string) error {
+	return t.create(gvr, obj, ns, defaultFieldManager)
+}
+
+// create adds obj to the tracker and attributes its fields to fieldManager. If fieldManager
+// is empty, the managedFields of obj are stored as they are. This is used for apply requests
+// which compute the managedFields upfront.
+func (t versionedTracker) create(gvr schema.GroupVersionResource, obj runtime.Object, ns string, fieldManager string) error {
 	accessor, err := meta.Accessor(obj)
 	if err != nil {
 		return fmt.Errorf("failed to get accessor for object: %w", err)
@@ -315,15 +418,36 @@ func (t versionedTracker) Create(gvr schema.GroupVersionResource, obj runtime.Ob
 	if accessor.GetResourceVersion() != "" {
 		return apierrors.NewBadRequest("resourceVersion can not be set for Create requests")
 	}
+	if err := t.applySchema(obj, nil, true); err != nil {
+		return err
+	}
+	managedFields := accessor.GetManagedFields()
+	if fieldManager != "" {
+		gvk, err := apiutil.GVKForObject(obj, t.scheme)
+		if err != nil {
+			return err
+		}
+		if err := t.updateManagedFields(gvk, nil, obj, false, fieldManager); err != nil {
+			return err
+		}
+	}
 	accessor.SetResourceVersion("1")
 	obj, err = convertFromUnstructuredIfNecessary(t.scheme, obj)
 	if err != nil {
+		accessor.SetResourceVersion("")
+		accessor.SetManagedFields(managedFields)
 		return err
 	}
 	if err := t.ObjectTracker.Create(gvr, obj, ns); err != nil {
 		accessor.SetResourceVersion("")
+		accessor.SetManagedFields(managedFields)
 		return err
 	}
+	t.recordKind(obj)
+	t.markGarbage(nil, accessor)
+	if !t.returnManagedFields {
+		accessor.SetManagedFields(nil)
+	}
 
 	return nil
 }
@@ -358,16 +482,12 @@ func convertFromUnstructuredIfNecessary(s *runtime.Scheme, o runtime.Object) (ru
 }
 
 func (t versionedTracker) Update(gvr schema.GroupVersionResource, obj runtime.Object, ns string) error {
-	isStatus := false
-	// We apply patches using a client-go reaction that ends up calling the trackers Update. As we can't change
-	// that reaction, we use the callstack to figure out if this originated from the status client.
-	if bytes.Contains(debug.Stack(), []byte("sigs.k8s.io/controller-runtime/pkg/client/fake.(*fakeSubResourceClient).Patch")) {
-		isStatus = true
-	}
-	return t.update(gvr, obj, ns, isStatus, false)
+	return t.update(gvr, obj, ns, false, false, defaultFieldManager)
 }
 
-func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Object, ns string, isStatus bool, deleting bool) error {
+// update updates obj in the tracker and attributes the changed fields to fieldManager. As
+// for create, an empty fieldManager means that the managedFields of obj are stored as they are.
+func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Object, ns string, isStatus bool, deleting bool, fieldManager string) error {
 	accessor, err := meta.Accessor(obj)
 	if err != nil {
 		return fmt.Errorf("failed to get accessor for object: %w", err)
@@ -393,16 +513,20 @@ func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Ob
 		// If the resource is not found and the resource allows create on update, issue a
 		// create instead.
 		if apierrors.IsNotFound(err) && allowsCreateOnUpdate(gvk) {
-			return t.Create(gvr, obj, ns)
+			return t.create(gvr, obj, ns, fieldManager)
 		}
 		return err
 	}
 
 	if t.withStatusSubresource.Has(gvk) {
 		if isStatus { // copy everything but status and metadata.ResourceVersion from original object
+			managedFields := accessor.GetManagedFields()
 			if err := copyNonStatusFrom(oldObject, obj); err != nil {
 				return fmt.Errorf("failed to copy non-status field for object with status subresouce: %w", err)
 			}
+			if fieldManager == "" {
+				accessor.SetManagedFields(managedFields)
+			}
 		} else { // copy status from original object
 			if err := copyStatusFrom(oldObject, obj); err != nil {
 				return fmt.Errorf("failed to copy the status for object with status subresource: %w", err)
@@ -425,6 +549,9 @@ func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Ob
 	if accessor.GetResourceVersion() != oldAccessor.GetResourceVersion() {
 		return apierrors.NewConflict(gvr.GroupResource(), accessor.GetName(), errors.New("object was modified"))
 	}
+	if err := t.applySchema(obj, oldObject, true); err != nil {
+		return err
+	}
 	if oldAccessor.GetResourceVersion() == "" {
 		oldAccessor.SetResourceVersion("0")
 	}
@@ -440,13 +567,105 @@ func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Ob
 	}
 
 	if !accessor.GetDeletionTimestamp().IsZero() && len(accessor.GetFinalizers()) == 0 {
-		return t.ObjectTracker.Delete(gvr, accessor.GetNamespace(), accessor.GetName())
+		if err := t.ObjectTracker.Delete(gvr, accessor.GetNamespace(), accessor.GetName()); err != nil {
+			return err
+		}
+		t.garbage.Store(true)
+		return nil
+	}
+	if fieldManager != "" {
+		if err := t.updateManagedFields(gvk, oldObject, obj, isStatus, fieldManager); err != nil {
+			return err
+		}
 	}
 	obj, err = convertFromUnstructuredIfNecessary(t.scheme, obj)
 	if err != nil {
 		return err
 	}
-	return t.ObjectTracker.Update(gvr, obj, ns)
+	if err := t.ObjectTracker.Update(gvr, obj, ns); err != nil {
+		return err
+	}
+	t.markGarbage(oldAccessor, accessor)
+	if !t.returnManagedFields {
+		accessor.SetManagedFields(nil)
+	}
+	return nil
+}
+
+// fieldManager returns the field manager for objects of the given kind. Writes to the
+// main resource of kinds with a status subresource don't own status fields and vice versa.
+func (t versionedTracker) fieldManager(gvk schema.GroupVersionKind, isStatus bool) (*managedfields.FieldManager, error) {
+	var subresource string
+	var resetFields map[fieldpath.APIVersion]*fieldpath.Set
+	switch {
+	case isStatus:
+		subresource = "status"
+		resetFields = map[fieldpath.APIVersion]*fieldpath.Set{
+			fieldpath.APIVersion(gvk.GroupVersion().String()): fieldpath.NewSet(fieldpath.MakePathOrDie("spec")),
+		}
+	case t.withStatusSubresource.Has(gvk):
+		resetFields = map[fieldpath.APIVersion]*fieldpath.Set{
+			fieldpath.APIVersion(gvk.GroupVersion().String()): fieldpath.NewSet(fieldpath.MakePathOrDie("status")),
+		}
+	}
+
+	converter := unstructuredObjectConverter{}
+	return managedfields.NewDefaultFieldManager(t.typeConverter, converter, converter, converter, gvk, gvk.GroupVersion(), subresource, resetFields)
+}
+
+// updateManagedFields records the changes from oldObj to obj in the managedFields of obj and
+// attributes them to fieldManager. oldObj is nil for creates. Like the apiserver, the write is
+// not rejected if the managedFields can't be computed, they are dropped instead.
+func (t versionedTracker) updateManagedFields(gvk schema.GroupVersionKind, oldObj, obj runtime.Object, isStatus bool, fieldManager string) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	fm, err := t.fieldManager(gvk, isStatus)
+	if err != nil {
+		return err
+	}
+
+	liveObj, err := unstructuredObjectConverter{}.New(gvk)
+	if err != nil {
+		return err
+	}
+	if oldObj != nil {
+		if liveObj, err = toUnstructuredWithGVK(oldObj, gvk); err != nil {
+			return err
+		}
+	}
+	newObj, err := toUnstructuredWithGVK(obj, gvk)
+	if err != nil {
+		return err
+	}
+
+	var managedFields []metav1.ManagedFieldsEntry
+	if result, err := fm.Update(liveObj, newObj, fieldManager); err == nil {
+		resultAccessor, err := meta.Accessor(result)
+		if err != nil {
+			return err
+		}
+		managedFields = resultAccessor.GetManagedFields()
+	}
+	accessor.SetManagedFields(managedFields)
+	return nil
+}
+
+// toUnstructuredWithGVK returns a copy of obj as *unstructured.Unstructured with the given GVK set.
+func toUnstructuredWithGVK(obj runtime.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	u := &unstructured.Unstructured{}
+	if in, isUnstructured := obj.(*unstructured.Unstructured); isUnstructured {
+		u = in.DeepCopy()
+	} else {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		u.Object = content
+	}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
 }
 
 func (c *fakeClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
@@ -458,6 +677,9 @@ func (c *fakeClient) Get(ctx context.Context, key client.ObjectKey, obj client.O
 	if err != nil {
 		return err
 	}
+	if err := c.stripManagedFieldsIfNecessary(o); err != nil {
+		return err
+	}
 
 	gvk, err := apiutil.GVKForObject(obj, c.scheme)
 	if err != nil {
@@ -492,7 +714,87 @@ func (c *fakeClient) Watch(ctx context.Context, list client.ObjectList, opts ...
 	listOpts.ApplyOptions(opts)
 
 	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
-	return c.tracker.Watch(gvr, listOpts.Namespace)
+	w, err := c.tracker.Watch(gvr, listOpts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if listOpts.LabelSelector != nil || listOpts.FieldSelector != nil {
+		// Reject the selectors List would reject.
+		if _, err := c.filterList(nil, gvk, listOpts.LabelSelector, listOpts.FieldSelector); err != nil {
+			w.Stop()
+			return nil, err
+		}
+		// Watchers usually list before they watch, so they know the objects that
+		// match when the watch starts.
+		c.registerUnstructuredList(list, gvk)
+		o, err := c.tracker.List(gvr, gvk, listOpts.Namespace)
+		if err != nil {
+			w.Stop()
+			return nil, err
+		}
+		objs, err := meta.ExtractList(o)
+		if err != nil {
+			w.Stop()
+			return nil, err
+		}
+		matching, err := c.filterList(objs, gvk, listOpts.LabelSelector, listOpts.FieldSelector)
+		if err != nil {
+			w.Stop()
+			return nil, err
+		}
+		// The keys of the objects the watcher knows. The filter is only called by
+		// the goroutine of the watch, so this doesn't need a lock.
+		known := sets.New[client.ObjectKey]()
+		for _, obj := range matching {
+			if accessor, err := meta.Accessor(obj); err == nil {
+				known.Insert(client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()})
+			}
+		}
+
+		w = watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+			if in.Type != watch.Added && in.Type != watch.Modified && in.Type != watch.Deleted {
+				return in, true
+			}
+			accessor, err := meta.Accessor(in.Object)
+			if err != nil {
+				return in, true
+			}
+			key := client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+			matching, err := c.filterList([]runtime.Object{in.Object}, gvk, listOpts.LabelSelector, listOpts.FieldSelector)
+			if err != nil || len(matching) > 0 {
+				if in.Type == watch.Deleted {
+					known.Delete(key)
+				} else {
+					known.Insert(key)
+				}
+				return in, true
+			}
+			// Like the API server, tell watchers that an object they know doesn't
+			// match the selectors anymore and is gone for them.
+			if known.Has(key) {
+				known.Delete(key)
+				in.Type = watch.Deleted
+				return in, true
+			}
+			return in, false
+		})
+	}
+
+	if _, isMetadataList := list.(*metav1.PartialObjectMetadataList); isMetadataList {
+		// Like the metadata client, only return the metadata of the objects.
+		return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+			accessor, err := meta.Accessor(in.Object)
+			if err != nil {
+				return in, true
+			}
+			m := meta.AsPartialObjectMetadata(accessor).DeepCopy()
+			m.SetGroupVersionKind(gvk)
+			in.Object = m
+			return in, true
+		}), nil
+	}
+	return w, nil
 }
 
 func (c *fakeClient) List(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
@@ -504,14 +806,7 @@ func (c *fakeClient) List(ctx context.Context, obj client.ObjectList, opts ...cl
 	originalKind := gvk.Kind
 
 	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
-
-	if _, isUnstructuredList := obj.(runtime.Unstructured); isUnstructuredList && !c.scheme.Recognizes(gvk) {
-		// We need to register the ListKind with UnstructuredList:
-		// https://github.com/kubernetes/kubernetes/blob/7b2776b89fb1be28d4e9203bdeec079be903c103/staging/src/k8s.io/client-go/dynamic/fake/simple.go#L44-L51
-		c.schemeWriteLock.Lock()
-		c.scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
-		c.schemeWriteLock.Unlock()
-	}
+	c.registerUnstructuredList(obj, gvk)
 
 	listOpts := client.ListOptions{}
 	listOpts.ApplyOptions(opts)
@@ -521,6 +816,9 @@ func (c *fakeClient) List(ctx context.Context, obj client.ObjectList, opts ...cl
 	if err != nil {
 		return err
 	}
+	if err := meta.EachListItem(o, c.stripManagedFieldsIfNecessary); err != nil {
+		return err
+	}
 
 	ta, err := meta.TypeAccessor(o)
 	if err != nil {
@@ -540,23 +838,52 @@ func (c *fakeClient) List(ctx context.Context, obj client.ObjectList, opts ...cl
 		return err
 	}
 
-	if listOpts.LabelSelector == nil && listOpts.FieldSelector == nil {
-		return nil
+	filtered := listOpts.LabelSelector != nil || listOpts.FieldSelector != nil
+	if filtered {
+		// Either a label or field selector are specified (or both), so before we return
+		// the list we must filter it. If both selectors are set, they are ANDed.
+		objs, err := meta.ExtractList(obj)
+		if err != nil {
+			return err
+		}
+
+		filteredList, err := c.filterList(objs, gvk, listOpts.LabelSelector, listOpts.FieldSelector)
+		if err != nil {
+			return err
+		}
+
+		if err := meta.SetList(obj, filteredList); err != nil {
+			return err
+		}
 	}
 
-	// If we're here, either a label or field selector are specified (or both), so before we return
-	// the list we must filter it. If both selectors are set, they are ANDed.
-	objs, err := meta.ExtractList(obj)
-	if err != nil {
-		return err
+	return c.paginate(gvk, obj, listOpts.Limit, listOpts.Continue, filtered)
+}
+
+// registerUnstructuredList registers the list kind of gvk for unstructured lists of kinds
+// that are not in the scheme, so the tracker can list them.
+func (c *fakeClient) registerUnstructuredList(list client.ObjectList, gvk schema.GroupVersionKind) {
+	if _, isUnstructuredList := list.(runtime.Unstructured); isUnstructuredList && !c.scheme.Recognizes(gvk) {
+		// We need to register the ListKind with UnstructuredList:
+		// https://github.com/kubernetes/kubernetes/blob/7b2776b89fb1be28d4e9203bdeec079be903c103/staging/src/k8s.io/client-go/dynamic/fake/simple.go#L44-L51
+		c.schemeWriteLock.Lock()
+		c.scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+		c.schemeWriteLock.Unlock()
 	}
+}
 
-	filteredList, err := c.filterList(objs, gvk, listOpts.LabelSelector, listOpts.FieldSelector)
+// stripManagedFieldsIfNecessary removes the managedFields from an object that is returned
+// to the caller, unless the client was configured to return them.
+func (c *fakeClient) stripManagedFieldsIfNecessary(obj runtime.Object) error {
+	if c.tracker.returnManagedFields {
+		return nil
+	}
+	accessor, err := meta.Accessor(obj)
 	if err != nil {
 		return err
 	}
-
-	return meta.SetList(obj, filteredList)
+	accessor.SetManagedFields(nil)
+	return nil
 }
 
 func (c *fakeClient) filterList(list []runtime.Object, gvk schema.GroupVersionKind, ls labels.Selector, fs fields.Selector) ([]runtime.Object, error) {
@@ -646,10 +973,10 @@ func (c *fakeClient) Create(ctx context.Context, obj client.Object, opts ...clie
 	createOptions := &client.CreateOptions{}
 	createOptions.ApplyOptions(opts)
 
-	for _, dryRunOpt := range createOptions.DryRun {
-		if dryRunOpt == metav1.DryRunAll {
-			return nil
-		}
+	// Dry-run requests are only processed if they are admitted, see WithDefaulter.
+	dryRun := isDryRun(createOptions.DryRun)
+	if dryRun && !c.hasAdmission(obj) {
+		return nil
 	}
 
 	gvr, err := getGVRFromObject(obj, c.scheme)
@@ -673,7 +1000,18 @@ func (c *fakeClient) Create(ctx context.Context, obj client.Object, opts ...clie
 		accessor.SetDeletionTimestamp(nil)
 	}
 
-	return c.tracker.Create(gvr, obj, accessor.GetNamespace())
+	if err := c.admit(ctx, admissionv1.Create, gvr, obj, accessor.GetNamespace(), accessor.GetName(), createOptions.AsCreateOptions(), dryRun); err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err := c.tracker.create(gvr, obj, accessor.GetNamespace(), fieldManagerOrDefault(createOptions.FieldManager)); err != nil {
+		return err
+	}
+	c.collectGarbage(ctx)
+	return nil
 }
 
 func (c *fakeClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
@@ -688,10 +1026,10 @@ func (c *fakeClient) Delete(ctx context.Context, obj client.Object, opts ...clie
 	delOptions := client.DeleteOptions{}
 	delOptions.ApplyOptions(opts)
 
-	for _, dryRunOpt := range delOptions.DryRun {
-		if dryRunOpt == metav1.DryRunAll {
-			return nil
-		}
+	// Dry-run requests are only processed if they are admitted, see WithDefaulter.
+	dryRun := isDryRun(delOptions.DryRun)
+	if dryRun && !c.hasAdmission(obj) {
+		return nil
 	}
 
 	// Check the ResourceVersion if that Precondition was specified.
@@ -716,7 +1054,18 @@ func (c *fakeClient) Delete(ctx context.Context, obj client.Object, opts ...clie
 		}
 	}
 
-	return c.deleteObject(gvr, accessor)
+	if err := c.admit(ctx, admissionv1.Delete, gvr, obj, accessor.GetNamespace(), accessor.GetName(), delOptions.AsDeleteOptions(), dryRun); err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err := c.deleteObject(gvr, accessor, &delOptions); err != nil {
+		return err
+	}
+	c.collectGarbage(ctx)
+	return nil
 }
 
 func (c *fakeClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
@@ -727,12 +1076,7 @@ func (c *fakeClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ..
 
 	dcOptions := client.DeleteAllOfOptions{}
 	dcOptions.ApplyOptions(opts)
-
-	for _, dryRunOpt := range dcOptions.DryRun {
-		if dryRunOpt == metav1.DryRunAll {
-			return nil
-		}
-	}
+	dryRun := isDryRun(dcOptions.DryRun)
 
 	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
 	o, err := c.tracker.List(gvr, gvk, dcOptions.Namespace)
@@ -753,27 +1097,29 @@ func (c *fakeClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ..
 		if err != nil {
 			return err
 		}
-		err = c.deleteObject(gvr, accessor)
+		if err := c.admit(ctx, admissionv1.Delete, gvr, obj, accessor.GetNamespace(), accessor.GetName(), dcOptions.AsDeleteOptions(), dryRun); err != nil {
+			return err
+		}
+		if dryRun {
+			continue
+		}
+		err = c.deleteObject(gvr, accessor, &dcOptions.DeleteOptions)
 		if err != nil {
 			return err
 		}
 	}
+	c.collectGarbage(ctx)
 	return nil
 }
 
 func (c *fakeClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
-	return c.update(obj, false, opts...)
+	return c.update(ctx, obj, false, opts...)
 }
 
-func (c *fakeClient) update(obj client.Object, isStatus bool, opts ...client.UpdateOption) error {
+func (c *fakeClient) update(ctx context.Context, obj client.Object, isStatus bool, opts ...client.UpdateOption) error {
 	updateOptions := &client.UpdateOptions{}
 	updateOptions.ApplyOptions(opts)
-
-	for _, dryRunOpt := range updateOptions.DryRun {
-		if dryRunOpt == metav1.DryRunAll {
-			return nil
-		}
-	}
+	dryRun := isDryRun(updateOptions.DryRun)
 
 	gvr, err := getGVRFromObject(obj, c.scheme)
 	if err != nil {
@@ -783,21 +1129,36 @@ func (c *fakeClient) update(obj client.Object, isStatus bool, opts ...client.Upd
 	if err != nil {
 		return err
 	}
-	return c.tracker.update(gvr, obj, accessor.GetNamespace(), isStatus, false)
+	if !isStatus {
+		if err := c.admit(ctx, admissionv1.Update, gvr, obj, accessor.GetNamespace(), accessor.GetName(), updateOptions.AsUpdateOptions(), dryRun); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		return nil
+	}
+	if err := c.tracker.update(gvr, obj, accessor.GetNamespace(), isStatus, false, fieldManagerOrDefault(updateOptions.FieldManager)); err != nil {
+		return err
+	}
+	c.collectGarbage(ctx)
+	return nil
 }
 
 func (c *fakeClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
-	return c.patch(obj, patch, opts...)
+	return c.patch(ctx, obj, patch, false, opts...)
 }
 
-func (c *fakeClient) patch(obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+func (c *fakeClient) patch(ctx context.Context, obj client.Object, patch client.Patch, isStatus bool, opts ...client.PatchOption) error {
 	patchOptions := &client.PatchOptions{}
 	patchOptions.ApplyOptions(opts)
 
-	for _, dryRunOpt := range patchOptions.DryRun {
-		if dryRunOpt == metav1.DryRunAll {
-			return nil
-		}
+	if patch.Type() == types.ApplyPatchType {
+		return c.applyPatch(ctx, obj, patch, isStatus, patchOptions)
+	}
+
+	dryRun := isDryRun(patchOptions.DryRun)
+	if dryRun && (isStatus || !c.hasAdmission(obj)) {
+		return nil
 	}
 
 	gvr, err := getGVRFromObject(obj, c.scheme)
@@ -830,8 +1191,7 @@ func (c *fakeClient) patch(obj client.Object, patch client.Patch, opts ...client
 	// Apply patch without updating object.
 	// To remain in accordance with the behavior of k8s api behavior,
 	// a patch must not allow for changes to the deletionTimestamp of an object.
-	// The reaction() function applies the patch to the object and calls Update(),
-	// whereas dryPatch() replicates this behavior but skips the call to Update().
+	// dryPatch() applies the patch to the object but skips the call to Update().
 	// This ensures that the patch may be rejected if a deletionTimestamp is modified, prior
 	// to updating the object.
 	action := testing.NewPatchAction(gvr, accessor.GetNamespace(), accessor.GetName(), patch.Type(), data)
@@ -849,13 +1209,17 @@ func (c *fakeClient) patch(obj client.Object, patch client.Patch, opts ...client
 		return fmt.Errorf("rejected patch, metadata.deletionTimestamp immutable")
 	}
 
-	reaction := testing.ObjectReaction(c.tracker)
-	handled, o, err := reaction(action)
-	if err != nil {
-		return err
+	if !isStatus {
+		if err := c.admit(ctx, admissionv1.Update, gvr, o, accessor.GetNamespace(), accessor.GetName(), patchOptions.AsPatchOptions(), dryRun); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		return nil
 	}
-	if !handled {
-		panic("tracker could not handle patch method")
+
+	if err := c.tracker.update(gvr, o, accessor.GetNamespace(), isStatus, false, fieldManagerOrDefault(patchOptions.FieldManager)); err != nil {
+		return err
 	}
 	ta, err := meta.TypeAccessor(o)
 	if err != nil {
@@ -870,10 +1234,171 @@ func (c *fakeClient) patch(obj client.Object, patch client.Patch, opts ...client
 	}
 	decoder := scheme.Codecs.UniversalDecoder()
 	zero(obj)
+	if _, _, err := decoder.Decode(j, nil, obj); err != nil {
+		return err
+	}
+	c.collectGarbage(ctx)
+	return nil
+}
+
+func (c *fakeClient) Apply(ctx context.Context, obj client.ApplyConfiguration, opts ...client.ApplyOption) error {
+	applyOptions := &client.ApplyOptions{}
+	applyOptions.ApplyOptions(opts)
+
+	u, err := applyconfiguration.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	patchOptions := &client.PatchOptions{
+		DryRun:       applyOptions.DryRun,
+		Force:        applyOptions.Force,
+		FieldManager: applyOptions.FieldManager,
+		Raw:          applyOptions.Raw,
+	}
+	if err := c.apply(ctx, u, false, patchOptions); err != nil {
+		return err
+	}
+	return copyApplyResult(u, obj)
+}
+
+// applyPatch handles server-side apply requests that are sent through Patch, in which
+// case obj is the applied configuration.
+func (c *fakeClient) applyPatch(ctx context.Context, obj client.Object, patch client.Patch, isStatus bool, patchOptions *client.PatchOptions) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &u.Object); err != nil {
+		return err
+	}
+	if u.GroupVersionKind().Empty() {
+		gvk, err := apiutil.GVKForObject(obj, c.scheme)
+		if err != nil {
+			return err
+		}
+		u.SetGroupVersionKind(gvk)
+	}
+
+	if err := c.apply(ctx, u, isStatus, patchOptions); err != nil {
+		return err
+	}
+
+	j, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	decoder := scheme.Codecs.UniversalDecoder()
+	zero(obj)
 	_, _, err = decoder.Decode(j, nil, obj)
 	return err
 }
 
+// apply merges the applied configuration u into the stored object using the
+// structured-merge-diff field manager, the same way the apiserver does. The
+// object is created if it doesn't exist yet. On success, u is replaced with
+// the resulting object.
+func (c *fakeClient) apply(ctx context.Context, u *unstructured.Unstructured, isStatus bool, patchOptions *client.PatchOptions) error {
+	if patchOptions.FieldManager == "" {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: metav1.GroupName, Kind: "PatchOptions"},
+			"",
+			field.ErrorList{field.Required(field.NewPath("fieldManager"), "is required for apply patch")})
+	}
+
+	gvk, err := apiutil.GVKForObject(u, c.scheme)
+	if err != nil {
+		return err
+	}
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+
+	var liveObj *unstructured.Unstructured
+	creating := false
+	oldObj, err := c.tracker.Get(gvr, u.GetNamespace(), u.GetName())
+	switch {
+	case err == nil:
+		if liveObj, err = toUnstructuredWithGVK(oldObj, gvk); err != nil {
+			return err
+		}
+		if len(liveObj.GetManagedFields()) == 0 {
+			// Objects that were added to the tracker directly don't have managedFields.
+			// Attribute their fields to the default field manager, as if they had been
+			// created through the client.
+			if err := c.tracker.updateManagedFields(gvk, nil, liveObj, false, defaultFieldManager); err != nil {
+				return err
+			}
+		}
+	case apierrors.IsNotFound(err) && !isStatus:
+		// Server-side apply creates the object if it doesn't exist yet.
+		creating = true
+		liveObj = &unstructured.Unstructured{}
+		liveObj.SetGroupVersionKind(gvk)
+	default:
+		return err
+	}
+
+	fm, err := c.tracker.fieldManager(gvk, isStatus)
+	if err != nil {
+		return err
+	}
+	force := patchOptions.Force != nil && *patchOptions.Force
+	result, err := fm.Apply(liveObj, u, patchOptions.FieldManager, force)
+	if err != nil {
+		return err
+	}
+	applied, err := toUnstructuredWithGVK(result, gvk)
+	if err != nil {
+		return err
+	}
+
+	dryRun := isDryRun(patchOptions.DryRun)
+	if !isStatus {
+		operation := admissionv1.Update
+		if creating {
+			operation = admissionv1.Create
+		}
+		if err := c.admit(ctx, operation, gvr, applied, u.GetNamespace(), u.GetName(), patchOptions.AsPatchOptions(), dryRun); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		if err := c.stripManagedFieldsIfNecessary(applied); err != nil {
+			return err
+		}
+		u.Object = applied.Object
+		return nil
+	}
+
+	if creating {
+		err = c.tracker.create(gvr, applied, u.GetNamespace(), "")
+	} else {
+		err = c.tracker.update(gvr, applied, u.GetNamespace(), isStatus, false, "")
+	}
+	if err != nil {
+		return err
+	}
+	u.Object = applied.Object
+	// The tracker drops the type information of typed objects, but like the
+	// API server, return the applied object with it.
+	u.SetGroupVersionKind(gvk)
+	c.collectGarbage(ctx)
+	return nil
+}
+
+// copyApplyResult copies the result of an apply request back into the apply
+// configuration that was passed in by the caller.
+func copyApplyResult(u *unstructured.Unstructured, obj client.ApplyConfiguration) error {
+	if u == obj {
+		return nil
+	}
+	j, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	zero(obj)
+	return json.Unmarshal(j, obj)
+}
+
 // Applying a patch results in a deletionTimestamp that is truncated to the nearest second.
 // Check that the diff between a new and old deletion timestamp is within a reasonable threshold
 // to be considered unchanged.
@@ -935,7 +1460,18 @@ func dryPatch(action testing.PatchActionImpl, tracker testing.ObjectTracker) (ru
 		if err := json.Unmarshal(modified, obj); err != nil {
 			return nil, err
 		}
-	case types.StrategicMergePatchType, types.ApplyPatchType:
+	case types.StrategicMergePatchType:
+		// Like the apiserver, only accept strategic merge patches for built-in types,
+		// the patch metadata is taken from the tags of their Go types.
+		if !isBuiltInType(gvr.Group, obj) {
+			return nil, &apierrors.StatusError{ErrStatus: metav1.Status{
+				Status: metav1.StatusFailure,
+				Code:   http.StatusUnsupportedMediaType,
+				Reason: metav1.StatusReasonUnsupportedMediaType,
+				Message: fmt.Sprintf("the body of the request was in an unknown format - accepted media types include: %s, %s, %s",
+					types.JSONPatchType, types.MergePatchType, types.ApplyPatchType),
+			}}
+		}
 		mergedByte, err := strategicpatch.StrategicMergePatch(old, action.GetPatch(), obj)
 		if err != nil {
 			return nil, err
@@ -949,6 +1485,29 @@ func dryPatch(action testing.PatchActionImpl, tracker testing.ObjectTracker) (ru
 	return obj, nil
 }
 
+// builtInGroups are the API groups served by the Kubernetes API server itself.
+// They are taken from a scheme of their own, because the global scheme.Scheme
+// often has the Go types of CRDs registered in it, too.
+var builtInGroups = func() sets.Set[string] {
+	builtInScheme := runtime.NewScheme()
+	utilruntime.Must(scheme.AddToScheme(builtInScheme))
+	groups := sets.New[string]("apiextensions.k8s.io", "apiregistration.k8s.io")
+	for gvk := range builtInScheme.AllKnownTypes() {
+		groups.Insert(gvk.Group)
+	}
+	return groups
+}()
+
+// isBuiltInType returns whether obj is a typed object of a kind that is built into
+// Kubernetes, as opposed to an unstructured object or a CRD. group is the API
+// group of obj.
+func isBuiltInType(group string, obj runtime.Object) bool {
+	if _, isUnstructured := obj.(runtime.Unstructured); isUnstructured {
+		return false
+	}
+	return builtInGroups.Has(group)
+}
+
 func copyNonStatusFrom(old, new runtime.Object) error {
 	newClientObject, ok := new.(client.Object)
 	if !ok {
@@ -999,7 +1558,11 @@ func copyStatusFrom(old, new runtime.Object) error {
 		return fmt.Errorf("failed to convert new to *unststructured.Unstructured: %w", err)
 	}
 
-	newMapStringAny["status"] = oldMapStringAny["status"]
+	if status, hasStatus := oldMapStringAny["status"]; hasStatus {
+		newMapStringAny["status"] = status
+	} else {
+		delete(newMapStringAny, "status")
+	}
 
 	if err := fromMapStringAny(newMapStringAny, new); err != nil {
 		return fmt.Errorf("failed to convert back from map[string]any: %w", err)
@@ -1048,23 +1611,48 @@ func (c *fakeClient) SubResource(subResource string) client.SubResourceClient {
 	return &fakeSubResourceClient{client: c, subResource: subResource}
 }
 
-func (c *fakeClient) deleteObject(gvr schema.GroupVersionResource, accessor metav1.Object) error {
+func (c *fakeClient) deleteObject(gvr schema.GroupVersionResource, accessor metav1.Object, opts *client.DeleteOptions) error {
 	old, err := c.tracker.Get(gvr, accessor.GetNamespace(), accessor.GetName())
 	if err == nil {
 		oldAccessor, err := meta.Accessor(old)
 		if err == nil {
+			if c.garbageCollection {
+				setDeletionFinalizers(oldAccessor, opts)
+			}
 			if len(oldAccessor.GetFinalizers()) > 0 {
 				now := metav1.Now()
 				oldAccessor.SetDeletionTimestamp(&now)
 				// Call update directly with mutability parameter set to true to allow
 				// changes to deletionTimestamp
-				return c.tracker.update(gvr, old, accessor.GetNamespace(), false, true)
+				return c.tracker.update(gvr, old, accessor.GetNamespace(), false, true, "")
 			}
 		}
 	}
 
 	//TODO: implement propagation
-	return c.tracker.Delete(gvr, accessor.GetNamespace(), accessor.GetName())
+	if err := c.tracker.Delete(gvr, accessor.GetNamespace(), accessor.GetName()); err != nil {
+		return err
+	}
+	c.tracker.garbage.Store(true)
+	return nil
+}
+
+// isDryRun returns whether the given dry run options request a dry run.
+func isDryRun(dryRun []string) bool {
+	for _, dryRunOpt := range dryRun {
+		if dryRunOpt == metav1.DryRunAll {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldManagerOrDefault returns the given field manager, or the default one if it is empty.
+func fieldManagerOrDefault(fieldManager string) string {
+	if fieldManager == "" {
+		return defaultFieldManager
+	}
+	return fieldManager
 }
 
 func getGVRFromObject(obj runtime.Object, scheme *runtime.Scheme) (schema.GroupVersionResource, error) {
@@ -1076,6 +1664,8 @@ func getGVRFromObject(obj runtime.Object, scheme *runtime.Scheme) (schema.GroupV
 	return gvr, nil
 }
 
+var _ client.SubResourceStreamer = &fakeSubResourceClient{}
+
 type fakeSubResourceClient struct {
 	client      *fakeClient
 	subResource string
@@ -1085,6 +1675,21 @@ func (sw *fakeSubResourceClient) Get(ctx context.Context, obj, subResource clien
 	panic("fakeSubResourceClient does not support get")
 }
 
+func (sw *fakeSubResourceClient) Stream(ctx context.Context, obj client.Object, opts ...client.SubResourceStreamOption) (io.ReadCloser, error) {
+	current, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("object %T does not implement client.Object", obj)
+	}
+	if err := sw.client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return nil, err
+	}
+
+	if sw.client.streamFunc == nil {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return sw.client.streamFunc(ctx, current, sw.subResource, (&client.SubResourceStreamOptions{}).ApplyOptions(opts))
+}
+
 func (sw *fakeSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
 	switch sw.subResource {
 	case "eviction":
@@ -1113,7 +1718,7 @@ func (sw *fakeSubResourceClient) Update(ctx context.Context, obj client.Object,
 	if updateOptions.SubResourceBody != nil {
 		body = updateOptions.SubResourceBody
 	}
-	return sw.client.update(body, true, &updateOptions.UpdateOptions)
+	return sw.client.update(ctx, body, true, &updateOptions.UpdateOptions)
 }
 
 func (sw *fakeSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
@@ -1125,7 +1730,31 @@ func (sw *fakeSubResourceClient) Patch(ctx context.Context, obj client.Object, p
 		body = patchOptions.SubResourceBody
 	}
 
-	return sw.client.patch(body, patch, &patchOptions.PatchOptions)
+	return sw.client.patch(ctx, body, patch, true, &patchOptions.PatchOptions)
+}
+
+func (sw *fakeSubResourceClient) Apply(ctx context.Context, obj client.ApplyConfiguration, opts ...client.SubResourceApplyOption) error {
+	applyOptions := client.SubResourceApplyOptions{}
+	applyOptions.ApplyOpts(opts)
+
+	body := obj
+	if applyOptions.SubResourceBody != nil {
+		body = applyOptions.SubResourceBody
+	}
+	u, err := applyconfiguration.ToUnstructured(body)
+	if err != nil {
+		return err
+	}
+	patchOptions := &client.PatchOptions{
+		DryRun:       applyOptions.DryRun,
+		Force:        applyOptions.Force,
+		FieldManager: applyOptions.FieldManager,
+		Raw:          applyOptions.Raw,
+	}
+	if err := sw.client.apply(ctx, u, true, patchOptions); err != nil {
+		return err
+	}
+	return copyApplyResult(u, body)
 }
 
 func allowsUnconditionalUpdate(gvk schema.GroupVersionKind) bool {
@@ -20,7 +20,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -37,9 +39,12 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
@@ -846,6 +851,102 @@ var _ = Describe("Fake client", func() {
 			Expect(service.Name).To(Equal("for-watch"))
 		})
 
+		It("should be able to watch metadata only objects", func() {
+			By("Creating a watch")
+			list := &metav1.PartialObjectMetadataList{}
+			list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ServiceList"))
+			objWatch, err := cl.Watch(context.Background(), list)
+			Expect(err).NotTo(HaveOccurred())
+
+			defer objWatch.Stop()
+
+			go func() {
+				defer GinkgoRecover()
+				// It is likely starting a new goroutine is slower than progressing
+				// in the outer routine, sleep to make sure this is always true
+				time.Sleep(100 * time.Millisecond)
+
+				err := cl.Create(context.Background(), &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "for-metadata-watch"}})
+				Expect(err).ToNot(HaveOccurred())
+			}()
+
+			event, ok := <-objWatch.ResultChan()
+			Expect(ok).To(BeTrue())
+			Expect(event.Type).To(Equal(watch.Added))
+
+			service, ok := event.Object.(*metav1.PartialObjectMetadata)
+			Expect(ok).To(BeTrue())
+			Expect(service.Name).To(Equal("for-metadata-watch"))
+			Expect(service.GroupVersionKind()).To(Equal(corev1.SchemeGroupVersion.WithKind("Service")))
+		})
+
+		It("should only watch objects matching the label selector", func() {
+			By("Creating a watch")
+			objWatch, err := cl.Watch(context.Background(), &corev1.ServiceList{}, client.MatchingLabels{"app": "watched"})
+			Expect(err).NotTo(HaveOccurred())
+
+			defer objWatch.Stop()
+
+			By("Creating a service that doesn't match")
+			Expect(cl.Create(context.Background(), &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "not-watched"}})).To(Succeed())
+
+			By("Creating a service that matches")
+			service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "watched", Labels: map[string]string{"app": "watched"}}}
+			Expect(cl.Create(context.Background(), service)).To(Succeed())
+
+			event, ok := <-objWatch.ResultChan()
+			Expect(ok).To(BeTrue())
+			Expect(event.Type).To(Equal(watch.Added))
+			Expect(event.Object.(*corev1.Service).Name).To(Equal("watched"))
+
+			By("Removing the label of the service")
+			service.Labels = nil
+			Expect(cl.Update(context.Background(), service)).To(Succeed())
+
+			event, ok = <-objWatch.ResultChan()
+			Expect(ok).To(BeTrue())
+			Expect(event.Type).To(Equal(watch.Deleted))
+			Expect(event.Object.(*corev1.Service).Name).To(Equal("watched"))
+		})
+
+		It("should only send deleted events for objects that stop matching the selector to watchers that know them", func() {
+			By("Creating a service that matches before the watch starts")
+			existing := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "existing", Labels: map[string]string{"app": "watched"}}}
+			Expect(cl.Create(context.Background(), existing)).To(Succeed())
+
+			By("Creating a watch")
+			objWatch, err := cl.Watch(context.Background(), &corev1.ServiceList{}, client.MatchingLabels{"app": "watched"})
+			Expect(err).NotTo(HaveOccurred())
+
+			defer objWatch.Stop()
+
+			By("Updating a service that never matched")
+			notWatched := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "not-watched"}}
+			Expect(cl.Create(context.Background(), notWatched)).To(Succeed())
+			notWatched.Annotations = map[string]string{"updated": "true"}
+			Expect(cl.Update(context.Background(), notWatched)).To(Succeed())
+			Expect(cl.Delete(context.Background(), notWatched)).To(Succeed())
+
+			By("Removing the label of the service that matched before the watch started")
+			existing.Labels = nil
+			Expect(cl.Update(context.Background(), existing)).To(Succeed())
+
+			event, ok := <-objWatch.ResultChan()
+			Expect(ok).To(BeTrue())
+			Expect(event.Type).To(Equal(watch.Deleted))
+			Expect(event.Object.(*corev1.Service).Name).To(Equal("existing"))
+
+			By("Updating the service that doesn't match anymore")
+			existing.Annotations = map[string]string{"updated": "true"}
+			Expect(cl.Update(context.Background(), existing)).To(Succeed())
+			Consistently(objWatch.ResultChan()).ShouldNot(Receive())
+		})
+
+		It("should reject field selectors without an index when watching", func() {
+			_, err := cl.Watch(context.Background(), &corev1.ServiceList{}, client.MatchingFields{"spec.type": "ClusterIP"})
+			Expect(err).To(HaveOccurred())
+		})
+
 		Context("with the DryRun option", func() {
 			It("should not create a new object", func() {
 				By("Creating a new configmap with DryRun")
@@ -917,6 +1018,27 @@ var _ = Describe("Fake client", func() {
 				Expect(obj).To(Equal(cm))
 				Expect(obj.ObjectMeta.ResourceVersion).To(Equal(trackerAddResourceVersion))
 			})
+
+			It("should not modify the created object", func() {
+				newcm := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						GenerateName: "new-test-cm-",
+						Namespace:    "ns2",
+					},
+				}
+				Expect(cl.Create(context.Background(), newcm, client.DryRunAll)).To(Succeed())
+				Expect(newcm.Name).To(BeEmpty())
+			})
+
+			It("should not check the deleted object", func() {
+				By("Deleting a configmap that doesn't exist with DryRun")
+				missing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "ns2"}}
+				Expect(cl.Delete(context.Background(), missing, client.DryRunAll)).To(Succeed())
+
+				By("Deleting a configmap with DryRun and a precondition that doesn't match")
+				bogusRV := "bogus"
+				Expect(cl.Delete(context.Background(), cm, client.DryRunAll, client.Preconditions{ResourceVersion: &bogusRV})).To(Succeed())
+			})
 		})
 
 		It("should be able to Patch", func() {
@@ -1542,6 +1664,24 @@ var _ = Describe("Fake client", func() {
 		Expect(obj.Object["status"]).To(BeEquivalentTo(map[string]any{"state": "old"}))
 	})
 
+	It("should not set the status of unstructured objects that are configured to have a status subresource and have no status on update", func() {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("foo/v1")
+		obj.SetKind("Foo")
+		obj.SetName("a-foo")
+
+		cl := NewClientBuilder().WithStatusSubresource(obj).WithObjects(obj).Build()
+
+		err := unstructured.SetNestedField(obj.Object, map[string]any{"state": "new"}, "status")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Update(context.Background(), obj)).To(Succeed())
+		Expect(obj.Object).NotTo(HaveKey("status"))
+
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+		Expect(obj.Object).NotTo(HaveKey("status"))
+	})
+
 	It("should not change non-status fields of unstructured objects that are configured to have a status subresource on status update", func() {
 		obj := &unstructured.Unstructured{}
 		obj.SetAPIVersion("foo/v1")
@@ -1621,6 +1761,326 @@ var _ = Describe("Fake client", func() {
 		Expect(apierrors.IsNotFound(err)).To(BeTrue())
 	})
 
+	It("should create an object when applying an apply configuration", func() {
+		cl := NewClientBuilder().Build()
+
+		cm := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"some": "data"})
+		Expect(cl.Apply(context.Background(), cm, client.FieldOwner("test-owner"))).To(Succeed())
+		Expect(cm.ResourceVersion).NotTo(BeNil())
+		Expect(cm.Data).To(Equal(map[string]string{"some": "data"}))
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, actual)).To(Succeed())
+		Expect(actual.Data).To(Equal(map[string]string{"some": "data"}))
+	})
+
+	It("should update an existing object when applying an apply configuration", func() {
+		obj := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Data:       map[string]string{"some": "data"},
+		}
+		cl := NewClientBuilder().WithObjects(obj).Build()
+
+		cm := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"other": "data"})
+		Expect(cl.Apply(context.Background(), cm, client.FieldOwner("test-owner"))).To(Succeed())
+		Expect(cm.ResourceVersion).To(HaveValue(Equal("1000")))
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(obj), actual)).To(Succeed())
+		Expect(actual.Data).To(HaveKeyWithValue("other", "data"))
+	})
+
+	It("should apply unstructured objects", func() {
+		cl := NewClientBuilder().Build()
+
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("ConfigMap")
+		u.SetNamespace("default")
+		u.SetName("foo")
+		Expect(unstructured.SetNestedField(u.Object, "data", "data", "some")).To(Succeed())
+		Expect(cl.Apply(context.Background(), u, client.FieldOwner("test-owner"))).To(Succeed())
+		Expect(u.GetResourceVersion()).NotTo(BeEmpty())
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, actual)).To(Succeed())
+		Expect(actual.Data).To(Equal(map[string]string{"some": "data"}))
+	})
+
+	It("should apply to existing unstructured objects", func() {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("example.com/v1")
+		obj.SetKind("Widget")
+		obj.SetNamespace("default")
+		obj.SetName("foo")
+		Expect(unstructured.SetNestedField(obj.Object, "small", "spec", "size")).To(Succeed())
+		cl := NewClientBuilder().WithObjects(obj).Build()
+
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("example.com/v1")
+		u.SetKind("Widget")
+		u.SetNamespace("default")
+		u.SetName("foo")
+		Expect(unstructured.SetNestedField(u.Object, int64(2), "spec", "replicas")).To(Succeed())
+		Expect(cl.Apply(context.Background(), u, client.FieldOwner("test-owner"))).To(Succeed())
+
+		actual := &unstructured.Unstructured{}
+		actual.SetAPIVersion("example.com/v1")
+		actual.SetKind("Widget")
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(obj), actual)).To(Succeed())
+		Expect(actual.Object["spec"]).To(Equal(map[string]interface{}{"size": "small", "replicas": int64(2)}))
+	})
+
+	It("should require a field manager when applying", func() {
+		cl := NewClientBuilder().Build()
+
+		err := cl.Apply(context.Background(), corev1ac.ConfigMap("foo", "default"))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("should not persist anything when applying with DryRunAll", func() {
+		cl := NewClientBuilder().Build()
+
+		Expect(cl.Apply(context.Background(), corev1ac.ConfigMap("foo", "default"), client.FieldOwner("test-owner"), client.DryRunAll)).To(Succeed())
+
+		err := cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should only change the status when applying to the status subresource", func() {
+		obj := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node"},
+			Spec:       corev1.NodeSpec{PodCIDR: "old-cidr"},
+		}
+		cl := NewClientBuilder().WithStatusSubresource(obj).WithObjects(obj).Build()
+
+		node := corev1ac.Node("node").
+			WithSpec(corev1ac.NodeSpec().WithPodCIDR("new-cidr")).
+			WithStatus(corev1ac.NodeStatus().WithNodeInfo(corev1ac.NodeSystemInfo().WithMachineID("machine-id")))
+		Expect(cl.Status().Apply(context.Background(), node, client.FieldOwner("test-owner"))).To(Succeed())
+		Expect(node.APIVersion).To(HaveValue(Equal("v1")))
+		Expect(node.Kind).To(HaveValue(Equal("Node")))
+		Expect(node.ResourceVersion).NotTo(BeNil())
+
+		actual := &corev1.Node{}
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(obj), actual)).To(Succeed())
+		Expect(actual.Spec.PodCIDR).To(Equal("old-cidr"))
+		Expect(actual.Status.NodeInfo.MachineID).To(Equal("machine-id"))
+		Expect(*node.ResourceVersion).To(Equal(actual.ResourceVersion))
+	})
+
+	It("should not return managedFields by default", func() {
+		cl := NewClientBuilder().Build()
+
+		cm := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"some": "data"})
+		Expect(cl.Apply(context.Background(), cm, client.FieldOwner("test-owner"))).To(Succeed())
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, actual)).To(Succeed())
+		Expect(actual.ManagedFields).To(BeEmpty())
+	})
+
+	It("should track managedFields of all writes", func() {
+		cl := NewClientBuilder().WithReturnManagedFields().Build()
+
+		obj := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Data:       map[string]string{"created": "data"},
+		}
+		Expect(cl.Create(context.Background(), obj, client.FieldOwner("creator"))).To(Succeed())
+		Expect(obj.ManagedFields).To(HaveLen(1))
+
+		cm := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"applied": "data"})
+		Expect(cl.Apply(context.Background(), cm, client.FieldOwner("applier"))).To(Succeed())
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(obj), actual)).To(Succeed())
+		Expect(actual.Data).To(Equal(map[string]string{"created": "data", "applied": "data"}))
+		Expect(actual.ManagedFields).To(ConsistOf(
+			And(
+				HaveField("Manager", "creator"),
+				HaveField("Operation", metav1.ManagedFieldsOperationUpdate),
+				HaveField("FieldsV1.Raw", WithTransform(func(raw []byte) string { return string(raw) }, ContainSubstring(`"f:created"`))),
+			),
+			And(
+				HaveField("Manager", "applier"),
+				HaveField("Operation", metav1.ManagedFieldsOperationApply),
+				HaveField("FieldsV1.Raw", WithTransform(func(raw []byte) string { return string(raw) }, ContainSubstring(`"f:applied"`))),
+			),
+		))
+	})
+
+	It("should return a conflict when applying a field that is owned by another manager", func() {
+		cl := NewClientBuilder().Build()
+
+		first := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"some": "first"})
+		Expect(cl.Apply(context.Background(), first, client.FieldOwner("first"))).To(Succeed())
+
+		second := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"some": "second"})
+		err := cl.Apply(context.Background(), second, client.FieldOwner("second"))
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, actual)).To(Succeed())
+		Expect(actual.Data).To(HaveKeyWithValue("some", "first"))
+	})
+
+	It("should take ownership of conflicting fields when applying with ForceOwnership", func() {
+		cl := NewClientBuilder().WithReturnManagedFields().Build()
+
+		first := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"some": "first"})
+		Expect(cl.Apply(context.Background(), first, client.FieldOwner("first"))).To(Succeed())
+
+		second := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"some": "second"})
+		Expect(cl.Apply(context.Background(), second, client.FieldOwner("second"), client.ForceOwnership)).To(Succeed())
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, actual)).To(Succeed())
+		Expect(actual.Data).To(HaveKeyWithValue("some", "second"))
+		Expect(actual.ManagedFields).To(HaveLen(1))
+		Expect(actual.ManagedFields[0].Manager).To(Equal("second"))
+	})
+
+	It("should return a conflict when applying a field that was changed by an update", func() {
+		obj := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Data:       map[string]string{"some": "data"},
+		}
+		cl := NewClientBuilder().Build()
+		Expect(cl.Create(context.Background(), obj, client.FieldOwner("updater"))).To(Succeed())
+
+		cm := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"some": "applied"})
+		err := cl.Apply(context.Background(), cm, client.FieldOwner("applier"))
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+	})
+
+	It("should remove fields that are no longer applied", func() {
+		cl := NewClientBuilder().Build()
+
+		cm := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"a": "a", "b": "b"})
+		Expect(cl.Apply(context.Background(), cm, client.FieldOwner("test-owner"))).To(Succeed())
+
+		cm = corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"a": "a"})
+		Expect(cl.Apply(context.Background(), cm, client.FieldOwner("test-owner"))).To(Succeed())
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, actual)).To(Succeed())
+		Expect(actual.Data).To(Equal(map[string]string{"a": "a"}))
+	})
+
+	It("should merge associative lists of different managers when applying", func() {
+		cl := NewClientBuilder().Build()
+
+		pod := corev1ac.Pod("foo", "default").WithSpec(corev1ac.PodSpec().WithContainers(
+			corev1ac.Container().WithName("first").WithImage("first-image"),
+		))
+		Expect(cl.Apply(context.Background(), pod, client.FieldOwner("first"))).To(Succeed())
+
+		pod = corev1ac.Pod("foo", "default").WithSpec(corev1ac.PodSpec().WithContainers(
+			corev1ac.Container().WithName("second").WithImage("second-image"),
+		))
+		Expect(cl.Apply(context.Background(), pod, client.FieldOwner("second"))).To(Succeed())
+		Expect(pod.Spec.Containers).To(HaveLen(2))
+
+		actual := &corev1.Pod{}
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, actual)).To(Succeed())
+		Expect(actual.Spec.Containers).To(ConsistOf(
+			HaveField("Name", "first"),
+			HaveField("Name", "second"),
+		))
+	})
+
+	It("should apply objects through Patch", func() {
+		cl := NewClientBuilder().Build()
+
+		obj := &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Data:       map[string]string{"some": "data"},
+		}
+		Expect(cl.Patch(context.Background(), obj, client.Apply, client.FieldOwner("test-owner"))).To(Succeed())
+		Expect(obj.ResourceVersion).To(Equal("1"))
+
+		obj = &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Data:       map[string]string{"some": "other-data"},
+		}
+		err := cl.Patch(context.Background(), obj, client.Apply, client.FieldOwner("other-owner"))
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+		Expect(cl.Patch(context.Background(), obj, client.Apply, client.FieldOwner("other-owner"), client.ForceOwnership)).To(Succeed())
+		Expect(obj.ResourceVersion).To(Equal("2"))
+		Expect(obj.Data).To(Equal(map[string]string{"some": "other-data"}))
+	})
+
+	It("should not persist anything when applying an existing object with DryRunAll", func() {
+		obj := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Data:       map[string]string{"some": "data"},
+		}
+		cl := NewClientBuilder().WithObjects(obj).Build()
+
+		cm := corev1ac.ConfigMap("foo", "default").WithData(map[string]string{"other": "data"})
+		Expect(cl.Apply(context.Background(), cm, client.FieldOwner("test-owner"), client.DryRunAll)).To(Succeed())
+		Expect(cm.Data).To(Equal(map[string]string{"some": "data", "other": "data"}))
+
+		actual := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(obj), actual)).To(Succeed())
+		Expect(actual.Data).To(Equal(map[string]string{"some": "data"}))
+	})
+
+	It("should merge lists by their patch merge key when applying a strategic merge patch", func() {
+		obj := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "first", Image: "first-image"},
+			}},
+		}
+		cl := NewClientBuilder().WithObjects(obj).Build()
+
+		patch := []byte(`{"spec":{"containers":[{"name":"second","image":"second-image"}]}}`)
+		Expect(cl.Patch(context.Background(), obj, client.RawPatch(types.StrategicMergePatchType, patch))).To(Succeed())
+
+		actual := &corev1.Pod{}
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(obj), actual)).To(Succeed())
+		Expect(actual.Spec.Containers).To(ConsistOf(
+			corev1.Container{Name: "first", Image: "first-image"},
+			corev1.Container{Name: "second", Image: "second-image"},
+		))
+	})
+
+	It("should reject strategic merge patches for types that are not built-in", func() {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("custom/v1")
+		obj.SetKind("Image")
+		obj.SetNamespace("default")
+		obj.SetName("foo")
+		cl := NewClientBuilder().Build()
+		Expect(cl.Create(context.Background(), obj)).To(Succeed())
+
+		patch := []byte(`{"metadata":{"labels":{"foo":"bar"}}}`)
+		err := cl.Patch(context.Background(), obj, client.RawPatch(types.StrategicMergePatchType, patch))
+		Expect(apierrors.IsUnsupportedMediaType(err)).To(BeTrue())
+
+		Expect(cl.Patch(context.Background(), obj, client.RawPatch(types.MergePatchType, patch))).To(Succeed())
+		Expect(obj.GetLabels()).To(Equal(map[string]string{"foo": "bar"}))
+	})
+
+	It("should reject strategic merge patches for CRD types registered in the client-go scheme", func() {
+		gv := schema.GroupVersion{Group: "crd.example.com", Version: "v1"}
+		scheme.Scheme.AddKnownTypes(gv, &crdWidget{})
+		obj := &crdWidget{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+		cl := NewClientBuilder().WithObjects(obj).Build()
+
+		patch := []byte(`{"metadata":{"labels":{"foo":"bar"}}}`)
+		err := cl.Patch(context.Background(), obj, client.RawPatch(types.StrategicMergePatchType, patch))
+		Expect(apierrors.IsUnsupportedMediaType(err)).To(BeTrue())
+
+		Expect(cl.Patch(context.Background(), obj, client.RawPatch(types.MergePatchType, patch))).To(Succeed())
+		Expect(obj.GetLabels()).To(Equal(map[string]string{"foo": "bar"}))
+	})
+
 	evictionTypes := []client.Object{
 		&policyv1beta1.Eviction{},
 		&policyv1.Eviction{},
@@ -1670,6 +2130,61 @@ var _ = Describe("Fake client", func() {
 	})
 })
 
+var _ = Describe("Fake client streams", func() {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+
+	It("should return empty streams by default", func() {
+		cl := NewClientBuilder().WithObjects(pod.DeepCopy()).Build()
+		logs, err := client.StreamSubResource(context.Background(), cl.SubResource("log"), pod)
+		Expect(err).NotTo(HaveOccurred())
+		defer logs.Close()
+		Expect(io.ReadAll(logs)).To(BeEmpty())
+	})
+
+	It("should serve streams with the stream function", func() {
+		cl := NewClientBuilder().WithObjects(pod.DeepCopy()).WithStreamFunc(func(ctx context.Context, obj client.Object, subResource string, opts *client.SubResourceStreamOptions) (io.ReadCloser, error) {
+			Expect(obj.GetResourceVersion()).NotTo(BeEmpty())
+			Expect(subResource).To(Equal("log"))
+			logOpts := opts.Raw.(*corev1.PodLogOptions)
+			return io.NopCloser(strings.NewReader("logs of " + logOpts.Container)), nil
+		}).Build()
+
+		logs, err := client.StreamSubResource(context.Background(), cl.SubResource("log"), pod, &client.SubResourceStreamOptions{
+			Raw: &corev1.PodLogOptions{Container: "app"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer logs.Close()
+		Expect(io.ReadAll(logs)).To(BeEquivalentTo("logs of app"))
+	})
+
+	It("should fail to stream subresources of objects that don't exist", func() {
+		cl := NewClientBuilder().Build()
+		_, err := client.StreamSubResource(context.Background(), cl.SubResource("log"), pod)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should implement SubResourceStreamer", func() {
+		cl := NewClientBuilder().WithObjects(pod.DeepCopy()).Build()
+		streamer, ok := cl.SubResource("log").(client.SubResourceStreamer)
+		Expect(ok).To(BeTrue())
+		logs, err := streamer.Stream(context.Background(), pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logs.Close()).To(Succeed())
+	})
+
+	It("should fail to stream with subresource clients that don't implement SubResourceStreamer", func() {
+		cl := NewClientBuilder().WithObjects(pod.DeepCopy()).Build()
+		_, err := client.StreamSubResource(context.Background(), nonStreamingSubResourceClient{cl.SubResource("log")}, pod)
+		Expect(err).To(MatchError(ContainSubstring("does not support streaming")))
+	})
+})
+
+// nonStreamingSubResourceClient is a SubResourceClient that doesn't implement
+// SubResourceStreamer.
+type nonStreamingSubResourceClient struct {
+	client.SubResourceClient
+}
+
 var _ = Describe("Fake client builder", func() {
 	It("panics when an index with the same name and GroupVersionKind is registered twice", func() {
 		// We need any realistic GroupVersionKind, the choice of apps/v1 Deployment is arbitrary.
@@ -1697,3 +2212,15 @@ var _ = Describe("Fake client builder", func() {
 		Expect(called).To(BeTrue())
 	})
 })
+
+// crdWidget is the Go type of a CRD.
+type crdWidget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+func (w *crdWidget) DeepCopyObject() runtime.Object {
+	out := &crdWidget{TypeMeta: w.TypeMeta}
+	w.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
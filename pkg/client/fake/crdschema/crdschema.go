@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crdschema validates, prunes and defaults custom resources written
+// with the fake client against the schemas of their CustomResourceDefinitions,
+// like the API server does, e.g.:
+//
+//	schemas, err := crdschema.Load(filepath.Join("..", "config", "crd", "bases"))
+//	if err != nil {
+//		...
+//	}
+//	c := fake.NewClientBuilder().WithObjectSchemas(schemas).Build()
+//
+// Writes of custom resources are then validated against the schemas of their
+// CRDs, including x-kubernetes-validations rules, and unknown fields are pruned
+// and defaults are applied.
+package crdschema
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	structurallisttype "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/listtype"
+	schemaobjectmeta "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/objectmeta"
+	structuralpruning "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	apiservervalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/internal/manifest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ fake.ObjectSchemas = &Schemas{}
+
+// Schemas are the schemas of the versions of a set of CRDs. They implement
+// fake.ObjectSchemas.
+type Schemas struct {
+	crds    []*apiextensionsv1.CustomResourceDefinition
+	schemas map[schema.GroupVersionKind]*versionSchema
+}
+
+// versionSchema holds what is needed to validate, prune and default the
+// objects of one version of a CRD.
+type versionSchema struct {
+	structural   *structuralschema.Structural
+	validator    apiservervalidation.SchemaValidator
+	celValidator *cel.Validator
+}
+
+// Load returns the schemas of the CustomResourceDefinitions in the given paths,
+// which can be directories or files like the Paths of envtest.CRDInstallOptions.
+// It returns an error if a path doesn't exist.
+func Load(paths ...string) (*Schemas, error) {
+	crds, err := manifest.ReadCRDs(paths, true, log.Log.WithName("crdschema"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CRD files: %w", err)
+	}
+	return New(crds...)
+}
+
+// New returns the schemas of the given CustomResourceDefinitions. It returns
+// an error if a schema is not structural.
+func New(crds ...*apiextensionsv1.CustomResourceDefinition) (*Schemas, error) {
+	s := &Schemas{crds: crds, schemas: map[schema.GroupVersionKind]*versionSchema{}}
+	for _, crd := range crds {
+		for _, version := range crd.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+
+			validation := &apiextensions.CustomResourceValidation{}
+			if err := apiextensionsv1.Convert_v1_CustomResourceValidation_To_apiextensions_CustomResourceValidation(version.Schema, validation, nil); err != nil {
+				return nil, fmt.Errorf("failed to convert the schema of %s: %w", gvk, err)
+			}
+			structural, err := structuralschema.NewStructural(validation.OpenAPIV3Schema)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert the schema of %s to a structural schema: %w", gvk, err)
+			}
+			if errs := structuralschema.ValidateStructural(nil, structural); len(errs) > 0 {
+				return nil, fmt.Errorf("the schema of %s is not structural: %w", gvk, errs.ToAggregate())
+			}
+			validator, _, err := apiservervalidation.NewSchemaValidator(validation.OpenAPIV3Schema)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create a validator for %s: %w", gvk, err)
+			}
+			s.schemas[gvk] = &versionSchema{
+				structural:   structural,
+				validator:    validator,
+				celValidator: cel.NewValidator(structural, true, celconfig.PerCallLimit),
+			}
+		}
+	}
+	return s, nil
+}
+
+// RESTMapper returns a RESTMapper for the kinds of the CRDs.
+func (s *Schemas) RESTMapper() meta.RESTMapper {
+	restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{})
+	for _, crd := range s.crds {
+		scope := meta.RESTScopeNamespace
+		if crd.Spec.Scope == apiextensionsv1.ClusterScoped {
+			scope = meta.RESTScopeRoot
+		}
+		for _, version := range crd.Spec.Versions {
+			gv := schema.GroupVersion{Group: crd.Spec.Group, Version: version.Name}
+			singular := crd.Spec.Names.Singular
+			if singular == "" {
+				singular = crd.Spec.Names.Plural
+			}
+			restMapper.AddSpecific(gv.WithKind(crd.Spec.Names.Kind), gv.WithResource(crd.Spec.Names.Plural), gv.WithResource(singular), scope)
+		}
+	}
+	return restMapper
+}
+
+// StatusSubresources returns the kinds of the CRDs that have a status
+// subresource.
+func (s *Schemas) StatusSubresources() []schema.GroupVersionKind {
+	var gvks []schema.GroupVersionKind
+	for _, crd := range s.crds {
+		for _, version := range crd.Spec.Versions {
+			if version.Subresources != nil && version.Subresources.Status != nil {
+				gvks = append(gvks, schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind})
+			}
+		}
+	}
+	return gvks
+}
+
+// Apply prunes the unknown fields of obj and applies the defaults of the
+// schema of gvk. If validate is true, obj is also validated against the schema,
+// like the API server validates custom resources. oldObj is the object that is
+// updated, or nil for creates. Apply returns false if gvk has no schema.
+func (s *Schemas) Apply(gvk schema.GroupVersionKind, obj, oldObj map[string]interface{}, validate bool) (bool, field.ErrorList) {
+	vs, ok := s.schemas[gvk]
+	if !ok {
+		return false, nil
+	}
+	structuralpruning.Prune(obj, vs.structural, true)
+	structuraldefaulting.Default(obj, vs.structural)
+	if !validate {
+		return true, nil
+	}
+	return true, vs.validate(obj, oldObj)
+}
+
+// validate validates obj like the API server validates custom resources. old
+// is nil for creates.
+func (vs *versionSchema) validate(obj, old map[string]interface{}) field.ErrorList {
+	var errs field.ErrorList
+	if old == nil {
+		errs = append(errs, apiservervalidation.ValidateCustomResource(nil, obj, vs.validator)...)
+	} else {
+		errs = append(errs, apiservervalidation.ValidateCustomResourceUpdate(nil, obj, old, vs.validator)...)
+	}
+	errs = append(errs, schemaobjectmeta.Validate(nil, obj, vs.structural, false)...)
+	if old == nil || len(structurallisttype.ValidateListSetsAndMaps(nil, vs.structural, old)) == 0 {
+		errs = append(errs, structurallisttype.ValidateListSetsAndMaps(nil, vs.structural, obj)...)
+	}
+
+	if vs.celValidator == nil {
+		return errs
+	}
+	// Like in the API server, rules are not evaluated if the object doesn't
+	// even have the right structure.
+	for _, err := range errs {
+		if err.Type == field.ErrorTypeRequired || err.Type == field.ErrorTypeTooLong || err.Type == field.ErrorTypeTooMany || err.Type == field.ErrorTypeTypeInvalid {
+			return append(errs, field.Invalid(nil, nil, "some validation rules were not checked because the object was invalid; correct the existing errors to complete validation"))
+		}
+	}
+	var oldObj interface{}
+	if old != nil {
+		oldObj = old
+	}
+	celErrs, _ := vs.celValidator.Validate(context.Background(), nil, vs.structural, obj, oldObj, celconfig.RuntimeCELCostBudget)
+	return append(errs, celErrs...)
+}
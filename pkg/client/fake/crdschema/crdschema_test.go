@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdschema_test
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake/crdschema"
+)
+
+var _ = Describe("Fake client with CRD schemas", func() {
+	var (
+		ctx context.Context
+		cl  client.WithWatch
+	)
+
+	load := func(paths ...string) *crdschema.Schemas {
+		schemas, err := crdschema.Load(paths...)
+		Expect(err).NotTo(HaveOccurred())
+		return schemas
+	}
+
+	newWidget := func(name string, spec map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		u.SetAPIVersion("example.com/v1")
+		u.SetKind("Widget")
+		u.SetName(name)
+		u.SetNamespace("default")
+		return u
+	}
+	invalidCauses := func(err error) []string {
+		Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+		var causes []string
+		for _, cause := range err.(apierrors.APIStatus).Status().Details.Causes {
+			causes = append(causes, cause.Field+": "+cause.Message)
+		}
+		return causes
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cl = fake.NewClientBuilder().WithObjectSchemas(load(filepath.Join("testdata", "crds"))).Build()
+	})
+
+	It("should apply defaults and prune unknown fields", func() {
+		widget := newWidget("widget", map[string]interface{}{"size": "small", "unknown": "field"})
+		Expect(cl.Create(ctx, widget)).To(Succeed())
+		Expect(widget.Object["spec"]).To(Equal(map[string]interface{}{"size": "small", "replicas": int64(1)}))
+
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(widget), widget)).To(Succeed())
+		Expect(widget.Object["spec"]).To(Equal(map[string]interface{}{"size": "small", "replicas": int64(1)}))
+	})
+
+	It("should default initial objects", func() {
+		cl = fake.NewClientBuilder().
+			WithObjectSchemas(load(filepath.Join("testdata", "crds", "example.com_widgets.yaml"))).
+			WithObjects(newWidget("widget", map[string]interface{}{})).
+			Build()
+
+		widget := newWidget("widget", nil)
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(widget), widget)).To(Succeed())
+		Expect(widget.Object["spec"]).To(Equal(map[string]interface{}{"replicas": int64(1)}))
+	})
+
+	It("should reject objects that don't match the schema", func() {
+		err := cl.Create(ctx, newWidget("widget", map[string]interface{}{"size": "medium", "replicas": int64(-1)}))
+		Expect(invalidCauses(err)).To(ConsistOf(
+			`spec.size: Unsupported value: "medium": supported values: "small", "large"`,
+			`spec.replicas: Invalid value: -1: spec.replicas in body should be greater than or equal to 0`,
+		))
+
+		err = cl.Create(ctx, newWidget("widget", map[string]interface{}{}))
+		Expect(invalidCauses(err)).To(ContainElement("spec.size: Required value"))
+	})
+
+	It("should evaluate validation rules", func() {
+		err := cl.Create(ctx, newWidget("widget", map[string]interface{}{"size": "small", "replicas": int64(3), "maxReplicas": int64(2)}))
+		Expect(invalidCauses(err)).To(ConsistOf(`spec: Invalid value: "object": replicas must not exceed maxReplicas`))
+	})
+
+	It("should evaluate transition rules on updates", func() {
+		widget := newWidget("widget", map[string]interface{}{"size": "small"})
+		Expect(cl.Create(ctx, widget)).To(Succeed())
+
+		Expect(unstructured.SetNestedField(widget.Object, "large", "spec", "size")).To(Succeed())
+		Expect(invalidCauses(cl.Update(ctx, widget))).To(ConsistOf(`spec.size: Invalid value: "string": size is immutable`))
+
+		patch := client.RawPatch(types.MergePatchType, []byte(`{"spec":{"size":"large"}}`))
+		Expect(invalidCauses(cl.Patch(ctx, newWidget("widget", nil), patch))).To(ConsistOf(`spec.size: Invalid value: "string": size is immutable`))
+
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(widget), widget)).To(Succeed())
+		Expect(widget.Object["spec"]).To(HaveKeyWithValue("size", "small"))
+	})
+
+	It("should use the status subresource of CRDs", func() {
+		widget := newWidget("widget", map[string]interface{}{"size": "small"})
+		Expect(cl.Create(ctx, widget)).To(Succeed())
+
+		Expect(unstructured.SetNestedField(widget.Object, true, "status", "ready")).To(Succeed())
+		Expect(cl.Update(ctx, widget)).To(Succeed())
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(widget), widget)).To(Succeed())
+		Expect(widget.Object).NotTo(HaveKey("status"))
+
+		Expect(unstructured.SetNestedField(widget.Object, true, "status", "ready")).To(Succeed())
+		Expect(cl.Status().Update(ctx, widget)).To(Succeed())
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(widget), widget)).To(Succeed())
+		Expect(widget.Object["status"]).To(Equal(map[string]interface{}{"ready": true}))
+	})
+
+	It("should add the kinds of CRDs to the default RESTMapper", func() {
+		mapping, err := cl.RESTMapper().RESTMapping(newWidget("widget", nil).GroupVersionKind().GroupKind(), "v1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.Resource.Resource).To(Equal("widgets"))
+		Expect(mapping.Scope.Name()).To(Equal(meta.RESTScopeNameNamespace))
+	})
+
+	It("should not replace the RESTMapper the client is built with", func() {
+		restMapper := meta.NewDefaultRESTMapper(nil)
+		cl = fake.NewClientBuilder().WithRESTMapper(restMapper).WithObjectSchemas(load(filepath.Join("testdata", "crds"))).Build()
+		Expect(cl.RESTMapper()).To(BeIdenticalTo(restMapper))
+	})
+
+	It("should return an error if a path doesn't exist", func() {
+		_, err := crdschema.Load(filepath.Join("testdata", "missing"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if a schema is not structural", func() {
+		_, err := crdschema.New(&apiextensionsv1.CustomResourceDefinition{
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "example.com",
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Gadget", Plural: "gadgets"},
+				Scope: apiextensionsv1.NamespaceScoped,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name:    "v1",
+					Served:  true,
+					Storage: true,
+					Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+						Type:       "object",
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{"spec": {}},
+					}},
+				}},
+			},
+		})
+		Expect(err).To(MatchError(ContainSubstring("not structural")))
+	})
+})
@@ -24,6 +24,45 @@ You can create a fake client with optional objects.
 
 You can invoke the methods defined in the Client interface.
 
+Server-side apply is implemented with the same field manager the API server uses. The
+managedFields of objects are tracked for all writes, but they are only returned if the
+client was built WithReturnManagedFields. The schema used for merging is derived from the
+Go types registered in the scheme, so lists are only merged by key if their field has a
+`patchStrategy:"merge"` tag. Kinds that are not registered in the scheme are merged like
+CRDs without a schema.
+
+Like in the API server, strategic merge patches are only supported for built-in types. They
+are rejected with an UnsupportedMediaType error for unstructured objects and CRDs.
+
+Defaulting and validating webhooks can be registered WithDefaulter, WithValidator and
+WithAdmissionHandler. The client calls them on
+writes the same way the API server calls admission webhooks, so the logic of webhooks and
+controllers can be tested together.
+
+Clients built WithGarbageCollection delete the dependents of deleted objects according to
+their ownerReferences and the propagation policy of the delete, like the garbage collector
+of a cluster does.
+
+To assert which calls a test made, e.g. that a reconcile doesn't write anything in steady
+state, build the client WithActionRecorder.
+
+List honors the Limit and Continue options and returns items in pages ordered by namespace
+and name. WithContinueTokenExpiry makes continue tokens expire, so code that restarts a list
+on a 410 Gone error can be tested.
+
+Custom resources are not validated unless the client is built WithObjectSchemas. The
+crdschema package loads CRDs from the same paths as envtest and implements ObjectSchemas, so
+writes of their kinds are validated against the schemas and x-kubernetes-validations rules of
+the CRDs, and unknown fields are pruned and defaults are applied.
+
+The clients built by ClientBuilder implement Snapshotter, so tests can take a Snapshot of all
+objects and Restore it later. The YAML of a snapshot is normalized to be compared with golden
+files.
+
+Watch honors label and field selectors like List does. To run controllers end to end against
+a fake client, create the cache of the manager with cache.NewFromClient, which feeds informers,
+indexes and sources from the watches of the client.
+
 When in doubt, it's almost always better not to use this package and instead use
 envtest.Environment with a real client and API server.
 
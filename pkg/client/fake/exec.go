@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/podexec"
+)
+
+// ExecRequest is a command run by an Executor returned by NewExecutor.
+type ExecRequest struct {
+	// Pod is the pod the command runs in.
+	Pod *corev1.Pod
+	// SubResource is either "exec" or "attach".
+	SubResource string
+	// Container is the container the command runs in.
+	Container string
+	// Command is the command to run. It is empty for attach.
+	Command []string
+	// Streams are the streams connected to the command.
+	Streams podexec.Streams
+}
+
+// ExecFunc runs the command of an ExecRequest. It should write the output of
+// the command to the streams of the request and return an error to fail the
+// command, e.g. a k8s.io/client-go/util/exec.CodeExitError.
+type ExecFunc func(ctx context.Context, req ExecRequest) error
+
+// NewExecutor returns a podexec.Executor that runs commands by calling
+// execFunc instead of running them in containers. The pods are read from c,
+// which is typically a fake client, and the container must exist in the pod.
+// If execFunc is nil, all commands succeed without output.
+func NewExecutor(c client.Reader, execFunc ExecFunc) podexec.Executor {
+	return &executor{reader: c, execFunc: execFunc}
+}
+
+type executor struct {
+	reader   client.Reader
+	execFunc ExecFunc
+}
+
+// Exec implements podexec.Executor.
+func (e *executor) Exec(ctx context.Context, pod client.Object, opts *corev1.PodExecOptions, streams podexec.Streams) error {
+	return e.run(ctx, pod, ExecRequest{SubResource: "exec", Container: opts.Container, Command: opts.Command, Streams: streams})
+}
+
+// Attach implements podexec.Executor.
+func (e *executor) Attach(ctx context.Context, pod client.Object, opts *corev1.PodAttachOptions, streams podexec.Streams) error {
+	return e.run(ctx, pod, ExecRequest{SubResource: "attach", Container: opts.Container, Streams: streams})
+}
+
+func (e *executor) run(ctx context.Context, pod client.Object, req ExecRequest) error {
+	req.Pod = &corev1.Pod{}
+	if err := e.reader.Get(ctx, client.ObjectKeyFromObject(pod), req.Pod); err != nil {
+		return err
+	}
+
+	// Like the API server, default to the only container of the pod.
+	if req.Container == "" {
+		if len(req.Pod.Spec.Containers) != 1 {
+			return apierrors.NewBadRequest(fmt.Sprintf("a container name must be specified for pod %s", req.Pod.Name))
+		}
+		req.Container = req.Pod.Spec.Containers[0].Name
+	}
+	found := false
+	for _, container := range req.Pod.Spec.Containers {
+		if container.Name == req.Container {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return apierrors.NewBadRequest(fmt.Sprintf("container %s is not valid for pod %s", req.Container, req.Pod.Name))
+	}
+
+	if e.execFunc == nil {
+		return nil
+	}
+	return e.execFunc(ctx, req)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/exec"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/podexec"
+)
+
+var _ = Describe("Fake executor", func() {
+	ctx := context.Background()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}
+
+	It("should run commands with the exec function", func() {
+		var requests []ExecRequest
+		executor := NewExecutor(NewClientBuilder().WithObjects(pod.DeepCopy()).Build(), func(ctx context.Context, req ExecRequest) error {
+			requests = append(requests, req)
+			if req.SubResource == "attach" {
+				return exec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 2"), Code: 2}
+			}
+			_, err := fmt.Fprintf(req.Streams.Stdout, "%s %s", strings.Join(req.Command, " "), req.Pod.Name)
+			return err
+		})
+
+		stdout := &bytes.Buffer{}
+		Expect(executor.Exec(ctx, pod, &corev1.PodExecOptions{Command: []string{"echo", "hello"}}, podexec.Streams{Stdout: stdout})).To(Succeed())
+		Expect(stdout.String()).To(Equal("echo hello pod"))
+
+		err := executor.Attach(ctx, pod, &corev1.PodAttachOptions{Container: "app"}, podexec.Streams{})
+		var exitErr exec.ExitError
+		Expect(errors.As(err, &exitErr)).To(BeTrue())
+		Expect(exitErr.ExitStatus()).To(Equal(2))
+
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0].SubResource).To(Equal("exec"))
+		Expect(requests[0].Container).To(Equal("app"))
+		Expect(requests[1].SubResource).To(Equal("attach"))
+		Expect(requests[1].Command).To(BeEmpty())
+	})
+
+	It("should fail for pods and containers that don't exist", func() {
+		executor := NewExecutor(NewClientBuilder().WithObjects(pod.DeepCopy()).Build(), nil)
+
+		err := executor.Exec(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}, &corev1.PodExecOptions{}, podexec.Streams{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		err = executor.Exec(ctx, pod, &corev1.PodExecOptions{Container: "sidecar"}, podexec.Streams{})
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+
+		Expect(executor.Exec(ctx, pod, &corev1.PodExecOptions{Container: "app"}, podexec.Streams{})).To(Succeed())
+	})
+})
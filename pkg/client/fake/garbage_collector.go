@@ -0,0 +1,404 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// WithGarbageCollection enables garbage collection of objects whose owners
+// were deleted, like the garbage collector of kube-controller-manager does it.
+//
+// Deletes honor the PropagationPolicy of their options:
+//   - Background, the default, deletes the object and then its dependents.
+//   - Foreground adds the foregroundDeletion finalizer to the object, which is
+//     removed once all dependents whose ownerReference sets
+//     blockOwnerDeletion are deleted.
+//   - Orphan adds the orphan finalizer to the object, which is removed once the
+//     ownerReferences to the object are removed from its dependents.
+//
+// Garbage is collected synchronously after every write that deletes an object
+// or changes the ownerReferences or finalizers of one, so dependents are gone
+// when the request that deleted their owner returns. Like in a cluster, objects
+// whose owners don't exist are deleted as well. Only objects of kinds that were
+// added through the builder or the client are collected. The write succeeds
+// even if collecting garbage fails; the error is logged instead.
+func (f *ClientBuilder) WithGarbageCollection() *ClientBuilder {
+	f.garbageCollection = true
+	return f
+}
+
+// trackedKinds records the kinds of the objects added to the tracker, so the
+// garbage collector can find all objects.
+type trackedKinds struct {
+	lock      sync.Mutex
+	resources map[schema.GroupVersionKind]schema.GroupVersionResource
+}
+
+func newTrackedKinds() *trackedKinds {
+	return &trackedKinds{resources: map[schema.GroupVersionKind]schema.GroupVersionResource{}}
+}
+
+// add records the kind of the given resource.
+func (k *trackedKinds) add(gvk schema.GroupVersionKind, gvr schema.GroupVersionResource) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.resources[gvk] = gvr
+}
+
+// all returns all recorded kinds and their resources.
+func (k *trackedKinds) all() map[schema.GroupVersionKind]schema.GroupVersionResource {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	resources := make(map[schema.GroupVersionKind]schema.GroupVersionResource, len(k.resources))
+	for gvk, gvr := range k.resources {
+		resources[gvk] = gvr
+	}
+	return resources
+}
+
+// gcObject is an object the garbage collector considers.
+type gcObject struct {
+	gvk    schema.GroupVersionKind
+	gvr    schema.GroupVersionResource
+	object runtime.Object
+	meta   metav1.Object
+}
+
+// waitingForDependents returns whether the object is deleted in the
+// foreground and waits for its dependents to be deleted.
+func (o *gcObject) waitingForDependents() bool {
+	return o.meta.GetDeletionTimestamp() != nil && sets.New(o.meta.GetFinalizers()...).Has(metav1.FinalizerDeleteDependents)
+}
+
+// ownerKey identifies an owner within the namespace of its dependents.
+type ownerKey struct {
+	groupKind schema.GroupKind
+	namespace string
+	name      string
+}
+
+// gcGraph are all objects and their ownership relations.
+type gcGraph struct {
+	objects []*gcObject
+	byKey   map[ownerKey]*gcObject
+	// dependents maps each owner to the objects it owns.
+	dependents map[*gcObject][]*gcObject
+}
+
+// owner returns the owner the given reference of dependent refers to, or nil
+// if it doesn't exist.
+func (g *gcGraph) owner(dependent *gcObject, ref metav1.OwnerReference) *gcObject {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil
+	}
+	groupKind := schema.GroupKind{Group: gv.Group, Kind: ref.Kind}
+
+	// Owners are either in the namespace of the dependent or cluster-scoped.
+	owner := g.byKey[ownerKey{groupKind: groupKind, namespace: dependent.meta.GetNamespace(), name: ref.Name}]
+	if owner == nil {
+		owner = g.byKey[ownerKey{groupKind: groupKind, name: ref.Name}]
+	}
+	if owner == nil {
+		return nil
+	}
+	// The fake client doesn't set UIDs, so only compare them if both are set.
+	if ref.UID != "" && owner.meta.GetUID() != "" && ref.UID != owner.meta.GetUID() {
+		return nil
+	}
+	return owner
+}
+
+// trackedObject is an object in the tracker and its kind.
+type trackedObject struct {
+	gvk    schema.GroupVersionKind
+	gvr    schema.GroupVersionResource
+	object runtime.Object
+}
+
+// trackedObjects lists the objects of all kinds in the tracker, ordered by kind.
+func (c *fakeClient) trackedObjects() ([]trackedObject, error) {
+	resources := c.tracker.kinds.all()
+	gvks := make([]schema.GroupVersionKind, 0, len(resources))
+	for gvk := range resources {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool { return gvks[i].String() < gvks[j].String() })
+
+	var objects []trackedObject
+	for _, gvk := range gvks {
+		listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+		if !c.scheme.Recognizes(listGVK) {
+			c.schemeWriteLock.Lock()
+			c.scheme.AddKnownTypeWithName(listGVK, &unstructured.UnstructuredList{})
+			c.schemeWriteLock.Unlock()
+		}
+		list, err := c.tracker.List(resources[gvk], gvk, "")
+		if err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			objects = append(objects, trackedObject{gvk: gvk, gvr: resources[gvk], object: item})
+		}
+	}
+	return objects, nil
+}
+
+// graph lists all objects and resolves their owners.
+func (c *fakeClient) graph() (*gcGraph, error) {
+	g := &gcGraph{byKey: map[ownerKey]*gcObject{}, dependents: map[*gcObject][]*gcObject{}}
+
+	// Objects are listed in a stable order, so garbage is collected in one, too.
+	objects, err := c.trackedObjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		accessor, err := meta.Accessor(o.object)
+		if err != nil {
+			return nil, err
+		}
+		obj := &gcObject{gvk: o.gvk, gvr: o.gvr, object: o.object, meta: accessor}
+		g.objects = append(g.objects, obj)
+		g.byKey[ownerKey{groupKind: o.gvk.GroupKind(), namespace: accessor.GetNamespace(), name: accessor.GetName()}] = obj
+	}
+
+	for _, obj := range g.objects {
+		for _, ref := range obj.meta.GetOwnerReferences() {
+			if owner := g.owner(obj, ref); owner != nil {
+				g.dependents[owner] = append(g.dependents[owner], obj)
+			}
+		}
+	}
+	return g, nil
+}
+
+// markGarbage records that garbage might have to be collected after obj was
+// written, i.e. if it was created with ownerReferences, deleted, or its
+// ownerReferences or finalizers changed. oldObj is nil for new objects.
+func (t versionedTracker) markGarbage(oldObj, obj metav1.Object) {
+	if oldObj == nil {
+		if len(obj.GetOwnerReferences()) > 0 || obj.GetDeletionTimestamp() != nil {
+			t.garbage.Store(true)
+		}
+		return
+	}
+	if !reflect.DeepEqual(oldObj.GetOwnerReferences(), obj.GetOwnerReferences()) ||
+		!sets.New(oldObj.GetFinalizers()...).Equal(sets.New(obj.GetFinalizers()...)) ||
+		!deletionTimestampEqual(oldObj, obj) {
+		t.garbage.Store(true)
+	}
+}
+
+// collectGarbage deletes and updates objects until no owner or dependent is
+// left that the garbage collector would act on. It only does so if a write
+// might have left garbage since it last ran, see markGarbage. It doesn't fail,
+// as the write that left the garbage already succeeded; errors are logged.
+func (c *fakeClient) collectGarbage(ctx context.Context) {
+	if !c.garbageCollection {
+		return
+	}
+	c.garbageCollectionLock.Lock()
+	defer c.garbageCollectionLock.Unlock()
+
+	// Every change the garbage collector makes marks garbage again, so this
+	// runs until a pass finds nothing to do.
+	for c.tracker.garbage.Swap(false) {
+		g, err := c.graph()
+		if err == nil {
+			err = c.collectGarbageOnce(g)
+		}
+		switch {
+		case err == nil:
+		case apierrors.IsConflict(err) || apierrors.IsNotFound(err):
+			// Another write changed the object since it was listed, so list
+			// the objects again.
+			c.tracker.garbage.Store(true)
+		default:
+			// Try again after the next write.
+			c.tracker.garbage.Store(true)
+			log.FromContext(ctx).Error(err, "Failed to collect garbage")
+			return
+		}
+	}
+}
+
+// collectGarbageOnce makes the first change the garbage collector would make
+// to the objects of g, if any.
+func (c *fakeClient) collectGarbageOnce(g *gcGraph) error {
+	// Finish the deletion of owners that are waiting for their dependents.
+	for _, owner := range g.objects {
+		if owner.meta.GetDeletionTimestamp() == nil {
+			continue
+		}
+		finalizers := sets.New(owner.meta.GetFinalizers()...)
+		switch {
+		case finalizers.Has(metav1.FinalizerOrphanDependents):
+			for _, dependent := range g.dependents[owner] {
+				if err := c.removeOwnerReferences(g, dependent, owner); err != nil {
+					return err
+				}
+			}
+			return c.removeFinalizer(owner, metav1.FinalizerOrphanDependents)
+		case finalizers.Has(metav1.FinalizerDeleteDependents) && !blocksDeletion(g, owner):
+			return c.removeFinalizer(owner, metav1.FinalizerDeleteDependents)
+		}
+	}
+
+	// Delete dependents whose owners are gone or are deleted in the foreground.
+	for _, dependent := range g.objects {
+		refs := dependent.meta.GetOwnerReferences()
+		if len(refs) == 0 || dependent.meta.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		var solid, waiting int
+		for _, ref := range refs {
+			owner := g.owner(dependent, ref)
+			switch {
+			case owner == nil:
+			case owner.waitingForDependents():
+				waiting++
+			default:
+				solid++
+			}
+		}
+		switch {
+		case solid == len(refs):
+			continue
+		case solid > 0:
+			// Keep the dependent, but remove the references to owners that are gone.
+			return c.removeOwnerReferences(g, dependent, nil)
+		}
+
+		policy := metav1.DeletePropagationBackground
+		if waiting > 0 && len(g.dependents[dependent]) > 0 {
+			policy = metav1.DeletePropagationForeground
+		}
+		return c.deleteObject(dependent.gvr, dependent.meta, &client.DeleteOptions{PropagationPolicy: &policy})
+	}
+	return nil
+}
+
+// blocksDeletion returns whether owner has dependents that block its
+// deletion in the foreground.
+func blocksDeletion(g *gcGraph, owner *gcObject) bool {
+	for _, dependent := range g.dependents[owner] {
+		for _, ref := range dependent.meta.GetOwnerReferences() {
+			if g.owner(dependent, ref) == owner && ref.BlockOwnerDeletion != nil && *ref.BlockOwnerDeletion {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeOwnerReferences removes the references to owner from dependent. If
+// owner is nil, the references to all owners that are gone or are deleted in
+// the foreground are removed.
+func (c *fakeClient) removeOwnerReferences(g *gcGraph, dependent, owner *gcObject) error {
+	var refs []metav1.OwnerReference
+	for _, ref := range dependent.meta.GetOwnerReferences() {
+		refOwner := g.owner(dependent, ref)
+		if owner != nil && refOwner != owner {
+			refs = append(refs, ref)
+			continue
+		}
+		if owner == nil && refOwner != nil && !refOwner.waitingForDependents() {
+			refs = append(refs, ref)
+		}
+	}
+	dependent.meta.SetOwnerReferences(refs)
+	return c.tracker.update(dependent.gvr, dependent.object, dependent.meta.GetNamespace(), false, false, "")
+}
+
+// removeFinalizer removes the given finalizer from obj, which deletes it if
+// it was the last one.
+func (c *fakeClient) removeFinalizer(obj *gcObject, finalizer string) error {
+	var remaining []string
+	for _, f := range obj.meta.GetFinalizers() {
+		if f != finalizer {
+			remaining = append(remaining, f)
+		}
+	}
+	obj.meta.SetFinalizers(remaining)
+	return c.tracker.update(obj.gvr, obj.object, obj.meta.GetNamespace(), false, false, "")
+}
+
+// setDeletionFinalizers adds and removes the finalizers of the garbage
+// collector on an object that is deleted with the given options, like the API
+// server does.
+func setDeletionFinalizers(obj metav1.Object, opts *client.DeleteOptions) {
+	deleteOptions := opts.AsDeleteOptions()
+
+	var orphan, foreground bool
+	switch {
+	case deleteOptions.PropagationPolicy != nil:
+		orphan = *deleteOptions.PropagationPolicy == metav1.DeletePropagationOrphan
+		foreground = *deleteOptions.PropagationPolicy == metav1.DeletePropagationForeground
+	case deleteOptions.OrphanDependents != nil:
+		orphan = *deleteOptions.OrphanDependents
+	default:
+		// Keep the policy of a previous delete.
+		return
+	}
+
+	var finalizers []string
+	for _, f := range obj.GetFinalizers() {
+		if f != metav1.FinalizerOrphanDependents && f != metav1.FinalizerDeleteDependents {
+			finalizers = append(finalizers, f)
+		}
+	}
+	if orphan {
+		finalizers = append(finalizers, metav1.FinalizerOrphanDependents)
+	}
+	if foreground {
+		finalizers = append(finalizers, metav1.FinalizerDeleteDependents)
+	}
+	obj.SetFinalizers(finalizers)
+}
+
+// recordKind records the kind of obj in the tracker, unless it can't be
+// determined.
+func (t versionedTracker) recordKind(obj runtime.Object) {
+	gvk, err := apiutil.GVKForObject(obj, t.scheme)
+	if err != nil {
+		return
+	}
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	t.kinds.add(gvk, gvr)
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Fake client garbage collection", func() {
+	var (
+		ctx        context.Context
+		cl         client.WithWatch
+		deployment *appsv1.Deployment
+		replicaSet *appsv1.ReplicaSet
+		pod        *corev1.Pod
+	)
+
+	ownerRef := func(apiVersion, kind, name string, block bool) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: apiVersion, Kind: kind, Name: name, BlockOwnerDeletion: pointer.Bool(block)}
+	}
+	exists := func(obj client.Object) bool {
+		err := cl.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		deployment = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+		replicaSet = &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-1",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{ownerRef("apps/v1", "Deployment", "app", true)},
+		}}
+		pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-1-a",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{ownerRef("apps/v1", "ReplicaSet", "app-1", true)},
+		}}
+		cl = NewClientBuilder().WithObjects(deployment, replicaSet, pod).WithGarbageCollection().Build()
+	})
+
+	It("should delete dependents in the background by default", func() {
+		Expect(cl.Delete(ctx, deployment)).To(Succeed())
+		Expect(exists(deployment)).To(BeFalse())
+		Expect(exists(replicaSet)).To(BeFalse())
+		Expect(exists(pod)).To(BeFalse())
+	})
+
+	It("should delete dependents of all objects deleted by DeleteAllOf", func() {
+		Expect(cl.DeleteAllOf(ctx, &appsv1.Deployment{}, client.InNamespace("default"))).To(Succeed())
+		Expect(exists(replicaSet)).To(BeFalse())
+		Expect(exists(pod)).To(BeFalse())
+	})
+
+	It("should keep dependents that have other owners", func() {
+		other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+		Expect(cl.Create(ctx, other)).To(Succeed())
+		Expect(exists(replicaSet)).To(BeTrue())
+		replicaSet.OwnerReferences = append(replicaSet.OwnerReferences, ownerRef("v1", "ConfigMap", "other", false))
+		Expect(cl.Update(ctx, replicaSet)).To(Succeed())
+
+		Expect(cl.Delete(ctx, deployment)).To(Succeed())
+		Expect(exists(replicaSet)).To(BeTrue())
+		Expect(replicaSet.OwnerReferences).To(Equal([]metav1.OwnerReference{ownerRef("v1", "ConfigMap", "other", false)}))
+		Expect(exists(pod)).To(BeTrue())
+	})
+
+	It("should wait for dependents that block the deletion of their owner in the foreground", func() {
+		pod.Finalizers = []string{"example.com/finalizer"}
+		Expect(cl.Update(ctx, pod)).To(Succeed())
+
+		Expect(cl.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationForeground))).To(Succeed())
+		Expect(exists(pod)).To(BeTrue())
+		Expect(pod.DeletionTimestamp).NotTo(BeNil())
+		Expect(exists(replicaSet)).To(BeTrue())
+		Expect(replicaSet.Finalizers).To(ConsistOf(metav1.FinalizerDeleteDependents))
+		Expect(exists(deployment)).To(BeTrue())
+		Expect(deployment.Finalizers).To(ConsistOf(metav1.FinalizerDeleteDependents))
+		Expect(deployment.DeletionTimestamp).NotTo(BeNil())
+
+		pod.Finalizers = nil
+		Expect(cl.Update(ctx, pod)).To(Succeed())
+		Expect(exists(pod)).To(BeFalse())
+		Expect(exists(replicaSet)).To(BeFalse())
+		Expect(exists(deployment)).To(BeFalse())
+	})
+
+	It("should not wait for dependents that don't block the deletion of their owner", func() {
+		replicaSet.OwnerReferences = []metav1.OwnerReference{ownerRef("apps/v1", "Deployment", "app", false)}
+		replicaSet.Finalizers = []string{"example.com/finalizer"}
+		Expect(cl.Update(ctx, replicaSet)).To(Succeed())
+
+		Expect(cl.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationForeground))).To(Succeed())
+		Expect(exists(deployment)).To(BeFalse())
+		Expect(exists(replicaSet)).To(BeTrue())
+		Expect(replicaSet.DeletionTimestamp).NotTo(BeNil())
+	})
+
+	It("should orphan dependents", func() {
+		Expect(cl.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationOrphan))).To(Succeed())
+		Expect(exists(deployment)).To(BeFalse())
+		Expect(exists(replicaSet)).To(BeTrue())
+		Expect(replicaSet.OwnerReferences).To(BeEmpty())
+		Expect(exists(pod)).To(BeTrue())
+	})
+
+	It("should keep the orphan finalizer until other finalizers are removed", func() {
+		deployment.Finalizers = []string{"example.com/finalizer"}
+		Expect(cl.Update(ctx, deployment)).To(Succeed())
+
+		Expect(cl.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationOrphan))).To(Succeed())
+		Expect(exists(deployment)).To(BeTrue())
+		Expect(deployment.Finalizers).To(Equal([]string{"example.com/finalizer"}))
+		Expect(exists(replicaSet)).To(BeTrue())
+		Expect(replicaSet.OwnerReferences).To(BeEmpty())
+
+		deployment.Finalizers = nil
+		Expect(cl.Update(ctx, deployment)).To(Succeed())
+		Expect(exists(deployment)).To(BeFalse())
+		Expect(exists(replicaSet)).To(BeTrue())
+	})
+
+	It("should delete dependents only once their owner is gone", func() {
+		deployment.Finalizers = []string{"example.com/finalizer"}
+		Expect(cl.Update(ctx, deployment)).To(Succeed())
+
+		Expect(cl.Delete(ctx, deployment)).To(Succeed())
+		Expect(exists(deployment)).To(BeTrue())
+		Expect(exists(replicaSet)).To(BeTrue())
+
+		deployment.Finalizers = nil
+		Expect(cl.Update(ctx, deployment)).To(Succeed())
+		Expect(exists(replicaSet)).To(BeFalse())
+		Expect(exists(pod)).To(BeFalse())
+	})
+
+	It("should compare the UIDs of owners", func() {
+		deployment.UID = "new-uid"
+		Expect(cl.Update(ctx, deployment)).To(Succeed())
+		replicaSet.OwnerReferences[0].UID = "old-uid"
+		Expect(cl.Update(ctx, replicaSet)).To(Succeed())
+
+		Expect(exists(replicaSet)).To(BeFalse())
+		Expect(exists(deployment)).To(BeTrue())
+	})
+
+	It("should collect unstructured objects", func() {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("example.com/v1")
+		u.SetKind("Widget")
+		u.SetName("widget")
+		u.SetNamespace("default")
+		u.SetOwnerReferences([]metav1.OwnerReference{ownerRef("apps/v1", "Deployment", "app", false)})
+		Expect(cl.Create(ctx, u)).To(Succeed())
+
+		Expect(cl.Delete(ctx, deployment)).To(Succeed())
+		Expect(apierrors.IsNotFound(cl.Get(ctx, client.ObjectKeyFromObject(u), u))).To(BeTrue())
+	})
+
+	It("should only collect garbage after writes that delete objects or change their owners or finalizers", func() {
+		tracker := &gcTestTracker{ObjectTracker: testing.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())}
+		cl = NewClientBuilder().WithObjectTracker(tracker).WithObjects(deployment, replicaSet, pod).WithGarbageCollection().Build()
+
+		By("collecting the garbage of the initial objects after the first write")
+		deployment.Labels = map[string]string{"app": "app"}
+		Expect(cl.Update(ctx, deployment)).To(Succeed())
+		Expect(tracker.lists).NotTo(BeZero())
+
+		tracker.lists = 0
+		deployment.Labels["tier"] = "backend"
+		Expect(cl.Update(ctx, deployment)).To(Succeed())
+		Expect(cl.Patch(ctx, pod, client.RawPatch(types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"app"}}}`)))).To(Succeed())
+		Expect(tracker.lists).To(BeZero())
+
+		replicaSet.Finalizers = []string{"example.com/finalizer"}
+		Expect(cl.Update(ctx, replicaSet)).To(Succeed())
+		Expect(tracker.lists).NotTo(BeZero())
+
+		tracker.lists = 0
+		Expect(cl.Delete(ctx, deployment)).To(Succeed())
+		Expect(tracker.lists).NotTo(BeZero())
+	})
+
+	It("should list the objects again if collecting garbage conflicts with another write", func() {
+		conflicts := 0
+		tracker := &gcTestTracker{
+			ObjectTracker: testing.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder()),
+			updateErr: func(obj runtime.Object) error {
+				if obj.(client.Object).GetName() != replicaSet.Name || conflicts > 0 {
+					return nil
+				}
+				conflicts++
+				return apierrors.NewConflict(appsv1.Resource("replicasets"), replicaSet.Name, errors.New("object was modified"))
+			},
+		}
+		cl = NewClientBuilder().WithObjectTracker(tracker).WithObjects(deployment, replicaSet).WithGarbageCollection().Build()
+
+		Expect(cl.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationOrphan))).To(Succeed())
+		Expect(conflicts).To(Equal(1))
+		Expect(exists(deployment)).To(BeFalse())
+		Expect(exists(replicaSet)).To(BeTrue())
+		Expect(replicaSet.OwnerReferences).To(BeEmpty())
+	})
+
+	It("should not fail writes if collecting garbage fails", func() {
+		tracker := &gcTestTracker{
+			ObjectTracker: testing.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder()),
+			updateErr: func(obj runtime.Object) error {
+				if obj.(client.Object).GetName() == replicaSet.Name {
+					return apierrors.NewInternalError(errors.New("storage is down"))
+				}
+				return nil
+			},
+		}
+		cl = NewClientBuilder().WithObjectTracker(tracker).WithObjects(deployment, replicaSet).WithGarbageCollection().Build()
+
+		Expect(cl.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationOrphan))).To(Succeed())
+		Expect(exists(deployment)).To(BeTrue())
+		Expect(deployment.DeletionTimestamp).NotTo(BeNil())
+		Expect(exists(replicaSet)).To(BeTrue())
+		Expect(replicaSet.OwnerReferences).To(HaveLen(1))
+	})
+
+	It("should not collect garbage if it is not enabled", func() {
+		cl = NewClientBuilder().WithObjects(deployment, replicaSet).Build()
+		Expect(cl.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationForeground))).To(Succeed())
+		Expect(exists(deployment)).To(BeFalse())
+		Expect(exists(replicaSet)).To(BeTrue())
+	})
+})
+
+// gcTestTracker counts the lists of an ObjectTracker and fails updates with
+// the errors returned by updateErr.
+type gcTestTracker struct {
+	testing.ObjectTracker
+	lists     int
+	updateErr func(obj runtime.Object) error
+}
+
+func (t *gcTestTracker) List(gvr schema.GroupVersionResource, gvk schema.GroupVersionKind, ns string) (runtime.Object, error) {
+	t.lists++
+	return t.ObjectTracker.List(gvr, gvk, ns)
+}
+
+func (t *gcTestTracker) Update(gvr schema.GroupVersionResource, obj runtime.Object, ns string) error {
+	if t.updateErr != nil {
+		if err := t.updateErr(obj); err != nil {
+			return err
+		}
+	}
+	return t.ObjectTracker.Update(gvr, obj, ns)
+}
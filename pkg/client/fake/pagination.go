@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WithContinueTokenExpiry sets a function that is called for every List
+// request with a continue token. If it returns true, the request fails with
+// the 410 ResourceExpired error the API server returns for continue tokens
+// that are too old, e.g. to test that pagination starts over.
+func (f *ClientBuilder) WithContinueTokenExpiry(expired func(gvk schema.GroupVersionKind) bool) *ClientBuilder {
+	f.continueTokenExpiry = expired
+	return f
+}
+
+// continueToken is the content of the continue tokens of the fake client. It
+// is the same as the one of the API server, so the tokens look alike.
+type continueToken struct {
+	APIVersion      string `json:"v"`
+	ResourceVersion int64  `json:"rv"`
+	StartKey        string `json:"start"`
+}
+
+// itemKey returns the key items of lists are sorted and paginated by.
+func itemKey(obj runtime.Object) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	return accessor.GetNamespace() + "/" + accessor.GetName(), nil
+}
+
+// paginate limits list to the page the Limit and Continue options of a List
+// request select, like the API server does. Items are returned in the order
+// of their namespace and name. If the items were filtered by selectors, the
+// RemainingItemCount is not set.
+func (c *fakeClient) paginate(gvk schema.GroupVersionKind, list client.ObjectList, limit int64, continueValue string, filtered bool) error {
+	if limit <= 0 && continueValue == "" {
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	keys := make(map[runtime.Object]string, len(items))
+	for _, item := range items {
+		if keys[item], err = itemKey(item); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return keys[items[i]] < keys[items[j]] })
+
+	resourceVersion, _ := strconv.ParseInt(list.GetResourceVersion(), 10, 64)
+	if continueValue != "" {
+		token, err := decodeContinueToken(continueValue)
+		if err != nil {
+			return apierrors.NewBadRequest(fmt.Sprintf("continue key is not valid: %v", err))
+		}
+		if c.continueTokenExpiry != nil && c.continueTokenExpiry(gvk) {
+			return apierrors.NewResourceExpired("The provided continue parameter is too old to display a consistent list result. You can start a new list without the continue parameter.")
+		}
+		resourceVersion = token.ResourceVersion
+		start := sort.Search(len(items), func(i int) bool { return keys[items[i]] >= token.StartKey })
+		items = items[start:]
+	}
+
+	list.SetContinue("")
+	list.SetRemainingItemCount(nil)
+	if limit > 0 && int64(len(items)) > limit {
+		remaining := int64(len(items)) - limit
+		items = items[:limit]
+		token, err := encodeContinueToken(continueToken{
+			APIVersion:      metav1.SchemeGroupVersion.Version,
+			ResourceVersion: resourceVersion,
+			StartKey:        keys[items[len(items)-1]] + "\x00",
+		})
+		if err != nil {
+			return err
+		}
+		list.SetContinue(token)
+		if !filtered {
+			list.SetRemainingItemCount(&remaining)
+		}
+	}
+	return meta.SetList(list, items)
+}
+
+func encodeContinueToken(token continueToken) (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeContinueToken(value string) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	token := &continueToken{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+	if token.APIVersion != metav1.SchemeGroupVersion.Version || token.StartKey == "" {
+		return nil, fmt.Errorf("unsupported continue token %q", value)
+	}
+	return token, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Fake client pagination", func() {
+	var (
+		ctx  context.Context
+		objs []client.Object
+	)
+
+	names := func(list *corev1.ConfigMapList) []string {
+		var names []string
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		objs = nil
+		for _, name := range []string{"e", "c", "a", "d", "b"} {
+			objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"odd": fmt.Sprint(name == "a" || name == "c" || name == "e")},
+			}})
+		}
+	})
+
+	It("should return pages of the given limit", func() {
+		cl := NewClientBuilder().WithObjects(objs...).Build()
+
+		list := &corev1.ConfigMapList{}
+		Expect(cl.List(ctx, list, client.Limit(2))).To(Succeed())
+		Expect(names(list)).To(Equal([]string{"a", "b"}))
+		Expect(list.Continue).NotTo(BeEmpty())
+		Expect(list.RemainingItemCount).To(Equal(pointer.Int64(3)))
+
+		Expect(cl.List(ctx, list, client.Limit(2), client.Continue(list.Continue))).To(Succeed())
+		Expect(names(list)).To(Equal([]string{"c", "d"}))
+		Expect(list.RemainingItemCount).To(Equal(pointer.Int64(1)))
+
+		Expect(cl.List(ctx, list, client.Limit(2), client.Continue(list.Continue))).To(Succeed())
+		Expect(names(list)).To(Equal([]string{"e"}))
+		Expect(list.Continue).To(BeEmpty())
+		Expect(list.RemainingItemCount).To(BeNil())
+	})
+
+	It("should return everything without a limit", func() {
+		cl := NewClientBuilder().WithObjects(objs...).Build()
+
+		list := &corev1.ConfigMapList{}
+		Expect(cl.List(ctx, list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(5))
+		Expect(list.Continue).To(BeEmpty())
+	})
+
+	It("should not return a remaining item count for filtered lists", func() {
+		cl := NewClientBuilder().WithObjects(objs...).Build()
+
+		list := &corev1.ConfigMapList{}
+		Expect(cl.List(ctx, list, client.MatchingLabels{"odd": "true"}, client.Limit(2))).To(Succeed())
+		Expect(names(list)).To(Equal([]string{"a", "c"}))
+		Expect(list.Continue).NotTo(BeEmpty())
+		Expect(list.RemainingItemCount).To(BeNil())
+
+		Expect(cl.List(ctx, list, client.MatchingLabels{"odd": "true"}, client.Limit(2), client.Continue(list.Continue))).To(Succeed())
+		Expect(names(list)).To(Equal([]string{"e"}))
+		Expect(list.Continue).To(BeEmpty())
+	})
+
+	It("should reject invalid continue tokens", func() {
+		cl := NewClientBuilder().WithObjects(objs...).Build()
+
+		err := cl.List(ctx, &corev1.ConfigMapList{}, client.Continue("invalid"))
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+	})
+
+	It("should return an expired error for continue tokens that are made to expire", func() {
+		var expiredKinds []schema.GroupVersionKind
+		cl := NewClientBuilder().
+			WithObjects(objs...).
+			WithContinueTokenExpiry(func(gvk schema.GroupVersionKind) bool {
+				expiredKinds = append(expiredKinds, gvk)
+				return true
+			}).
+			Build()
+
+		list := &corev1.ConfigMapList{}
+		Expect(cl.List(ctx, list, client.Limit(2))).To(Succeed())
+		Expect(expiredKinds).To(BeEmpty())
+
+		err := cl.List(ctx, list, client.Limit(2), client.Continue(list.Continue))
+		Expect(apierrors.IsResourceExpired(err)).To(BeTrue())
+		Expect(apierrors.IsGone(err)).To(BeFalse())
+		Expect(err.(apierrors.APIStatus).Status().Code).To(BeEquivalentTo(410))
+		Expect(expiredKinds).To(Equal([]schema.GroupVersionKind{corev1.SchemeGroupVersion.WithKind("ConfigMap")}))
+	})
+})
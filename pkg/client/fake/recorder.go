@@ -0,0 +1,294 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/internal/applyconfiguration"
+)
+
+// The verbs of recorded actions. They are the same as the ones of the actions
+// of k8s.io/client-go/testing.
+const (
+	VerbGet              = "get"
+	VerbList             = "list"
+	VerbWatch            = "watch"
+	VerbCreate           = "create"
+	VerbUpdate           = "update"
+	VerbPatch            = "patch"
+	VerbDelete           = "delete"
+	VerbDeleteCollection = "delete-collection"
+)
+
+// Action is a call to a client built WithActionRecorder.
+type Action struct {
+	// Verb is the verb of the call, e.g. VerbGet. Apply calls are recorded as
+	// patches with the types.ApplyPatchType.
+	Verb string
+
+	// GVK is the kind of the object. For List, Watch and DeleteAllOf it is
+	// the kind of the items.
+	GVK schema.GroupVersionKind
+
+	// Key is the namespace and name of the object. For List, Watch and
+	// DeleteAllOf only the namespace is set.
+	Key client.ObjectKey
+
+	// SubResource is the subresource the call was made for, if any.
+	SubResource string
+
+	// Options are the options of the call, e.g. a *client.GetOptions for Get
+	// or a *client.SubResourcePatchOptions for a patch of a subresource.
+	Options interface{}
+
+	// PatchType and Patch are the type and data of patches.
+	PatchType types.PatchType
+	Patch     []byte
+
+	// Object is a copy of the object or list after the call returned, i.e.
+	// the object the client returned, if any.
+	Object runtime.Object
+
+	// Err is the error the call returned.
+	Err error
+}
+
+// IsWrite returns whether the action changes objects.
+func (a Action) IsWrite() bool {
+	switch a.Verb {
+	case VerbGet, VerbList, VerbWatch:
+		return false
+	default:
+		return true
+	}
+}
+
+// ActionRecorder records the calls to a client built WithActionRecorder. It
+// is safe for concurrent use.
+type ActionRecorder struct {
+	lock    sync.Mutex
+	actions []Action
+}
+
+// NewActionRecorder returns a new ActionRecorder.
+func NewActionRecorder() *ActionRecorder {
+	return &ActionRecorder{}
+}
+
+// Actions returns all recorded actions in the order they were made.
+func (r *ActionRecorder) Actions() []Action {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Action(nil), r.actions...)
+}
+
+// ActionsFor returns the recorded actions with the given kind and verb in the
+// order they were made.
+func (r *ActionRecorder) ActionsFor(gvk schema.GroupVersionKind, verb string) []Action {
+	var actions []Action
+	for _, action := range r.Actions() {
+		if action.GVK == gvk && action.Verb == verb {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// Writes returns the recorded actions that change objects in the order they
+// were made.
+func (r *ActionRecorder) Writes() []Action {
+	var actions []Action
+	for _, action := range r.Actions() {
+		if action.IsWrite() {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// Reset removes all recorded actions.
+func (r *ActionRecorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.actions = nil
+}
+
+func (r *ActionRecorder) record(action Action) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.actions = append(r.actions, action)
+}
+
+// WithActionRecorder records all calls to the client with recorder. Calls
+// are recorded as they are made by the caller, i.e. before interceptor funcs
+// handle them.
+func (f *ClientBuilder) WithActionRecorder(recorder *ActionRecorder) *ClientBuilder {
+	f.actionRecorder = recorder
+	return f
+}
+
+// newRecordingClient returns a client that records all calls to c with
+// recorder.
+func newRecordingClient(c client.WithWatch, recorder *ActionRecorder) client.WithWatch {
+	// newAction returns an action for a call for obj, which may be a list.
+	newAction := func(verb string, obj runtime.Object, key client.ObjectKey, subResource string, options interface{}) Action {
+		gvk, _ := c.GroupVersionKindFor(obj)
+		if meta.IsListType(obj) {
+			gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+		}
+		return Action{Verb: verb, GVK: gvk, Key: key, SubResource: subResource, Options: options}
+	}
+	// finish sets the result of the call and records action.
+	finish := func(action Action, obj runtime.Object, err error) error {
+		if err == nil && obj != nil {
+			action.Object = obj.DeepCopyObject()
+		}
+		action.Err = err
+		recorder.record(action)
+		return err
+	}
+	patchAction := func(verb string, obj client.Object, patch client.Patch, subResource string, options interface{}) Action {
+		action := newAction(verb, obj, client.ObjectKeyFromObject(obj), subResource, options)
+		action.PatchType = patch.Type()
+		action.Patch, _ = patch.Data(obj)
+		return action
+	}
+	applyAction := func(obj client.ApplyConfiguration, subResource string, options interface{}) Action {
+		action := Action{Verb: VerbPatch, SubResource: subResource, Options: options, PatchType: types.ApplyPatchType}
+		if u, err := applyconfiguration.ToUnstructured(obj); err == nil {
+			action.GVK = u.GroupVersionKind()
+			action.Key = client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()}
+		}
+		action.Patch, _ = json.Marshal(obj)
+		return action
+	}
+	// applied returns the object an apply call returned in obj.
+	applied := func(obj client.ApplyConfiguration) runtime.Object {
+		u, err := applyconfiguration.ToUnstructured(obj)
+		if err != nil {
+			return nil
+		}
+		return u
+	}
+
+	return interceptor.NewClient(c, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			action := newAction(VerbGet, obj, key, "", (&client.GetOptions{}).ApplyOptions(opts))
+			return finish(action, obj, c.Get(ctx, key, obj, opts...))
+		},
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+			action := newAction(VerbList, list, client.ObjectKey{Namespace: listOpts.Namespace}, "", listOpts)
+			return finish(action, list, c.List(ctx, list, opts...))
+		},
+		Watch: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+			listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+			action := newAction(VerbWatch, list, client.ObjectKey{Namespace: listOpts.Namespace}, "", listOpts)
+			w, err := c.Watch(ctx, list, opts...)
+			return w, finish(action, nil, err)
+		},
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			createOpts := &client.CreateOptions{}
+			createOpts.ApplyOptions(opts)
+			action := newAction(VerbCreate, obj, client.ObjectKeyFromObject(obj), "", createOpts)
+			err := c.Create(ctx, obj, opts...)
+			// The name may have been generated.
+			action.Key = client.ObjectKeyFromObject(obj)
+			return finish(action, obj, err)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			updateOpts := &client.UpdateOptions{}
+			updateOpts.ApplyOptions(opts)
+			action := newAction(VerbUpdate, obj, client.ObjectKeyFromObject(obj), "", updateOpts)
+			return finish(action, obj, c.Update(ctx, obj, opts...))
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patchOpts := &client.PatchOptions{}
+			patchOpts.ApplyOptions(opts)
+			action := patchAction(VerbPatch, obj, patch, "", patchOpts)
+			return finish(action, obj, c.Patch(ctx, obj, patch, opts...))
+		},
+		Apply: func(ctx context.Context, c client.WithWatch, obj client.ApplyConfiguration, opts ...client.ApplyOption) error {
+			applyOpts := &client.ApplyOptions{}
+			applyOpts.ApplyOptions(opts)
+			action := applyAction(obj, "", applyOpts)
+			err := c.Apply(ctx, obj, opts...)
+			return finish(action, applied(obj), err)
+		},
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			deleteOpts := &client.DeleteOptions{}
+			deleteOpts.ApplyOptions(opts)
+			action := newAction(VerbDelete, obj, client.ObjectKeyFromObject(obj), "", deleteOpts)
+			return finish(action, nil, c.Delete(ctx, obj, opts...))
+		},
+		DeleteAllOf: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteAllOfOption) error {
+			deleteAllOfOpts := &client.DeleteAllOfOptions{}
+			deleteAllOfOpts.ApplyOptions(opts)
+			action := newAction(VerbDeleteCollection, obj, client.ObjectKey{Namespace: deleteAllOfOpts.Namespace}, "", deleteAllOfOpts)
+			return finish(action, nil, c.DeleteAllOf(ctx, obj, opts...))
+		},
+		SubResourceGet: func(ctx context.Context, c client.Client, subResourceName string, obj, subResource client.Object, opts ...client.SubResourceGetOption) error {
+			getOpts := &client.SubResourceGetOptions{}
+			getOpts.ApplyOptions(opts)
+			action := newAction(VerbGet, obj, client.ObjectKeyFromObject(obj), subResourceName, getOpts)
+			return finish(action, subResource, c.SubResource(subResourceName).Get(ctx, obj, subResource, opts...))
+		},
+		SubResourceStream: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceStreamOption) (io.ReadCloser, error) {
+			action := newAction(VerbGet, obj, client.ObjectKeyFromObject(obj), subResourceName, (&client.SubResourceStreamOptions{}).ApplyOptions(opts))
+			stream, err := client.StreamSubResource(ctx, c.SubResource(subResourceName), obj, opts...)
+			return stream, finish(action, nil, err)
+		},
+		SubResourceCreate: func(ctx context.Context, c client.Client, subResourceName string, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+			createOpts := &client.SubResourceCreateOptions{}
+			createOpts.ApplyOptions(opts)
+			action := newAction(VerbCreate, obj, client.ObjectKeyFromObject(obj), subResourceName, createOpts)
+			return finish(action, subResource, c.SubResource(subResourceName).Create(ctx, obj, subResource, opts...))
+		},
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			updateOpts := &client.SubResourceUpdateOptions{}
+			updateOpts.ApplyOptions(opts)
+			action := newAction(VerbUpdate, obj, client.ObjectKeyFromObject(obj), subResourceName, updateOpts)
+			return finish(action, obj, c.SubResource(subResourceName).Update(ctx, obj, opts...))
+		},
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			patchOpts := &client.SubResourcePatchOptions{}
+			patchOpts.ApplyOptions(opts)
+			action := patchAction(VerbPatch, obj, patch, subResourceName, patchOpts)
+			return finish(action, obj, c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...))
+		},
+		SubResourceApply: func(ctx context.Context, c client.Client, subResourceName string, obj client.ApplyConfiguration, opts ...client.SubResourceApplyOption) error {
+			applyOpts := &client.SubResourceApplyOptions{}
+			applyOpts.ApplyOpts(opts)
+			action := applyAction(obj, subResourceName, applyOpts)
+			err := c.SubResource(subResourceName).Apply(ctx, obj, opts...)
+			return finish(action, applied(obj), err)
+		},
+	})
+}
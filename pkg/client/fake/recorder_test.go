@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Fake client action recorder", func() {
+	var (
+		ctx      context.Context
+		recorder *ActionRecorder
+		cl       client.WithWatch
+	)
+	configMapGVK := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = NewActionRecorder()
+		cl = NewClientBuilder().
+			WithObjects(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}).
+			WithActionRecorder(recorder).
+			Build()
+	})
+
+	It("should record calls in order", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{GenerateName: "cm-", Namespace: "default"}}
+		Expect(cl.Create(ctx, cm, client.FieldOwner("test"))).To(Succeed())
+		Expect(cl.List(ctx, &corev1.ConfigMapList{}, client.InNamespace("default"))).To(Succeed())
+		err := cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "missing"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(cl.Delete(ctx, cm)).To(Succeed())
+
+		actions := recorder.Actions()
+		Expect(actions).To(HaveLen(4))
+
+		Expect(actions[0].Verb).To(Equal(VerbCreate))
+		Expect(actions[0].GVK).To(Equal(configMapGVK))
+		Expect(actions[0].Key).To(Equal(client.ObjectKeyFromObject(cm)))
+		Expect(actions[0].Options).To(Equal(&client.CreateOptions{FieldManager: "test"}))
+		Expect(actions[0].Object.(*corev1.ConfigMap).ResourceVersion).To(Equal("1"))
+		Expect(actions[0].Err).NotTo(HaveOccurred())
+
+		Expect(actions[1].Verb).To(Equal(VerbList))
+		Expect(actions[1].GVK).To(Equal(configMapGVK))
+		Expect(actions[1].Key).To(Equal(client.ObjectKey{Namespace: "default"}))
+		Expect(actions[1].Object.(*corev1.ConfigMapList).Items).To(HaveLen(2))
+
+		Expect(actions[2].Verb).To(Equal(VerbGet))
+		Expect(actions[2].Key).To(Equal(client.ObjectKey{Namespace: "default", Name: "missing"}))
+		Expect(actions[2].Object).To(BeNil())
+		Expect(apierrors.IsNotFound(actions[2].Err)).To(BeTrue())
+
+		Expect(actions[3].Verb).To(Equal(VerbDelete))
+	})
+
+	It("should record patches and applies", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}
+		patch := []byte(`{"data":{"a":"b"}}`)
+		Expect(cl.Patch(ctx, cm, client.RawPatch(types.MergePatchType, patch))).To(Succeed())
+		Expect(cl.Apply(ctx, corev1ac.ConfigMap("applied", "default").WithData(map[string]string{"c": "d"}), client.FieldOwner("test"))).To(Succeed())
+
+		patches := recorder.ActionsFor(configMapGVK, VerbPatch)
+		Expect(patches).To(HaveLen(2))
+		Expect(patches[0].PatchType).To(Equal(types.MergePatchType))
+		Expect(patches[0].Patch).To(Equal(patch))
+		Expect(patches[0].Object.(*corev1.ConfigMap).Data).To(HaveKeyWithValue("a", "b"))
+		Expect(patches[1].PatchType).To(Equal(types.ApplyPatchType))
+		Expect(patches[1].Key).To(Equal(client.ObjectKey{Namespace: "default", Name: "applied"}))
+		Expect(patches[1].Patch).To(MatchJSON(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"applied","namespace":"default"},"data":{"c":"d"}}`))
+	})
+
+	It("should record subresource calls", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+		Expect(cl.Create(ctx, pod)).To(Succeed())
+		recorder.Reset()
+
+		pod.Status.Phase = corev1.PodRunning
+		Expect(cl.Status().Update(ctx, pod)).To(Succeed())
+
+		Expect(recorder.Actions()).To(HaveLen(1))
+		Expect(recorder.Actions()[0].Verb).To(Equal(VerbUpdate))
+		Expect(recorder.Actions()[0].GVK).To(Equal(corev1.SchemeGroupVersion.WithKind("Pod")))
+		Expect(recorder.Actions()[0].SubResource).To(Equal("status"))
+	})
+
+	It("should tell writes from reads", func() {
+		Expect(cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "existing"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(recorder.Writes()).To(BeEmpty())
+
+		Expect(cl.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default"))).To(Succeed())
+		Expect(recorder.Writes()).To(HaveLen(1))
+		Expect(recorder.Writes()[0].Verb).To(Equal(VerbDeleteCollection))
+		Expect(recorder.Writes()[0].GVK).To(Equal(configMapGVK))
+	})
+
+	It("should record calls before interceptor funcs handle them", func() {
+		injected := errors.New("injected")
+		cl = NewClientBuilder().
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					return injected
+				},
+			}).
+			WithActionRecorder(recorder).
+			Build()
+
+		Expect(cl.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}})).To(MatchError(injected))
+		Expect(recorder.Actions()).To(HaveLen(1))
+		Expect(recorder.Actions()[0].Err).To(MatchError(injected))
+		Expect(recorder.Actions()[0].Object).To(BeNil())
+	})
+})
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ObjectSchemas prunes, defaults and validates objects like the API server
+// does for custom resources with the schemas of their CRDs. It is implemented
+// by the crdschema package, which is kept separate so that users of the fake
+// client that don't need it don't depend on the validation libraries of the
+// API server.
+type ObjectSchemas interface {
+	// RESTMapper returns a RESTMapper for the kinds that have a schema.
+	RESTMapper() meta.RESTMapper
+
+	// StatusSubresources returns the kinds that have a status subresource.
+	StatusSubresources() []schema.GroupVersionKind
+
+	// Apply prunes the unknown fields of obj and applies the defaults of the
+	// schema of gvk. If validate is true, obj is also validated against the
+	// schema. oldObj is the object that is updated, or nil for creates. Apply
+	// returns false if gvk has no schema.
+	Apply(gvk schema.GroupVersionKind, obj, oldObj map[string]interface{}, validate bool) (bool, field.ErrorList)
+}
+
+// WithObjectSchemas makes the client prune, default and validate the objects
+// of the kinds that have a schema in schemas when they are written, e.g. with
+// the schemas of CRDs loaded by the crdschema package. Initial objects are only
+// pruned and defaulted. If the client isn't built WithRESTMapper, the kinds are
+// added to its default RESTMapper, and kinds with a status subresource are
+// treated like the kinds set WithStatusSubresource.
+func (f *ClientBuilder) WithObjectSchemas(schemas ObjectSchemas) *ClientBuilder {
+	f.objectSchemas = schemas
+	return f
+}
+
+// applySchema prunes and defaults obj with the schema of its kind, if any. If
+// validate is true, obj is also validated against the schema. oldObj is the
+// object that is updated, or nil for creates. If obj is invalid, it is left
+// unchanged.
+func (t versionedTracker) applySchema(obj, oldObj runtime.Object, validate bool) error {
+	if t.schemas == nil {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(obj, t.scheme)
+	if err != nil {
+		return err
+	}
+
+	u, err := toMapStringAny(obj)
+	if err != nil {
+		return err
+	}
+	if _, isUnstructured := obj.(*unstructured.Unstructured); isUnstructured {
+		u = runtime.DeepCopyJSON(u)
+	}
+	var old map[string]interface{}
+	if validate && oldObj != nil {
+		if old, err = toMapStringAny(oldObj); err != nil {
+			return err
+		}
+	}
+
+	hasSchema, errs := t.schemas.Apply(gvk, u, old, validate)
+	if !hasSchema {
+		return nil
+	}
+	if len(errs) > 0 {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		return apierrors.NewInvalid(gvk.GroupKind(), accessor.GetName(), errs)
+	}
+	return fromMapStringAny(u, obj)
+}
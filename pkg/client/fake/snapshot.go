@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// normalizedTime replaces the timestamps in the YAML of snapshots.
+const normalizedTime = "1970-01-01T00:00:00Z"
+
+// Snapshotter is implemented by the clients built by ClientBuilder, including
+// the ones built WithInterceptorFuncs or WithActionRecorder.
+type Snapshotter interface {
+	// Snapshot returns a copy of all objects in the client.
+	Snapshot() (*Snapshot, error)
+
+	// Restore replaces all objects in the client with the ones of snapshot,
+	// which may be taken from any client. Watches see the objects that are
+	// replaced as deleted and the restored ones as added.
+	Restore(snapshot *Snapshot) error
+}
+
+// Snapshot is a copy of the objects in a fake client. It only contains objects
+// that were written through the client or its builder, not ones that were added
+// to an ObjectTracker set WithObjectTracker directly.
+type Snapshot struct {
+	objects []trackedObject
+}
+
+// Objects returns copies of the objects in the snapshot, ordered by kind,
+// namespace and name.
+func (s *Snapshot) Objects() []client.Object {
+	objects := make([]client.Object, 0, len(s.objects))
+	for _, o := range s.objects {
+		if obj, ok := o.object.DeepCopyObject().(client.Object); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+// YAML returns the objects in the snapshot as a multi-document YAML, ordered
+// by kind, namespace and name, so it can be compared with golden files. Fields
+// that change from run to run are normalized: resourceVersions, UIDs and
+// managedFields are removed, the creationTimestamp is removed and the
+// deletionTimestamp and the last*Time fields of status.conditions are set to
+// the Unix epoch.
+func (s *Snapshot) YAML() ([]byte, error) {
+	var out bytes.Buffer
+	for _, o := range s.objects {
+		u, err := toUnstructuredWithGVK(o.object, o.gvk)
+		if err != nil {
+			return nil, err
+		}
+		if err := normalizeForSnapshot(u); err != nil {
+			return nil, fmt.Errorf("failed to normalize %s %s: %w", o.gvk.Kind, client.ObjectKeyFromObject(u), err)
+		}
+		data, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(data)
+	}
+	return out.Bytes(), nil
+}
+
+// normalizeForSnapshot removes or overwrites the fields of u that differ
+// between test runs.
+func normalizeForSnapshot(u *unstructured.Unstructured) error {
+	u.SetResourceVersion("")
+	u.SetUID("")
+	u.SetManagedFields(nil)
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	if u.GetDeletionTimestamp() != nil {
+		if err := unstructured.SetNestedField(u.Object, normalizedTime, "metadata", "deletionTimestamp"); err != nil {
+			return err
+		}
+	}
+
+	ownerReferences, _, err := unstructured.NestedSlice(u.Object, "metadata", "ownerReferences")
+	if err != nil {
+		return err
+	}
+	for _, ref := range ownerReferences {
+		if ref, ok := ref.(map[string]interface{}); ok {
+			delete(ref, "uid")
+		}
+	}
+	if ownerReferences != nil {
+		if err := unstructured.SetNestedSlice(u.Object, ownerReferences, "metadata", "ownerReferences"); err != nil {
+			return err
+		}
+	}
+
+	// Not all kinds have standard conditions, so fields that are no list of
+	// conditions are left alone.
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if conditions == nil {
+		return nil
+	}
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, value := range condition {
+			if strings.HasPrefix(key, "last") && strings.HasSuffix(key, "Time") && value != nil {
+				condition[key] = normalizedTime
+			}
+		}
+	}
+	return unstructured.SetNestedSlice(u.Object, conditions, "status", "conditions")
+}
+
+// snapshotClient adds the Snapshotter of the fake client to the clients that
+// wrap it.
+type snapshotClient struct {
+	client.WithWatch
+	Snapshotter
+}
+
+// Snapshot implements Snapshotter.
+func (c *fakeClient) Snapshot() (*Snapshot, error) {
+	objects, err := c.trackedObjects()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[runtime.Object]string, len(objects))
+	for _, o := range objects {
+		if keys[o.object], err = itemKey(o.object); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		if objects[i].gvk != objects[j].gvk {
+			return objects[i].gvk.String() < objects[j].gvk.String()
+		}
+		return keys[objects[i].object] < keys[objects[j].object]
+	})
+	return &Snapshot{objects: objects}, nil
+}
+
+// Restore implements Snapshotter.
+func (c *fakeClient) Restore(snapshot *Snapshot) error {
+	current, err := c.trackedObjects()
+	if err != nil {
+		return err
+	}
+	for _, o := range current {
+		accessor, err := meta.Accessor(o.object)
+		if err != nil {
+			return err
+		}
+		if err := c.tracker.ObjectTracker.Delete(o.gvr, accessor.GetNamespace(), accessor.GetName()); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	for _, o := range snapshot.objects {
+		if err := c.tracker.ObjectTracker.Add(o.object.DeepCopyObject()); err != nil {
+			return err
+		}
+		c.tracker.kinds.add(o.gvk, o.gvr)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Fake client snapshots", func() {
+	var (
+		ctx context.Context
+		cm  *corev1.ConfigMap
+		cl  client.WithWatch
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+			Data:       map[string]string{"a": "b"},
+		}
+		cl = NewClientBuilder().WithObjects(cm).Build()
+	})
+
+	It("should restore the objects of a snapshot", func() {
+		snapshot, err := cl.(Snapshotter).Snapshot()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		cm.Data["a"] = "c"
+		Expect(cl.Update(ctx, cm)).To(Succeed())
+		Expect(cl.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"}})).To(Succeed())
+
+		Expect(cl.(Snapshotter).Restore(snapshot)).To(Succeed())
+
+		restored := &corev1.ConfigMap{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), restored)).To(Succeed())
+		Expect(restored.Data).To(Equal(map[string]string{"a": "b"}))
+		Expect(restored.ResourceVersion).To(Equal(trackerAddResourceVersion))
+		err = cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "secret"}, &corev1.Secret{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not change when the client changes", func() {
+		snapshot, err := cl.(Snapshotter).Snapshot()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cl.Delete(ctx, cm)).To(Succeed())
+
+		objects := snapshot.Objects()
+		Expect(objects).To(HaveLen(1))
+		Expect(objects[0].(*corev1.ConfigMap).Data).To(Equal(map[string]string{"a": "b"}))
+
+		Expect(cl.(Snapshotter).Restore(snapshot)).To(Succeed())
+		Expect(cl.Delete(ctx, cm)).To(Succeed())
+		Expect(cl.(Snapshotter).Restore(snapshot)).To(Succeed())
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+	})
+
+	It("should be supported by clients with interceptors", func() {
+		cl = NewClientBuilder().WithObjects(cm).WithInterceptorFuncs(interceptor.Funcs{}).Build()
+		snapshot, err := cl.(Snapshotter).Snapshot()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Objects()).To(HaveLen(1))
+	})
+
+	It("should export normalized YAML", func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "1234"}}
+		now := metav1.NewTime(time.Now())
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "app-1",
+				Namespace:         "default",
+				UID:               "5678",
+				CreationTimestamp: now,
+				DeletionTimestamp: &now,
+				Finalizers:        []string{"example.com/finalizer"},
+				OwnerReferences:   []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", UID: "1234"}},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: now}},
+			},
+		}
+		widget := &unstructured.Unstructured{}
+		widget.SetAPIVersion("example.com/v1")
+		widget.SetKind("Widget")
+		widget.SetName("widget")
+		Expect(unstructured.SetNestedField(widget.Object, "small", "spec", "size")).To(Succeed())
+
+		cl = NewClientBuilder().WithObjects(cm, pod, deployment).WithReturnManagedFields().Build()
+		Expect(cl.Create(ctx, widget)).To(Succeed())
+
+		snapshot, err := cl.(Snapshotter).Snapshot()
+		Expect(err).NotTo(HaveOccurred())
+		out, err := snapshot.YAML()
+		Expect(err).NotTo(HaveOccurred())
+
+		golden, err := os.ReadFile(filepath.Join("testdata", "snapshot.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(Equal(string(golden)))
+	})
+})
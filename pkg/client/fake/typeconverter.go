@@ -0,0 +1,322 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	smdschema "sigs.k8s.io/structured-merge-diff/v4/schema"
+	"sigs.k8s.io/structured-merge-diff/v4/typed"
+)
+
+const (
+	untypedAtomicName  = "__untyped_atomic_"
+	untypedDeducedName = "__untyped_deduced_"
+)
+
+var (
+	untypedScalar = smdschema.Scalar("untyped")
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemeTypeConverter is a managedfields.TypeConverter that derives the
+// structured-merge-diff schema of a kind from the Go type registered for it
+// in the scheme. Lists are atomic unless their field has a `patchStrategy:"merge"`
+// tag, in which case they are keyed by their `patchMergeKey`. This closely
+// follows, but is not guaranteed to be identical to, the schema the apiserver
+// publishes for the type.
+//
+// Kinds that are not registered in the scheme, e.g. CRDs that are only used
+// as unstructured, get a deduced schema in which all lists are atomic, just
+// like CRDs without a schema in a real apiserver.
+type schemeTypeConverter struct {
+	scheme *runtime.Scheme
+
+	lock  sync.Mutex
+	types map[schema.GroupVersionKind]typed.ParseableType
+}
+
+var _ managedfields.TypeConverter = &schemeTypeConverter{}
+
+func newSchemeTypeConverter(scheme *runtime.Scheme) *schemeTypeConverter {
+	return &schemeTypeConverter{
+		scheme: scheme,
+		types:  map[schema.GroupVersionKind]typed.ParseableType{},
+	}
+}
+
+// ObjectToTyped implements managedfields.TypeConverter.
+func (c *schemeTypeConverter) ObjectToTyped(obj runtime.Object) (*typed.TypedValue, error) {
+	var content map[string]interface{}
+	if u, isUnstructured := obj.(runtime.Unstructured); isUnstructured {
+		content = u.UnstructuredContent()
+	} else {
+		var err error
+		content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.parseableType(obj.GetObjectKind().GroupVersionKind()).FromUnstructured(content)
+}
+
+// TypedToObject implements managedfields.TypeConverter.
+func (c *schemeTypeConverter) TypedToObject(value *typed.TypedValue) (runtime.Object, error) {
+	content, isMap := value.AsValue().Unstructured().(map[string]interface{})
+	if !isMap {
+		return nil, fmt.Errorf("failed to convert value to unstructured for type %T", value.AsValue().Unstructured())
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+func (c *schemeTypeConverter) parseableType(gvk schema.GroupVersionKind) typed.ParseableType {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if t, ok := c.types[gvk]; ok {
+		return t
+	}
+
+	t := typed.DeducedParseableType
+	if obj, err := c.scheme.New(gvk); err == nil {
+		if _, isUnstructured := obj.(runtime.Unstructured); !isUnstructured {
+			t = newSchemaBuilder().parseableType(reflect.TypeOf(obj))
+		}
+	}
+	c.types[gvk] = t
+	return t
+}
+
+// schemaBuilder builds a structured-merge-diff schema out of Go types.
+type schemaBuilder struct {
+	types []smdschema.TypeDef
+	names map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	untypedAtomic, untypedDeduced := untypedAtomicName, untypedDeducedName
+	return &schemaBuilder{
+		types: []smdschema.TypeDef{
+			{
+				Name: untypedAtomicName,
+				Atom: smdschema.Atom{
+					Scalar: &untypedScalar,
+					List:   &smdschema.List{ElementType: smdschema.TypeRef{NamedType: &untypedAtomic}, ElementRelationship: smdschema.Atomic},
+					Map:    &smdschema.Map{ElementType: smdschema.TypeRef{NamedType: &untypedAtomic}, ElementRelationship: smdschema.Atomic},
+				},
+			},
+			{
+				Name: untypedDeducedName,
+				Atom: smdschema.Atom{
+					Scalar: &untypedScalar,
+					List:   &smdschema.List{ElementType: smdschema.TypeRef{NamedType: &untypedAtomic}, ElementRelationship: smdschema.Atomic},
+					Map:    &smdschema.Map{ElementType: smdschema.TypeRef{NamedType: &untypedDeduced}, ElementRelationship: smdschema.Separable},
+				},
+			},
+		},
+		names: map[reflect.Type]string{},
+	}
+}
+
+func (b *schemaBuilder) parseableType(t reflect.Type) typed.ParseableType {
+	root := b.typeRef(t)
+	return typed.ParseableType{
+		Schema:  &smdschema.Schema{Types: b.types},
+		TypeRef: root,
+	}
+}
+
+func (b *schemaBuilder) typeRef(t reflect.Type) smdschema.TypeRef {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Types with custom serialization, e.g. metav1.Time, resource.Quantity or
+	// runtime.RawExtension, can't be introspected and are treated as atomic.
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return namedTypeRef(untypedAtomicName)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return b.structRef(t)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return namedTypeRef(untypedAtomicName)
+		}
+		return smdschema.TypeRef{Inlined: smdschema.Atom{Map: &smdschema.Map{
+			ElementType:         b.typeRef(t.Elem()),
+			ElementRelationship: smdschema.Separable,
+		}}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return scalarTypeRef(smdschema.String)
+		}
+		return smdschema.TypeRef{Inlined: smdschema.Atom{List: &smdschema.List{
+			ElementType:         b.typeRef(t.Elem()),
+			ElementRelationship: smdschema.Atomic,
+		}}}
+	case reflect.String:
+		return scalarTypeRef(smdschema.String)
+	case reflect.Bool:
+		return scalarTypeRef(smdschema.Boolean)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return scalarTypeRef(smdschema.Numeric)
+	case reflect.Interface:
+		return namedTypeRef(untypedDeducedName)
+	default:
+		return namedTypeRef(untypedAtomicName)
+	}
+}
+
+func (b *schemaBuilder) structRef(t reflect.Type) smdschema.TypeRef {
+	if t.Name() == "" {
+		return smdschema.TypeRef{Inlined: smdschema.Atom{Map: &smdschema.Map{Fields: b.fields(t, map[string]bool{})}}}
+	}
+
+	if name, ok := b.names[t]; ok {
+		return namedTypeRef(name)
+	}
+	name := t.PkgPath() + "." + t.Name()
+	b.names[t] = name
+
+	// Register the type before building its fields so recursive types refer
+	// to it by name.
+	idx := len(b.types)
+	b.types = append(b.types, smdschema.TypeDef{Name: name})
+	fields := b.fields(t, map[string]bool{})
+	b.types[idx].Atom = smdschema.Atom{Map: &smdschema.Map{Fields: fields}}
+
+	return namedTypeRef(name)
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, seen map[string]bool) []smdschema.StructField {
+	var fields []smdschema.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := f.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if f.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			fields = append(fields, b.fields(fieldType, seen)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		fields = append(fields, smdschema.StructField{Name: name, Type: b.fieldTypeRef(f)})
+	}
+	return fields
+}
+
+// fieldTypeRef returns the type of the given struct field, turning lists
+// with a merge patch strategy into associative lists.
+func (b *schemaBuilder) fieldTypeRef(f reflect.StructField) smdschema.TypeRef {
+	ref := b.typeRef(f.Type)
+	if ref.Inlined.List == nil || !strings.Contains(f.Tag.Get("patchStrategy"), "merge") {
+		return ref
+	}
+
+	list := *ref.Inlined.List
+	if key := f.Tag.Get("patchMergeKey"); key != "" {
+		list.ElementRelationship = smdschema.Associative
+		list.Keys = []string{key}
+	} else if elem := list.ElementType; elem.Inlined.Scalar != nil {
+		list.ElementRelationship = smdschema.Associative
+	}
+	ref.Inlined.List = &list
+	return ref
+}
+
+func namedTypeRef(name string) smdschema.TypeRef {
+	return smdschema.TypeRef{NamedType: &name}
+}
+
+func scalarTypeRef(s smdschema.Scalar) smdschema.TypeRef {
+	return smdschema.TypeRef{Inlined: smdschema.Atom{Scalar: &s}}
+}
+
+// unstructuredObjectConverter implements the runtime.ObjectConvertor,
+// runtime.ObjectDefaulter and runtime.ObjectCreater needed by the field
+// manager for unstructured objects of a single version.
+type unstructuredObjectConverter struct{}
+
+var (
+	_ runtime.ObjectConvertor = unstructuredObjectConverter{}
+	_ runtime.ObjectDefaulter = unstructuredObjectConverter{}
+	_ runtime.ObjectCreater   = unstructuredObjectConverter{}
+)
+
+func (unstructuredObjectConverter) Convert(in, out, context interface{}) error {
+	return errors.New("unstructuredObjectConverter does not support Convert")
+}
+
+func (unstructuredObjectConverter) ConvertToVersion(in runtime.Object, gv runtime.GroupVersioner) (runtime.Object, error) {
+	u, isUnstructured := in.(*unstructured.Unstructured)
+	if !isUnstructured {
+		return nil, fmt.Errorf("unstructuredObjectConverter can only convert unstructured objects, got %T", in)
+	}
+	gvk, ok := gv.KindForGroupVersionKinds([]schema.GroupVersionKind{u.GroupVersionKind()})
+	if !ok {
+		return nil, fmt.Errorf("%v is not suitable for converting to %v", u.GroupVersionKind(), gv)
+	}
+	out := u.DeepCopy()
+	out.SetGroupVersionKind(gvk)
+	return out, nil
+}
+
+func (unstructuredObjectConverter) ConvertFieldLabel(gvk schema.GroupVersionKind, label, value string) (string, string, error) {
+	return "", "", errors.New("unstructuredObjectConverter does not support ConvertFieldLabel")
+}
+
+func (unstructuredObjectConverter) Default(in runtime.Object) {}
+
+func (unstructuredObjectConverter) New(gvk schema.GroupVersionKind) (runtime.Object, error) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// TypedClient knows how to perform CRUD operations on Kubernetes objects of
+// a single type T, whose list type is L. It saves callers from allocating
+// objects to read into and from extracting the items of lists.
+type TypedClient[T Object, L ObjectList] interface {
+	// Get retrieves the object for the given object key from the Kubernetes Cluster.
+	Get(ctx context.Context, key ObjectKey, opts ...GetOption) (T, error)
+
+	// List retrieves the objects for the given options from the Kubernetes Cluster.
+	List(ctx context.Context, opts ...ListOption) ([]T, error)
+
+	// Create saves the object obj in the Kubernetes cluster. obj must be a
+	// struct pointer so that obj can be updated with the content returned by the Server.
+	Create(ctx context.Context, obj T, opts ...CreateOption) error
+
+	// Update updates the given obj in the Kubernetes cluster. obj must be a
+	// struct pointer so that obj can be updated with the content returned by the Server.
+	Update(ctx context.Context, obj T, opts ...UpdateOption) error
+
+	// Patch patches the given obj in the Kubernetes cluster. obj must be a
+	// struct pointer so that obj can be updated with the content returned by the Server.
+	Patch(ctx context.Context, obj T, patch Patch, opts ...PatchOption) error
+
+	// Delete deletes the given obj from Kubernetes cluster.
+	Delete(ctx context.Context, obj T, opts ...DeleteOption) error
+
+	// DeleteAllOf deletes all objects of type T matching the given options.
+	DeleteAllOf(ctx context.Context, opts ...DeleteAllOfOption) error
+
+	// Status knows how to create a client which can update the status subresource
+	// of objects of type T.
+	Status() TypedSubResourceWriter[T]
+
+	// Client returns the client this TypedClient is built on.
+	Client() Client
+}
+
+// TypedSubResourceWriter knows how to update the subresource of objects of type T.
+type TypedSubResourceWriter[T Object] interface {
+	// Update updates the fields corresponding to the subresource for the given obj.
+	Update(ctx context.Context, obj T, opts ...SubResourceUpdateOption) error
+
+	// Patch patches the given object's subresource.
+	Patch(ctx context.Context, obj T, patch Patch, opts ...SubResourcePatchOption) error
+}
+
+// NewTypedClient returns a TypedClient for objects of type T and lists of type L
+// that is built on top of the given client. T and L must be pointers to structs,
+// e.g. *appsv1.Deployment and *appsv1.DeploymentList. As the kind of the objects is
+// taken from their Go type, unstructured objects are not supported.
+//
+// NewTypedClient panics if T or L are not pointers to structs.
+func NewTypedClient[T Object, L ObjectList](c Client) TypedClient[T, L] {
+	for _, t := range []reflect.Type{reflect.TypeOf((*T)(nil)).Elem(), reflect.TypeOf((*L)(nil)).Elem()} {
+		if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			panic(fmt.Sprintf("TypedClient type parameters must be pointers to structs, got %v", t))
+		}
+	}
+	return &typedClientWrapper[T, L]{client: c}
+}
+
+var _ TypedClient[Object, ObjectList] = &typedClientWrapper[Object, ObjectList]{}
+
+// typedClientWrapper is a TypedClient that wraps a Client.
+type typedClientWrapper[T Object, L ObjectList] struct {
+	client Client
+}
+
+// Get implements TypedClient.
+func (c *typedClientWrapper[T, L]) Get(ctx context.Context, key ObjectKey, opts ...GetOption) (T, error) {
+	obj := newObjectOf[T]()
+	if err := c.client.Get(ctx, key, obj, opts...); err != nil {
+		var zero T
+		return zero, err
+	}
+	return obj, nil
+}
+
+// List implements TypedClient.
+func (c *typedClientWrapper[T, L]) List(ctx context.Context, opts ...ListOption) ([]T, error) {
+	list := newObjectOf[L]()
+	if err := c.client.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]T, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(T)
+		if !ok {
+			return nil, fmt.Errorf("expected list item of type %T, got %T", obj, item)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// Create implements TypedClient.
+func (c *typedClientWrapper[T, L]) Create(ctx context.Context, obj T, opts ...CreateOption) error {
+	return c.client.Create(ctx, obj, opts...)
+}
+
+// Update implements TypedClient.
+func (c *typedClientWrapper[T, L]) Update(ctx context.Context, obj T, opts ...UpdateOption) error {
+	return c.client.Update(ctx, obj, opts...)
+}
+
+// Patch implements TypedClient.
+func (c *typedClientWrapper[T, L]) Patch(ctx context.Context, obj T, patch Patch, opts ...PatchOption) error {
+	return c.client.Patch(ctx, obj, patch, opts...)
+}
+
+// Delete implements TypedClient.
+func (c *typedClientWrapper[T, L]) Delete(ctx context.Context, obj T, opts ...DeleteOption) error {
+	return c.client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf implements TypedClient.
+func (c *typedClientWrapper[T, L]) DeleteAllOf(ctx context.Context, opts ...DeleteAllOfOption) error {
+	return c.client.DeleteAllOf(ctx, newObjectOf[T](), opts...)
+}
+
+// Status implements TypedClient.
+func (c *typedClientWrapper[T, L]) Status() TypedSubResourceWriter[T] {
+	return &typedSubResourceWriter[T]{writer: c.client.Status()}
+}
+
+// Client implements TypedClient.
+func (c *typedClientWrapper[T, L]) Client() Client {
+	return c.client
+}
+
+// typedSubResourceWriter is a TypedSubResourceWriter that wraps a SubResourceWriter.
+type typedSubResourceWriter[T Object] struct {
+	writer SubResourceWriter
+}
+
+// Update implements TypedSubResourceWriter.
+func (w *typedSubResourceWriter[T]) Update(ctx context.Context, obj T, opts ...SubResourceUpdateOption) error {
+	return w.writer.Update(ctx, obj, opts...)
+}
+
+// Patch implements TypedSubResourceWriter.
+func (w *typedSubResourceWriter[T]) Patch(ctx context.Context, obj T, patch Patch, opts ...SubResourcePatchOption) error {
+	return w.writer.Patch(ctx, obj, patch, opts...)
+}
+
+// newObjectOf returns a new, empty object of type T, which must be a pointer to a struct.
+func newObjectOf[T any]() T {
+	return reflect.New(reflect.TypeOf((*T)(nil)).Elem().Elem()).Interface().(T)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("TypedClient", func() {
+	var dep *appsv1.Deployment
+	var c client.Client
+	var tc client.TypedClient[*appsv1.Deployment, *appsv1.DeploymentList]
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		dep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "default", Labels: map[string]string{"app": "test"}},
+		}
+		other := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-deployment", Namespace: "other"},
+		}
+		c = fake.NewClientBuilder().WithObjects(dep, other).WithStatusSubresource(dep).Build()
+		tc = client.NewTypedClient[*appsv1.Deployment, *appsv1.DeploymentList](c)
+	})
+
+	It("should get objects", func() {
+		actual, err := tc.Get(ctx, client.ObjectKeyFromObject(dep))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.Name).To(Equal(dep.Name))
+		Expect(actual.Labels).To(Equal(dep.Labels))
+
+		actual, err = tc.Get(ctx, client.ObjectKey{Namespace: "default", Name: "missing"})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(actual).To(BeNil())
+	})
+
+	It("should list objects", func() {
+		actual, err := tc.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(ConsistOf(HaveField("Name", "deployment"), HaveField("Name", "other-deployment")))
+
+		actual, err = tc.List(ctx, client.InNamespace("other"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(ConsistOf(HaveField("Name", "other-deployment")))
+	})
+
+	It("should create, update, patch and delete objects", func() {
+		obj := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default"}}
+		Expect(tc.Create(ctx, obj)).To(Succeed())
+
+		obj.Labels = map[string]string{"updated": "true"}
+		Expect(tc.Update(ctx, obj)).To(Succeed())
+
+		base := obj.DeepCopy()
+		obj.Annotations = map[string]string{"patched": "true"}
+		Expect(tc.Patch(ctx, obj, client.MergeFrom(base))).To(Succeed())
+
+		actual, err := tc.Get(ctx, client.ObjectKeyFromObject(obj))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.Labels).To(HaveKeyWithValue("updated", "true"))
+		Expect(actual.Annotations).To(HaveKeyWithValue("patched", "true"))
+
+		Expect(tc.Delete(ctx, obj)).To(Succeed())
+		_, err = tc.Get(ctx, client.ObjectKeyFromObject(obj))
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should delete all objects matching the options", func() {
+		Expect(tc.DeleteAllOf(ctx, client.InNamespace("default"))).To(Succeed())
+
+		actual, err := tc.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(ConsistOf(HaveField("Name", "other-deployment")))
+	})
+
+	It("should update the status of objects", func() {
+		obj, err := tc.Get(ctx, client.ObjectKeyFromObject(dep))
+		Expect(err).NotTo(HaveOccurred())
+
+		obj.Status.Replicas = 3
+		Expect(tc.Status().Update(ctx, obj)).To(Succeed())
+
+		base := obj.DeepCopy()
+		obj.Status.ReadyReplicas = 2
+		Expect(tc.Status().Patch(ctx, obj, client.MergeFrom(base))).To(Succeed())
+
+		actual, err := tc.Get(ctx, client.ObjectKeyFromObject(dep))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.Status.Replicas).To(BeEquivalentTo(3))
+		Expect(actual.Status.ReadyReplicas).To(BeEquivalentTo(2))
+	})
+
+	It("should return the client it is built on", func() {
+		Expect(tc.Client()).To(BeIdenticalTo(c))
+	})
+
+	It("should panic if the types are not pointers to structs", func() {
+		Expect(func() {
+			client.NewTypedClient[client.Object, *appsv1.DeploymentList](c)
+		}).To(Panic())
+		Expect(func() {
+			client.NewTypedClient[*appsv1.Deployment, client.ObjectList](c)
+		}).To(Panic())
+	})
+})
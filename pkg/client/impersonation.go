@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// WithImpersonation returns a client that performs all requests of the given
+// client as the user described by impersonate. The returned client shares the
+// RESTMapper, the REST clients and the HTTP transport of the given client, so
+// creating it is cheap and it can be done per request, e.g. to act on behalf of
+// the user of an admission request.
+//
+// Reads of the returned client always go to the API server, as the cache of the
+// given client is populated with the permissions of the client itself. If the
+// given client is a WithWatch, so is the returned client.
+//
+// Only clients created by New or NewWithWatch can be impersonated.
+func WithImpersonation(c Client, impersonate rest.ImpersonationConfig) (Client, error) {
+	if impersonate.UserName == "" {
+		return nil, errors.New("must specify a user name to impersonate")
+	}
+
+	switch c := c.(type) {
+	case *client:
+		return c.impersonating(impersonate)
+	case *watchingClient:
+		impersonated, err := c.client.impersonating(impersonate)
+		if err != nil {
+			return nil, err
+		}
+		return &watchingClient{client: impersonated}, nil
+	default:
+		return nil, fmt.Errorf("impersonation is not supported for client of type %T", c)
+	}
+}
+
+// impersonating returns an uncached copy of the client that adds the given
+// impersonation headers to all requests.
+func (c *client) impersonating(impersonate rest.ImpersonationConfig) (*client, error) {
+	resources := c.typedClient.resources.impersonating(impersonationHeaders(impersonate))
+
+	httpClient := *resources.httpClient
+	httpClient.Transport = transport.NewImpersonatingRoundTripper(transport.ImpersonationConfig{
+		UserName: impersonate.UserName,
+		UID:      impersonate.UID,
+		Groups:   impersonate.Groups,
+		Extra:    impersonate.Extra,
+	}, resources.httpClient.Transport)
+	rawMetaClient, err := metadata.NewForConfigAndClient(metadata.ConfigFor(resources.config), &httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("unable to construct metadata-only client for use as part of client: %w", err)
+	}
+
+	return &client{
+		typedClient: typedClient{
+			resources:  resources,
+			paramCodec: c.typedClient.paramCodec,
+		},
+		unstructuredClient: unstructuredClient{
+			resources:  resources,
+			paramCodec: c.unstructuredClient.paramCodec,
+		},
+		metadataClient: metadataClient{
+			client:     rawMetaClient,
+			restMapper: c.metadataClient.restMapper,
+		},
+		scheme: c.scheme,
+		mapper: c.mapper,
+	}, nil
+}
+
+// impersonationHeaders returns the headers client-go sends to impersonate the
+// user described by the given config.
+func impersonationHeaders(impersonate rest.ImpersonationConfig) http.Header {
+	headers := http.Header{}
+	rt := transport.NewImpersonatingRoundTripper(transport.ImpersonationConfig{
+		UserName: impersonate.UserName,
+		UID:      impersonate.UID,
+		Groups:   impersonate.Groups,
+		Extra:    impersonate.Extra,
+	}, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		headers = req.Header
+		return nil, nil
+	}))
+	// The impersonating round tripper only adds headers to a copy of the
+	// request, which takes care of escaping the keys of extra fields.
+	_, _ = rt.RoundTrip(&http.Request{Header: http.Header{}})
+	return headers
+}
+
+// roundTripperFunc implements http.RoundTripper with a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// impersonatingRESTClient is a rest.Interface that adds impersonation headers
+// to all requests of the wrapped rest.Interface.
+type impersonatingRESTClient struct {
+	rest.Interface
+	headers http.Header
+}
+
+func (c *impersonatingRESTClient) withHeaders(req *rest.Request) *rest.Request {
+	for key, values := range c.headers {
+		req = req.SetHeader(key, values...)
+	}
+	return req
+}
+
+// Verb implements rest.Interface.
+func (c *impersonatingRESTClient) Verb(verb string) *rest.Request {
+	return c.withHeaders(c.Interface.Verb(verb))
+}
+
+// Post implements rest.Interface.
+func (c *impersonatingRESTClient) Post() *rest.Request {
+	return c.withHeaders(c.Interface.Post())
+}
+
+// Put implements rest.Interface.
+func (c *impersonatingRESTClient) Put() *rest.Request {
+	return c.withHeaders(c.Interface.Put())
+}
+
+// Patch implements rest.Interface.
+func (c *impersonatingRESTClient) Patch(pt types.PatchType) *rest.Request {
+	return c.withHeaders(c.Interface.Patch(pt))
+}
+
+// Get implements rest.Interface.
+func (c *impersonatingRESTClient) Get() *rest.Request {
+	return c.withHeaders(c.Interface.Get())
+}
+
+// Delete implements rest.Interface.
+func (c *impersonatingRESTClient) Delete() *rest.Request {
+	return c.withHeaders(c.Interface.Delete())
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ClientWithImpersonation", func() {
+	var server *httptest.Server
+	var mu sync.Mutex
+	var requestHeaders []http.Header
+	var c client.Client
+	ctx := context.Background()
+
+	impersonate := rest.ImpersonationConfig{
+		UserName: "alice",
+		UID:      "1234",
+		Groups:   []string{"devs", "admins"},
+		Extra:    map[string][]string{"example.com/scopes": {"read"}},
+	}
+
+	BeforeEach(func() {
+		requestHeaders = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requestHeaders = append(requestHeaders, r.Header.Clone())
+			mu.Unlock()
+
+			var obj interface{} = &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+			}
+			if strings.Contains(r.Header.Get("Accept"), "as=PartialObjectMetadata") {
+				obj = &metav1.PartialObjectMetadata{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "PartialObjectMetadata"},
+					ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(obj)).To(Succeed())
+		}))
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+		var err error
+		c, err = client.New(&rest.Config{Host: server.URL}, client.Options{Mapper: mapper})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	expectImpersonated := func(headers http.Header) {
+		ExpectWithOffset(1, headers.Get("Impersonate-User")).To(Equal("alice"))
+		ExpectWithOffset(1, headers.Get("Impersonate-Uid")).To(Equal("1234"))
+		ExpectWithOffset(1, headers.Values("Impersonate-Group")).To(Equal([]string{"devs", "admins"}))
+		ExpectWithOffset(1, headers.Values("Impersonate-Extra-Example.com%2fscopes")).To(Equal([]string{"read"}))
+	}
+
+	It("should add the impersonation headers to typed, unstructured and metadata requests", func() {
+		impersonated, err := client.WithImpersonation(c, impersonate)
+		Expect(err).NotTo(HaveOccurred())
+
+		key := client.ObjectKey{Namespace: "default", Name: "cm"}
+		Expect(impersonated.Get(ctx, key, &corev1.ConfigMap{})).To(Succeed())
+
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+		Expect(impersonated.Get(ctx, key, u)).To(Succeed())
+
+		m := &metav1.PartialObjectMetadata{}
+		m.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+		Expect(impersonated.Get(ctx, key, m)).To(Succeed())
+
+		Expect(requestHeaders).To(HaveLen(3))
+		for _, headers := range requestHeaders {
+			expectImpersonated(headers)
+		}
+	})
+
+	It("should not impersonate requests of the original client", func() {
+		impersonated, err := client.WithImpersonation(c, impersonate)
+		Expect(err).NotTo(HaveOccurred())
+
+		key := client.ObjectKey{Namespace: "default", Name: "cm"}
+		Expect(impersonated.Get(ctx, key, &corev1.ConfigMap{})).To(Succeed())
+		Expect(c.Get(ctx, key, &corev1.ConfigMap{})).To(Succeed())
+
+		Expect(requestHeaders).To(HaveLen(2))
+		expectImpersonated(requestHeaders[0])
+		Expect(requestHeaders[1].Get("Impersonate-User")).To(BeEmpty())
+	})
+
+	It("should replace the impersonated user when impersonating again", func() {
+		impersonated, err := client.WithImpersonation(c, impersonate)
+		Expect(err).NotTo(HaveOccurred())
+		impersonated, err = client.WithImpersonation(impersonated, rest.ImpersonationConfig{UserName: "bob"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(impersonated.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cm"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(requestHeaders).To(HaveLen(1))
+		Expect(requestHeaders[0].Get("Impersonate-User")).To(Equal("bob"))
+		Expect(requestHeaders[0].Values("Impersonate-Group")).To(BeEmpty())
+	})
+
+	It("should return a WithWatch for a WithWatch", func() {
+		wc, err := client.NewWithWatch(&rest.Config{Host: server.URL}, client.Options{Mapper: c.RESTMapper()})
+		Expect(err).NotTo(HaveOccurred())
+
+		impersonated, err := client.WithImpersonation(wc, impersonate)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(impersonated).To(BeAssignableToTypeOf(wc))
+	})
+
+	It("should fail without a user name", func() {
+		_, err := client.WithImpersonation(c, rest.ImpersonationConfig{Groups: []string{"devs"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail for clients that weren't created by New", func() {
+		_, err := client.WithImpersonation(fake.NewClientBuilder().Build(), impersonate)
+		Expect(err).To(MatchError(ContainSubstring("not supported")))
+	})
+})
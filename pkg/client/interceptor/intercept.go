@@ -2,6 +2,7 @@ package interceptor
 
 import (
 	"context"
+	"io"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,12 +20,15 @@ type Funcs struct {
 	DeleteAllOf       func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.DeleteAllOfOption) error
 	Update            func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.UpdateOption) error
 	Patch             func(ctx context.Context, client client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error
+	Apply             func(ctx context.Context, client client.WithWatch, obj client.ApplyConfiguration, opts ...client.ApplyOption) error
 	Watch             func(ctx context.Context, client client.WithWatch, obj client.ObjectList, opts ...client.ListOption) (watch.Interface, error)
 	SubResource       func(client client.WithWatch, subResource string) client.SubResourceClient
 	SubResourceGet    func(ctx context.Context, client client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceGetOption) error
 	SubResourceCreate func(ctx context.Context, client client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error
 	SubResourceUpdate func(ctx context.Context, client client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error
 	SubResourcePatch  func(ctx context.Context, client client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error
+	SubResourceApply  func(ctx context.Context, client client.Client, subResourceName string, obj client.ApplyConfiguration, opts ...client.SubResourceApplyOption) error
+	SubResourceStream func(ctx context.Context, client client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceStreamOption) (io.ReadCloser, error)
 }
 
 // NewClient returns a new interceptor client that calls the functions in funcs instead of the underlying client's methods, if they are not nil.
@@ -92,6 +96,13 @@ func (c interceptor) Patch(ctx context.Context, obj client.Object, patch client.
 	return c.client.Patch(ctx, obj, patch, opts...)
 }
 
+func (c interceptor) Apply(ctx context.Context, obj client.ApplyConfiguration, opts ...client.ApplyOption) error {
+	if c.funcs.Apply != nil {
+		return c.funcs.Apply(ctx, c.client, obj, opts...)
+	}
+	return c.client.Apply(ctx, obj, opts...)
+}
+
 func (c interceptor) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
 	if c.funcs.DeleteAllOf != nil {
 		return c.funcs.DeleteAllOf(ctx, c.client, obj, opts...)
@@ -136,6 +147,7 @@ type subResourceInterceptor struct {
 }
 
 var _ client.SubResourceClient = &subResourceInterceptor{}
+var _ client.SubResourceStreamer = &subResourceInterceptor{}
 
 func (s subResourceInterceptor) Get(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceGetOption) error {
 	if s.funcs.SubResourceGet != nil {
@@ -164,3 +176,17 @@ func (s subResourceInterceptor) Patch(ctx context.Context, obj client.Object, pa
 	}
 	return s.client.SubResource(s.subResourceName).Patch(ctx, obj, patch, opts...)
 }
+
+func (s subResourceInterceptor) Apply(ctx context.Context, obj client.ApplyConfiguration, opts ...client.SubResourceApplyOption) error {
+	if s.funcs.SubResourceApply != nil {
+		return s.funcs.SubResourceApply(ctx, s.client, s.subResourceName, obj, opts...)
+	}
+	return s.client.SubResource(s.subResourceName).Apply(ctx, obj, opts...)
+}
+
+func (s subResourceInterceptor) Stream(ctx context.Context, obj client.Object, opts ...client.SubResourceStreamOption) (io.ReadCloser, error) {
+	if s.funcs.SubResourceStream != nil {
+		return s.funcs.SubResourceStream(ctx, s.client, s.subResourceName, obj, opts...)
+	}
+	return client.StreamSubResource(ctx, s.client.SubResource(s.subResourceName), obj, opts...)
+}
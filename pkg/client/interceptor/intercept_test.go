@@ -178,6 +178,29 @@ var _ = Describe("NewClient", func() {
 		_ = client2.Patch(ctx, nil, nil)
 		Expect(called).To(BeTrue())
 	})
+	It("should call the provided Apply function", func() {
+		var called bool
+		client := NewClient(wrappedClient, Funcs{
+			Apply: func(ctx context.Context, client client.WithWatch, obj client.ApplyConfiguration, opts ...client.ApplyOption) error {
+				called = true
+				return nil
+			},
+		})
+		_ = client.Apply(ctx, nil)
+		Expect(called).To(BeTrue())
+	})
+	It("should call the underlying client if the provided Apply function is nil", func() {
+		var called bool
+		client1 := NewClient(wrappedClient, Funcs{
+			Apply: func(ctx context.Context, client client.WithWatch, obj client.ApplyConfiguration, opts ...client.ApplyOption) error {
+				called = true
+				return nil
+			},
+		})
+		client2 := NewClient(client1, Funcs{})
+		_ = client2.Apply(ctx, nil)
+		Expect(called).To(BeTrue())
+	})
 	It("should call the provided Watch function", func() {
 		var called bool
 		client := NewClient(wrappedClient, Funcs{
@@ -305,6 +328,31 @@ var _ = Describe("NewSubResourceClient", func() {
 		_ = client2.SubResource("foo").Patch(ctx, nil, nil)
 		Expect(called).To(BeTrue())
 	})
+	It("should call the provided Apply function", func() {
+		var called bool
+		client := NewClient(c, Funcs{
+			SubResourceApply: func(_ context.Context, client client.Client, subResourceName string, obj client.ApplyConfiguration, opts ...client.SubResourceApplyOption) error {
+				called = true
+				Expect(subResourceName).To(BeEquivalentTo("foo"))
+				return nil
+			},
+		})
+		_ = client.SubResource("foo").Apply(ctx, nil)
+		Expect(called).To(BeTrue())
+	})
+	It("should call the underlying client if the provided Apply function is nil", func() {
+		var called bool
+		client1 := NewClient(c, Funcs{
+			SubResourceApply: func(_ context.Context, client client.Client, subResourceName string, obj client.ApplyConfiguration, opts ...client.SubResourceApplyOption) error {
+				called = true
+				Expect(subResourceName).To(BeEquivalentTo("foo"))
+				return nil
+			},
+		})
+		client2 := NewClient(client1, Funcs{})
+		_ = client2.SubResource("foo").Apply(ctx, nil)
+		Expect(called).To(BeTrue())
+	})
 	It("should call the provided Create function", func() {
 		var called bool
 		client := NewClient(c, Funcs{
@@ -360,6 +408,10 @@ func (d dummyClient) Patch(ctx context.Context, obj client.Object, patch client.
 	return nil
 }
 
+func (d dummyClient) Apply(ctx context.Context, obj client.ApplyConfiguration, opts ...client.ApplyOption) error {
+	return nil
+}
+
 func (d dummyClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
 	return nil
 }
@@ -18,6 +18,8 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"io"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -44,6 +46,22 @@ type Patch interface {
 	Data(obj Object) ([]byte, error)
 }
 
+// ApplyConfiguration is the sparse, declarative representation of an object
+// that is sent as the body of a server-side apply request. Only the fields that
+// are set are owned by the field manager of the request.
+//
+// The apply configurations generated in k8s.io/client-go/applyconfigurations,
+// e.g. appsv1ac.Deployment("name", "namespace"), as well as
+// *unstructured.Unstructured and *metav1.PartialObjectMetadata satisfy it. Any
+// other value must be a struct pointer that serializes to JSON with apiVersion,
+// kind and metadata.name set.
+//
+// ApplyConfiguration has no methods because the generated apply configurations
+// don't share any that a marker method could be based on: their builders all
+// return their own types. Values are checked when a request is made instead,
+// and Apply returns an error for values that don't meet the requirements above.
+type ApplyConfiguration interface{}
+
 // TODO(directxman12): is there a sane way to deal with get/delete options?
 
 // Reader knows how to read and list Kubernetes objects.
@@ -76,6 +94,11 @@ type Writer interface {
 	// struct pointer so that obj can be updated with the content returned by the Server.
 	Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error
 
+	// Apply applies the given apply configuration to the Kubernetes cluster
+	// using server-side apply. obj must be a struct pointer so that obj can be
+	// updated with the content returned by the Server.
+	Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) error
+
 	// DeleteAllOf deletes all objects of the given type matching the given options.
 	DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) error
 }
@@ -151,6 +174,38 @@ type SubResourceWriter interface {
 	// pointer so that obj can be updated with the content returned by the
 	// Server.
 	Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error
+
+	// Apply applies the given apply configuration to the object's subresource
+	// using server-side apply. obj must be a struct pointer so that obj can be
+	// updated with the content returned by the Server.
+	Apply(ctx context.Context, obj ApplyConfiguration, opts ...SubResourceApplyOption) error
+}
+
+// SubResourceStreamer knows how to stream subresources, such as the logs of
+// a pod. It is implemented by the SubResourceClients of the clients in this
+// package, which can be type-asserted to it. Use StreamSubResource to do so.
+type SubResourceStreamer interface {
+	// Stream opens a stream of the subresource of the given obj. The caller
+	// must close the returned stream.
+	Stream(ctx context.Context, obj Object, opts ...SubResourceStreamOption) (io.ReadCloser, error)
+}
+
+// StreamSubResource opens a stream of the subresource of the given obj using
+// the given SubResourceClient, e.g.:
+//
+//	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "bar"}}
+//	logs, err := client.StreamSubResource(ctx, c.SubResource("log"), pod, &client.SubResourceStreamOptions{
+//	  Raw: &corev1.PodLogOptions{Container: "app", Follow: true},
+//	})
+//
+// It returns an error if the SubResourceClient doesn't implement
+// SubResourceStreamer. The caller must close the returned stream.
+func StreamSubResource(ctx context.Context, c SubResourceClient, obj Object, opts ...SubResourceStreamOption) (io.ReadCloser, error) {
+	streamer, ok := c.(SubResourceStreamer)
+	if !ok {
+		return nil, fmt.Errorf("subresource client %T does not support streaming", c)
+	}
+	return streamer.Stream(ctx, obj, opts...)
 }
 
 // SubResourceClient knows how to perform CRU operations on Kubernetes objects.
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// ConflictRetries is a prometheus counter metrics which holds the total
+	// number of writes retried after a conflict per group, kind and operation.
+	ConflictRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_client_conflict_retries_total",
+		Help: "Total number of writes retried after a conflict per group, kind and operation",
+	}, []string{"group", "kind", "operation"})
+
+	// ConflictRetriesExhausted is a prometheus counter metrics which holds the
+	// total number of writes that still failed with a conflict after all retries
+	// per group, kind and operation.
+	ConflictRetriesExhausted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_client_conflict_retries_exhausted_total",
+		Help: "Total number of writes that still conflicted after all retries per group, kind and operation",
+	}, []string{"group", "kind", "operation"})
+
+	// SkippedNoOpWrites is a prometheus counter metrics which holds the total
+	// number of writes that were skipped because they wouldn't have changed
+	// the object, per group, kind and operation.
+	SkippedNoOpWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_client_skipped_noop_writes_total",
+		Help: "Total number of writes skipped because they wouldn't have changed the object per group, kind and operation",
+	}, []string{"group", "kind", "operation"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ConflictRetries,
+		ConflictRetriesExhausted,
+		SkippedNoOpWrites,
+	)
+}
@@ -24,6 +24,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/metadata"
 )
 
@@ -115,6 +118,36 @@ func (mc *metadataClient) Patch(ctx context.Context, obj Object, patch Patch, op
 	return nil
 }
 
+// Apply implements client.Client.
+func (mc *metadataClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) error {
+	metadata, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return fmt.Errorf("metadata client did not understand object: %T", obj)
+	}
+
+	gvk := metadata.GroupVersionKind()
+	resInt, err := mc.getResourceInterface(gvk, metadata.Namespace)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	applyOpts := &ApplyOptions{}
+	applyOpts.ApplyOptions(opts)
+
+	res, err := resInt.Patch(ctx, metadata.Name, types.ApplyPatchType, data, *applyOpts.AsPatchOptions())
+	if err != nil {
+		return err
+	}
+	*metadata = *res
+	metadata.SetGroupVersionKind(gvk) // restore the GVK, which isn't set on metadata
+	return nil
+}
+
 // Get implements client.Client.
 func (mc *metadataClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
 	metadata, ok := obj.(*metav1.PartialObjectMetadata)
@@ -168,6 +201,40 @@ func (mc *metadataClient) List(ctx context.Context, obj ObjectList, opts ...List
 	return nil
 }
 
+// Watch implements client.WithWatch.
+func (mc *metadataClient) Watch(ctx context.Context, obj ObjectList, opts ...ListOption) (watch.Interface, error) {
+	metadata, ok := obj.(*metav1.PartialObjectMetadataList)
+	if !ok {
+		return nil, fmt.Errorf("metadata client did not understand object: %T", obj)
+	}
+
+	gvk := metadata.GroupVersionKind()
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	listOpts := ListOptions{}
+	listOpts.ApplyOptions(opts)
+	rawOpts := listOpts.AsListOptions()
+	rawOpts.Watch = true
+
+	resInt, err := mc.getResourceInterface(gvk, listOpts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := resInt.Watch(ctx, *rawOpts)
+	if err != nil {
+		return nil, err
+	}
+	// Set the GVK of the watched kind on the objects of the events, which
+	// otherwise carry the GVK of metav1.PartialObjectMetadata.
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		if m, isMetadata := in.Object.(*metav1.PartialObjectMetadata); isMetadata {
+			m.SetGroupVersionKind(gvk)
+		}
+		return in, true
+	}), nil
+}
+
 func (mc *metadataClient) PatchSubResource(ctx context.Context, obj Object, subResource string, patch Patch, opts ...SubResourcePatchOption) error {
 	metadata, ok := obj.(*metav1.PartialObjectMetadata)
 	if !ok {
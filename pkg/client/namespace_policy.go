@@ -0,0 +1,273 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/internal/applyconfiguration"
+)
+
+// NamespacePolicy restricts the objects a client can access to the given
+// namespaces and kinds of cluster-scoped objects.
+type NamespacePolicy struct {
+	// Namespaces are the namespaces in which namespaced objects may be read
+	// and written. Lists and DeleteAllOf across all namespaces are rejected.
+	Namespaces []string
+
+	// ClusterScopedWrites are the kinds of cluster-scoped objects that may be
+	// written. Cluster-scoped objects may always be read.
+	ClusterScopedWrites []schema.GroupKind
+}
+
+// NewNamespacePolicyClient wraps an existing client and rejects all requests
+// that the given policy doesn't permit with a Forbidden error, without sending
+// them to the API server:
+//   - Reads and writes of namespaced objects, and their subresources, outside
+//     the namespaces of the policy.
+//   - Writes of cluster-scoped objects, and their subresources, whose kind
+//     isn't listed in the policy.
+//
+// This is useful to keep controllers from acting outside of the namespaces
+// they are responsible for and, in tests, to surface requests that RBAC would
+// forbid in production.
+func NewNamespacePolicyClient(c Client, policy NamespacePolicy) Client {
+	return &namespacePolicyClient{
+		client:              c,
+		namespaces:          sets.New(policy.Namespaces...),
+		clusterScopedWrites: sets.New(policy.ClusterScopedWrites...),
+	}
+}
+
+var _ Client = &namespacePolicyClient{}
+
+// namespacePolicyClient is a Client that wraps another Client in order to
+// reject requests outside of a NamespacePolicy.
+type namespacePolicyClient struct {
+	client              Client
+	namespaces          sets.Set[string]
+	clusterScopedWrites sets.Set[schema.GroupKind]
+}
+
+// checkObject checks whether the policy permits a request for obj, which may
+// be a list, in the given namespace.
+func (c *namespacePolicyClient) checkObject(obj runtime.Object, namespace, name string, write bool) error {
+	gvk, err := c.client.GroupVersionKindFor(obj)
+	if err != nil {
+		return err
+	}
+	if meta.IsListType(obj) {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	return c.check(gvk, namespace, name, write)
+}
+
+// checkApplyConfiguration checks whether the policy permits applying obj.
+func (c *namespacePolicyClient) checkApplyConfiguration(obj ApplyConfiguration) error {
+	u, err := applyconfiguration.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	return c.check(u.GroupVersionKind(), u.GetNamespace(), u.GetName(), true)
+}
+
+// check checks whether the policy permits a request for the given kind in the
+// given namespace and returns a Forbidden error if it doesn't.
+func (c *namespacePolicyClient) check(gvk schema.GroupVersionKind, namespace, name string, write bool) error {
+	mapping, err := c.client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("error finding the scope of the object: %w", err)
+	}
+
+	var reason string
+	switch {
+	case mapping.Scope.Name() != meta.RESTScopeNameRoot && namespace == "":
+		reason = "requests across all namespaces are not permitted by the namespace policy of the client"
+	case mapping.Scope.Name() != meta.RESTScopeNameRoot && !c.namespaces.Has(namespace):
+		reason = fmt.Sprintf("namespace %q is not permitted by the namespace policy of the client", namespace)
+	case mapping.Scope.Name() == meta.RESTScopeNameRoot && write && !c.clusterScopedWrites.Has(gvk.GroupKind()):
+		reason = fmt.Sprintf("writes of cluster-scoped %s are not permitted by the namespace policy of the client", gvk.GroupKind())
+	default:
+		return nil
+	}
+	return apierrors.NewForbidden(mapping.Resource.GroupResource(), name, errors.New(reason))
+}
+
+// Scheme returns the scheme this client is using.
+func (c *namespacePolicyClient) Scheme() *runtime.Scheme {
+	return c.client.Scheme()
+}
+
+// RESTMapper returns the rest mapper this client is using.
+func (c *namespacePolicyClient) RESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GroupVersionKindFor returns the GroupVersionKind for the given object.
+func (c *namespacePolicyClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return c.client.GroupVersionKindFor(obj)
+}
+
+// IsObjectNamespaced returns true if the GroupVersionKind of the object is namespaced.
+func (c *namespacePolicyClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return c.client.IsObjectNamespaced(obj)
+}
+
+// Get implements client.Client.
+func (c *namespacePolicyClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
+	if err := c.checkObject(obj, key.Namespace, key.Name, false); err != nil {
+		return err
+	}
+	return c.client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Client.
+func (c *namespacePolicyClient) List(ctx context.Context, list ObjectList, opts ...ListOption) error {
+	listOpts := (&ListOptions{}).ApplyOptions(opts)
+	if err := c.checkObject(list, listOpts.Namespace, "", false); err != nil {
+		return err
+	}
+	return c.client.List(ctx, list, opts...)
+}
+
+// Create implements client.Client.
+func (c *namespacePolicyClient) Create(ctx context.Context, obj Object, opts ...CreateOption) error {
+	if err := c.checkObject(obj, obj.GetNamespace(), obj.GetName(), true); err != nil {
+		return err
+	}
+	return c.client.Create(ctx, obj, opts...)
+}
+
+// Update implements client.Client.
+func (c *namespacePolicyClient) Update(ctx context.Context, obj Object, opts ...UpdateOption) error {
+	if err := c.checkObject(obj, obj.GetNamespace(), obj.GetName(), true); err != nil {
+		return err
+	}
+	return c.client.Update(ctx, obj, opts...)
+}
+
+// Delete implements client.Client.
+func (c *namespacePolicyClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption) error {
+	if err := c.checkObject(obj, obj.GetNamespace(), obj.GetName(), true); err != nil {
+		return err
+	}
+	return c.client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf implements client.Client.
+func (c *namespacePolicyClient) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) error {
+	deleteAllOfOpts := (&DeleteAllOfOptions{}).ApplyOptions(opts)
+	if err := c.checkObject(obj, deleteAllOfOpts.Namespace, "", true); err != nil {
+		return err
+	}
+	return c.client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Patch implements client.Client.
+func (c *namespacePolicyClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error {
+	if err := c.checkObject(obj, obj.GetNamespace(), obj.GetName(), true); err != nil {
+		return err
+	}
+	return c.client.Patch(ctx, obj, patch, opts...)
+}
+
+// Apply implements client.Client.
+func (c *namespacePolicyClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) error {
+	if err := c.checkApplyConfiguration(obj); err != nil {
+		return err
+	}
+	return c.client.Apply(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient.
+func (c *namespacePolicyClient) Status() SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClientConstructor.
+func (c *namespacePolicyClient) SubResource(subResource string) SubResourceClient {
+	return &namespacePolicySubResourceClient{client: c, wrapped: c.client.SubResource(subResource)}
+}
+
+var _ SubResourceClient = &namespacePolicySubResourceClient{}
+var _ SubResourceStreamer = &namespacePolicySubResourceClient{}
+
+// namespacePolicySubResourceClient is a SubResourceClient that rejects
+// requests outside of a NamespacePolicy. The policy is checked for the
+// object the subresource belongs to.
+type namespacePolicySubResourceClient struct {
+	client  *namespacePolicyClient
+	wrapped SubResourceClient
+}
+
+// Get implements client.SubResourceReader.
+func (sc *namespacePolicySubResourceClient) Get(ctx context.Context, obj, subResource Object, opts ...SubResourceGetOption) error {
+	if err := sc.client.checkObject(obj, obj.GetNamespace(), obj.GetName(), false); err != nil {
+		return err
+	}
+	return sc.wrapped.Get(ctx, obj, subResource, opts...)
+}
+
+// Stream implements client.SubResourceStreamer.
+func (sc *namespacePolicySubResourceClient) Stream(ctx context.Context, obj Object, opts ...SubResourceStreamOption) (io.ReadCloser, error) {
+	if err := sc.client.checkObject(obj, obj.GetNamespace(), obj.GetName(), false); err != nil {
+		return nil, err
+	}
+	return StreamSubResource(ctx, sc.wrapped, obj, opts...)
+}
+
+// Create implements client.SubResourceWriter.
+func (sc *namespacePolicySubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
+	if err := sc.client.checkObject(obj, obj.GetNamespace(), obj.GetName(), true); err != nil {
+		return err
+	}
+	return sc.wrapped.Create(ctx, obj, subResource, opts...)
+}
+
+// Update implements client.SubResourceWriter.
+func (sc *namespacePolicySubResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) error {
+	if err := sc.client.checkObject(obj, obj.GetNamespace(), obj.GetName(), true); err != nil {
+		return err
+	}
+	return sc.wrapped.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.SubResourceWriter.
+func (sc *namespacePolicySubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
+	if err := sc.client.checkObject(obj, obj.GetNamespace(), obj.GetName(), true); err != nil {
+		return err
+	}
+	return sc.wrapped.Patch(ctx, obj, patch, opts...)
+}
+
+// Apply implements client.SubResourceWriter.
+func (sc *namespacePolicySubResourceClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...SubResourceApplyOption) error {
+	if err := sc.client.checkApplyConfiguration(obj); err != nil {
+		return err
+	}
+	return sc.wrapped.Apply(ctx, obj, opts...)
+}
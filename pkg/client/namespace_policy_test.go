@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1applyconfigurations "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("NamespacePolicyClient", func() {
+	var c client.Client
+	var mapper *meta.DefaultRESTMapper
+	ctx := context.Background()
+
+	configMap := func(namespace string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: namespace}}
+	}
+
+	BeforeEach(func() {
+		mapper = meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+		mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+		c = client.NewNamespacePolicyClient(
+			fake.NewClientBuilder().WithRESTMapper(mapper).WithObjects(configMap("allowed"), configMap("other")).Build(),
+			client.NamespacePolicy{
+				Namespaces:          []string{"allowed"},
+				ClusterScopedWrites: []schema.GroupKind{{Group: rbacv1.GroupName, Kind: "ClusterRole"}},
+			},
+		)
+	})
+
+	It("should permit reads and writes in allowed namespaces", func() {
+		cm := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "allowed", Name: "cm"}, cm)).To(Succeed())
+		Expect(c.List(ctx, &corev1.ConfigMapList{}, client.InNamespace("allowed"))).To(Succeed())
+
+		cm.Data = map[string]string{"a": "1"}
+		Expect(c.Update(ctx, cm)).To(Succeed())
+		Expect(c.Apply(ctx, corev1applyconfigurations.ConfigMap("applied", "allowed"), client.FieldOwner("test"))).To(Succeed())
+		Expect(c.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("allowed"))).To(Succeed())
+	})
+
+	It("should forbid reads and writes in other namespaces", func() {
+		err := c.Get(ctx, client.ObjectKey{Namespace: "other", Name: "cm"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`namespace "other" is not permitted`))
+
+		Expect(apierrors.IsForbidden(c.Create(ctx, configMap("new")))).To(BeTrue())
+		Expect(apierrors.IsForbidden(c.Delete(ctx, configMap("other")))).To(BeTrue())
+		Expect(apierrors.IsForbidden(c.Patch(ctx, configMap("other"), client.MergeFrom(configMap("other"))))).To(BeTrue())
+		Expect(apierrors.IsForbidden(c.Apply(ctx, corev1applyconfigurations.ConfigMap("applied", "other"), client.FieldOwner("test")))).To(BeTrue())
+		Expect(apierrors.IsForbidden(c.Status().Update(ctx, configMap("other")))).To(BeTrue())
+	})
+
+	It("should forbid lists and deletes across all namespaces", func() {
+		Expect(apierrors.IsForbidden(c.List(ctx, &corev1.ConfigMapList{}))).To(BeTrue())
+		Expect(apierrors.IsForbidden(c.DeleteAllOf(ctx, &corev1.ConfigMap{}))).To(BeTrue())
+	})
+
+	It("should permit reads of cluster-scoped objects and only permitted writes", func() {
+		Expect(c.List(ctx, &corev1.NamespaceList{})).To(Succeed())
+		Expect(apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Name: "foo"}, &corev1.Namespace{}))).To(BeTrue())
+
+		err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("writes of cluster-scoped Namespace are not permitted"))
+
+		Expect(c.Create(ctx, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})).To(Succeed())
+	})
+
+	It("should be enabled by the client options", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Fail("request should have been rejected by the client")
+		}))
+		defer server.Close()
+
+		c, err := client.New(&rest.Config{Host: server.URL}, client.Options{
+			Mapper:          mapper,
+			NamespacePolicy: &client.NamespacePolicy{Namespaces: []string{"allowed"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apierrors.IsForbidden(c.Create(ctx, configMap("other")))).To(BeTrue())
+	})
+})
@@ -19,10 +19,13 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/internal/applyconfiguration"
 )
 
 // NewNamespacedClient wraps an existing client enforcing the namespace value.
@@ -147,6 +150,37 @@ func (n *namespacedClient) Patch(ctx context.Context, obj Object, patch Patch, o
 	return n.client.Patch(ctx, obj, patch, opts...)
 }
 
+// Apply implements client.Client.
+func (n *namespacedClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) error {
+	if err := enforceApplyConfigurationNamespace(obj, n.namespace, n.RESTMapper()); err != nil {
+		return err
+	}
+	return n.client.Apply(ctx, obj, opts...)
+}
+
+// enforceApplyConfigurationNamespace validates the namespace of the given apply
+// configuration against the namespace of the client, defaulting it for namespaced objects.
+func enforceApplyConfigurationNamespace(obj ApplyConfiguration, namespace string, restMapper meta.RESTMapper) error {
+	u, err := applyconfiguration.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	isNamespaceScoped, err := apiutil.IsGVKNamespaced(u.GroupVersionKind(), restMapper)
+	if err != nil {
+		return fmt.Errorf("error finding the scope of the object: %w", err)
+	}
+
+	objectNamespace := u.GetNamespace()
+	if objectNamespace != namespace && objectNamespace != "" {
+		return fmt.Errorf("namespace %s of the object %s does not match the namespace %s on the client", objectNamespace, u.GetName(), namespace)
+	}
+
+	if isNamespaceScoped && objectNamespace == "" {
+		return setApplyConfigurationNamespace(obj, namespace)
+	}
+	return nil
+}
+
 // Get implements client.Client.
 func (n *namespacedClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
 	isNamespaceScoped, err := n.IsObjectNamespaced(obj)
@@ -182,6 +216,7 @@ func (n *namespacedClient) SubResource(subResource string) SubResourceClient {
 
 // ensure namespacedClientSubResourceClient implements client.SubResourceClient.
 var _ SubResourceClient = &namespacedClientSubResourceClient{}
+var _ SubResourceStreamer = &namespacedClientSubResourceClient{}
 
 type namespacedClientSubResourceClient struct {
 	client           SubResourceClient
@@ -207,6 +242,24 @@ func (nsw *namespacedClientSubResourceClient) Get(ctx context.Context, obj, subR
 	return nsw.client.Get(ctx, obj, subResource, opts...)
 }
 
+func (nsw *namespacedClientSubResourceClient) Stream(ctx context.Context, obj Object, opts ...SubResourceStreamOption) (io.ReadCloser, error) {
+	isNamespaceScoped, err := nsw.namespacedclient.IsObjectNamespaced(obj)
+	if err != nil {
+		return nil, fmt.Errorf("error finding the scope of the object: %w", err)
+	}
+
+	objectNamespace := obj.GetNamespace()
+	if objectNamespace != nsw.namespace && objectNamespace != "" {
+		return nil, fmt.Errorf("namespace %s of the object %s does not match the namespace %s on the client", objectNamespace, obj.GetName(), nsw.namespace)
+	}
+
+	if isNamespaceScoped && objectNamespace == "" {
+		obj.SetNamespace(nsw.namespace)
+	}
+
+	return StreamSubResource(ctx, nsw.client, obj, opts...)
+}
+
 func (nsw *namespacedClientSubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
 	isNamespaceScoped, err := nsw.namespacedclient.IsObjectNamespaced(obj)
 	if err != nil {
@@ -260,3 +313,11 @@ func (nsw *namespacedClientSubResourceClient) Patch(ctx context.Context, obj Obj
 	}
 	return nsw.client.Patch(ctx, obj, patch, opts...)
 }
+
+// Apply implements client.SubResourceWriter.
+func (nsw *namespacedClientSubResourceClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...SubResourceApplyOption) error {
+	if err := enforceApplyConfigurationNamespace(obj, nsw.namespace, nsw.namespacedclient.RESTMapper()); err != nil {
+		return err
+	}
+	return nsw.client.Apply(ctx, obj, opts...)
+}
@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -409,6 +410,35 @@ var _ = Describe("NamespacedClient", func() {
 		})
 	})
 
+	Describe("Apply", func() {
+		var err error
+		BeforeEach(func() {
+			dep, err = clientset.AppsV1().Deployments(ns).Create(ctx, dep, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			deleteDeployment(ctx, dep, ns)
+		})
+
+		It("should successfully apply the object when namespace is not provided", func() {
+			deployment := appsv1ac.Deployment(dep.Name, "").WithAnnotations(map[string]string{"foo": "bar"})
+			err = getClient().Apply(ctx, deployment, client.FieldOwner("test-owner"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*deployment.Namespace).To(Equal(ns))
+
+			actual, err := clientset.AppsV1().Deployments(ns).Get(ctx, dep.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actual.Annotations["foo"]).To(Equal("bar"))
+		})
+
+		It("should not apply the object when namespace of the object is different", func() {
+			deployment := appsv1ac.Deployment(dep.Name, "non-default").WithAnnotations(map[string]string{"foo": "bar"})
+			err = getClient().Apply(ctx, deployment, client.FieldOwner("test-owner"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("Delete and DeleteAllOf", func() {
 		var err error
 		BeforeEach(func() {
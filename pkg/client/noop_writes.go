@@ -0,0 +1,77 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/internal/metrics"
+)
+
+// isNoOpUpdate returns whether the update of obj can be skipped, because obj
+// semantically equals the cached object. Any error reading the cached object
+// results in the update being sent.
+func (c *client) isNoOpUpdate(ctx context.Context, obj Object, operation string) bool {
+	if !c.skipNoOpWrites {
+		return false
+	}
+	if isUncached, err := c.shouldBypassCache(obj); err != nil || isUncached {
+		return false
+	}
+
+	cached := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(Object)
+	gvk, err := c.GroupVersionKindFor(obj)
+	if err != nil {
+		return false
+	}
+	cached.GetObjectKind().SetGroupVersionKind(gvk)
+	if err := c.cache.Get(ctx, ObjectKeyFromObject(obj), cached); err != nil {
+		return false
+	}
+	// Whether the type information is set doesn't change the object.
+	cached.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	if !equality.Semantic.DeepEqual(obj, cached) {
+		return false
+	}
+
+	metrics.SkippedNoOpWrites.WithLabelValues(gvk.Group, gvk.Kind, operation).Inc()
+	return true
+}
+
+// isNoOpPatch returns whether the given patch of obj can be skipped, because it
+// is a merge patch computed by MergeFrom or StrategicMergeFrom that is empty.
+func (c *client) isNoOpPatch(obj Object, patch Patch, operation string) bool {
+	if !c.skipNoOpWrites {
+		return false
+	}
+	if _, ok := patch.(*mergeFromPatch); !ok {
+		return false
+	}
+	data, err := patch.Data(obj)
+	if err != nil || !bytes.Equal(bytes.TrimSpace(data), []byte("{}")) {
+		return false
+	}
+
+	if gvk, err := c.GroupVersionKindFor(obj); err == nil {
+		metrics.SkippedNoOpWrites.WithLabelValues(gvk.Group, gvk.Kind, operation).Inc()
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/internal/metrics"
+)
+
+var _ = Describe("ClientWithSkipNoOpWrites", func() {
+	var server *httptest.Server
+	var mu sync.Mutex
+	var requests []string
+	var cm *corev1.ConfigMap
+	var c client.Client
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		requests = nil
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+			Data:       map[string]string{"a": "1"},
+		}
+		cache := fake.NewClientBuilder().WithObjects(cm).Build()
+		Expect(cache.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+
+		// The server always returns the configmap as it is in the cache.
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests = append(requests, r.Method)
+			mu.Unlock()
+
+			current := &corev1.ConfigMap{}
+			Expect(cache.Get(ctx, client.ObjectKeyFromObject(cm), current)).To(Succeed())
+			current.APIVersion, current.Kind = "v1", "ConfigMap"
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(current)).To(Succeed())
+		}))
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+		var err error
+		c, err = client.New(&rest.Config{Host: server.URL}, client.Options{
+			Mapper:         mapper,
+			Cache:          &client.CacheOptions{Reader: cache},
+			SkipNoOpWrites: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should skip updates of objects that equal the cached object", func() {
+		skipped := testutil.ToFloat64(metrics.SkippedNoOpWrites.WithLabelValues("", "ConfigMap", "Update"))
+
+		Expect(c.Update(ctx, cm.DeepCopy())).To(Succeed())
+		Expect(requests).To(BeEmpty())
+		Expect(testutil.ToFloat64(metrics.SkippedNoOpWrites.WithLabelValues("", "ConfigMap", "Update"))).To(Equal(skipped + 1))
+	})
+
+	It("should send updates of changed objects", func() {
+		updated := cm.DeepCopy()
+		updated.Data["a"] = "2"
+		Expect(c.Update(ctx, updated)).To(Succeed())
+		Expect(requests).To(Equal([]string{http.MethodPut}))
+	})
+
+	It("should skip status updates of objects that equal the cached object", func() {
+		Expect(c.Status().Update(ctx, cm.DeepCopy())).To(Succeed())
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("should skip empty merge patches and return the current object", func() {
+		skipped := testutil.ToFloat64(metrics.SkippedNoOpWrites.WithLabelValues("", "ConfigMap", "Patch"))
+
+		obj := cm.DeepCopy()
+		obj.Annotations = map[string]string{"stale": "true"}
+		patch := client.MergeFrom(obj.DeepCopy())
+		Expect(c.Patch(ctx, obj, patch)).To(Succeed())
+		Expect(requests).To(BeEmpty())
+		Expect(obj.Annotations).To(BeEmpty())
+		Expect(obj.Data).To(Equal(cm.Data))
+		Expect(testutil.ToFloat64(metrics.SkippedNoOpWrites.WithLabelValues("", "ConfigMap", "Patch"))).To(Equal(skipped + 1))
+
+		Expect(c.Patch(ctx, cm.DeepCopy(), client.StrategicMergeFrom(cm.DeepCopy()))).To(Succeed())
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("should send non-empty patches", func() {
+		obj := cm.DeepCopy()
+		patch := client.MergeFrom(obj.DeepCopy())
+		obj.Data["a"] = "2"
+		Expect(c.Patch(ctx, obj, patch)).To(Succeed())
+		Expect(c.Patch(ctx, cm.DeepCopy(), client.RawPatch("application/merge-patch+json", []byte("{}")))).To(Succeed())
+		Expect(c.Patch(ctx, cm.DeepCopy(), client.MergeFromWithOptions(cm.DeepCopy(), client.MergeFromWithOptimisticLock{}))).To(Succeed())
+		Expect(requests).To(Equal([]string{http.MethodPatch, http.MethodPatch, http.MethodPatch}))
+	})
+})
@@ -0,0 +1,148 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefaultPageSize is the number of objects a ListPager requests per page
+// if neither its PageSize nor a Limit list option is set.
+const DefaultPageSize = 500
+
+// ListPager lists objects page by page, following the continue token of each
+// page until the list is complete. It works with typed and unstructured lists
+// as well as with metav1.PartialObjectMetadataList.
+//
+// Readers backed by a cache, like the client of a manager, page through the
+// objects in the cache ordered by namespace and name. Unlike the pages of the
+// API server, they are not a consistent snapshot: objects that are created or
+// deleted while paging may or may not be listed.
+type ListPager struct {
+	// Reader is used to list the objects.
+	Reader Reader
+
+	// PageSize is the maximum number of objects requested per page.
+	// Defaults to the Limit list option passed to EachPage and EachItem, or
+	// DefaultPageSize if that isn't set either.
+	PageSize int64
+
+	// FullListIfExpired makes the pager fall back to listing all objects in a
+	// single, unpaginated request if the continue token expired while paging,
+	// instead of returning the 410 Gone error of the server. As the full list
+	// starts from the beginning, the callback may be called again for objects it
+	// has already been called for.
+	FullListIfExpired bool
+}
+
+// ListPages lists the objects matching opts page by page using the given Reader,
+// see ListPager.EachPage.
+func ListPages(ctx context.Context, reader Reader, list ObjectList, fn func(ObjectList) error, opts ...ListOption) error {
+	return (&ListPager{Reader: reader}).EachPage(ctx, list, fn, opts...)
+}
+
+// ListItems lists the objects matching opts page by page using the given Reader,
+// see ListPager.EachItem.
+func ListItems(ctx context.Context, reader Reader, list ObjectList, fn func(Object) error, opts ...ListOption) error {
+	return (&ListPager{Reader: reader}).EachItem(ctx, list, fn, opts...)
+}
+
+// EachPage lists the objects matching opts, filling list with one page after
+// the other and calling fn with it. Listing starts at the Continue list option,
+// if any. If fn returns an error, listing stops and the error is returned.
+//
+// list is reused for every page, so it must not be retained by fn beyond the
+// call; DeepCopy it if necessary.
+func (p *ListPager) EachPage(ctx context.Context, list ObjectList, fn func(ObjectList) error, opts ...ListOption) error {
+	listOpts := (&ListOptions{}).ApplyOptions(opts)
+	pageSize := p.PageSize
+	if pageSize <= 0 {
+		pageSize = listOpts.Limit
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	continueToken := listOpts.Continue
+	for {
+		// Decoders don't necessarily reset the list they decode into, so
+		// make sure no items or continue token of the last page survive.
+		if err := resetList(list); err != nil {
+			return err
+		}
+		err := p.Reader.List(ctx, list, withListOptions(opts, Limit(pageSize), Continue(continueToken))...)
+		if err != nil {
+			if !p.FullListIfExpired || continueToken == "" || !apierrors.IsResourceExpired(err) {
+				return err
+			}
+			// The continue token expired, list everything at once instead.
+			if err := resetList(list); err != nil {
+				return err
+			}
+			if err := p.Reader.List(ctx, list, withListOptions(opts, Limit(0), Continue(""))...); err != nil {
+				return err
+			}
+			return fn(list)
+		}
+
+		if err := fn(list); err != nil {
+			return err
+		}
+
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
+
+// withListOptions returns a new slice with opts followed by extra. Appending
+// to opts directly could write into the backing array of the caller.
+func withListOptions(opts []ListOption, extra ...ListOption) []ListOption {
+	result := make([]ListOption, 0, len(opts)+len(extra))
+	result = append(result, opts...)
+	return append(result, extra...)
+}
+
+// EachItem lists the objects matching opts page by page like EachPage and
+// calls fn with every item of every page. If fn returns an error, listing
+// stops and the error is returned.
+//
+// The items are reused for every page, so they must not be retained by fn
+// beyond the call; DeepCopy them if necessary.
+func (p *ListPager) EachItem(ctx context.Context, list ObjectList, fn func(Object) error, opts ...ListOption) error {
+	return p.EachPage(ctx, list, func(page ObjectList) error {
+		return meta.EachListItem(page, func(item runtime.Object) error {
+			obj, ok := item.(Object)
+			if !ok {
+				return fmt.Errorf("list item %T does not implement client.Object", item)
+			}
+			return fn(obj)
+		})
+	}, opts...)
+}
+
+// resetList removes the items and the continue token from the given list.
+func resetList(list ObjectList) error {
+	list.SetContinue("")
+	return meta.SetList(list, nil)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("ListPager", func() {
+	var c client.Client
+	var requests []client.ListOptions
+	var expireContinue bool
+	ctx := context.Background()
+
+	// pagedList serves pages of the objects of the fake client, using the
+	// index of the first item of the next page as continue token.
+	pagedList := func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+		listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+		requests = append(requests, *listOpts)
+		if expireContinue && listOpts.Continue != "" {
+			return apierrors.NewResourceExpired("continue token expired")
+		}
+
+		if err := c.List(ctx, list, opts...); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].(client.Object).GetName() < items[j].(client.Object).GetName()
+		})
+
+		start := 0
+		if listOpts.Continue != "" {
+			if start, err = strconv.Atoi(listOpts.Continue); err != nil {
+				return err
+			}
+		}
+		end := len(items)
+		if listOpts.Limit > 0 && start+int(listOpts.Limit) < end {
+			end = start + int(listOpts.Limit)
+			list.SetContinue(strconv.Itoa(end))
+		}
+		return meta.SetList(list, items[start:end])
+	}
+
+	BeforeEach(func() {
+		requests = nil
+		expireContinue = false
+
+		var objs []client.Object
+		for i := 0; i < 5; i++ {
+			objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm-%d", i), Namespace: "default"}})
+		}
+		objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"}})
+		c = interceptor.NewClient(fake.NewClientBuilder().WithObjects(objs...).Build(), interceptor.Funcs{List: pagedList})
+	})
+
+	It("should call the function for every page", func() {
+		pager := &client.ListPager{Reader: c, PageSize: 2}
+
+		var pages [][]string
+		err := pager.EachPage(ctx, &corev1.ConfigMapList{}, func(page client.ObjectList) error {
+			var names []string
+			for _, cm := range page.(*corev1.ConfigMapList).Items {
+				names = append(names, cm.Name)
+			}
+			pages = append(pages, names)
+			return nil
+		}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pages).To(Equal([][]string{{"cm-0", "cm-1"}, {"cm-2", "cm-3"}, {"cm-4"}}))
+
+		Expect(requests).To(HaveLen(3))
+		for _, req := range requests {
+			Expect(req.Namespace).To(Equal("default"))
+			Expect(req.Limit).To(BeEquivalentTo(2))
+		}
+		Expect(requests[0].Continue).To(BeEmpty())
+		Expect(requests[1].Continue).To(Equal("2"))
+		Expect(requests[2].Continue).To(Equal("4"))
+	})
+
+	It("should call the function for every item", func() {
+		var names []string
+		err := client.ListItems(ctx, c, &corev1.ConfigMapList{}, func(obj client.Object) error {
+			names = append(names, obj.GetName())
+			return nil
+		}, client.InNamespace("default"), client.Limit(3))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"cm-0", "cm-1", "cm-2", "cm-3", "cm-4"}))
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0].Limit).To(BeEquivalentTo(3))
+	})
+
+	It("should not modify the options of the caller", func() {
+		opts := make([]client.ListOption, 1, 4)
+		opts[0] = client.InNamespace("default")
+		pager := &client.ListPager{Reader: c, PageSize: 2}
+		Expect(pager.EachPage(ctx, &corev1.ConfigMapList{}, func(client.ObjectList) error { return nil }, opts...)).To(Succeed())
+		Expect(opts[:cap(opts)]).To(Equal([]client.ListOption{client.InNamespace("default"), nil, nil, nil}))
+	})
+
+	It("should default the page size", func() {
+		Expect(client.ListPages(ctx, c, &corev1.ConfigMapList{}, func(client.ObjectList) error { return nil })).To(Succeed())
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Limit).To(BeEquivalentTo(client.DefaultPageSize))
+	})
+
+	It("should start at the given continue token", func() {
+		var names []string
+		err := client.ListItems(ctx, c, &corev1.ConfigMapList{}, func(obj client.Object) error {
+			names = append(names, obj.GetName())
+			return nil
+		}, client.InNamespace("default"), client.Limit(2), client.Continue("2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"cm-2", "cm-3", "cm-4"}))
+	})
+
+	It("should list unstructured objects", func() {
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion("v1")
+		list.SetKind("ConfigMapList")
+
+		var names []string
+		err := (&client.ListPager{Reader: c, PageSize: 2}).EachItem(ctx, list, func(obj client.Object) error {
+			Expect(obj).To(BeAssignableToTypeOf(&unstructured.Unstructured{}))
+			names = append(names, obj.GetName())
+			return nil
+		}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"cm-0", "cm-1", "cm-2", "cm-3", "cm-4"}))
+	})
+
+	It("should list metadata only objects", func() {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMapList"))
+
+		var names []string
+		err := (&client.ListPager{Reader: c, PageSize: 2}).EachItem(ctx, list, func(obj client.Object) error {
+			Expect(obj).To(BeAssignableToTypeOf(&metav1.PartialObjectMetadata{}))
+			names = append(names, obj.GetName())
+			return nil
+		}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"cm-0", "cm-1", "cm-2", "cm-3", "cm-4"}))
+	})
+
+	It("should stop listing if the function returns an error", func() {
+		stop := errors.New("stop")
+		var names []string
+		err := (&client.ListPager{Reader: c, PageSize: 2}).EachItem(ctx, &corev1.ConfigMapList{}, func(obj client.Object) error {
+			names = append(names, obj.GetName())
+			if len(names) == 3 {
+				return stop
+			}
+			return nil
+		}, client.InNamespace("default"))
+		Expect(err).To(MatchError(stop))
+		Expect(names).To(Equal([]string{"cm-0", "cm-1", "cm-2"}))
+		Expect(requests).To(HaveLen(2))
+	})
+
+	It("should return the error if the continue token expired", func() {
+		expireContinue = true
+
+		var names []string
+		err := (&client.ListPager{Reader: c, PageSize: 2}).EachItem(ctx, &corev1.ConfigMapList{}, func(obj client.Object) error {
+			names = append(names, obj.GetName())
+			return nil
+		}, client.InNamespace("default"))
+		Expect(apierrors.IsResourceExpired(err)).To(BeTrue())
+		Expect(names).To(Equal([]string{"cm-0", "cm-1"}))
+	})
+
+	It("should fall back to a full list if the continue token expired and FullListIfExpired is set", func() {
+		expireContinue = true
+
+		var names []string
+		err := (&client.ListPager{Reader: c, PageSize: 2, FullListIfExpired: true}).EachItem(ctx, &corev1.ConfigMapList{}, func(obj client.Object) error {
+			names = append(names, obj.GetName())
+			return nil
+		}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"cm-0", "cm-1", "cm-0", "cm-1", "cm-2", "cm-3", "cm-4"}))
+
+		Expect(requests).To(HaveLen(3))
+		Expect(requests[2].Limit).To(BeZero())
+		Expect(requests[2].Continue).To(BeEmpty())
+	})
+})
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package podexec runs commands in the containers of pods through their exec
+// and attach subresources, like kubectl exec and kubectl attach.
+//
+// Use the fake package of the client to run commands in tests.
+package podexec
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Streams are the streams connected to a command. Stdin, Stdout and Stderr
+// may be nil if the command doesn't use them.
+type Streams = remotecommand.StreamOptions
+
+// Executor runs commands in the containers of pods.
+type Executor interface {
+	// Exec runs a command in a container of the pod and blocks until it exits.
+	// Only the namespace and name of pod are used. The Stdin, Stdout, Stderr
+	// and TTY fields of opts are set from streams.
+	//
+	// If the command exits with a non-zero code, the returned error is a
+	// k8s.io/client-go/util/exec.ExitError.
+	Exec(ctx context.Context, pod client.Object, opts *corev1.PodExecOptions, streams Streams) error
+
+	// Attach attaches to the main process of a container of the pod and blocks
+	// until it exits. Only the namespace and name of pod are used. The Stdin,
+	// Stdout, Stderr and TTY fields of opts are set from streams.
+	Attach(ctx context.Context, pod client.Object, opts *corev1.PodAttachOptions, streams Streams) error
+}
+
+// Protocol is a protocol used to stream to and from commands.
+type Protocol string
+
+const (
+	// ProtocolSPDY streams using SPDY, which is supported by all API servers.
+	ProtocolSPDY Protocol = "SPDY"
+
+	// ProtocolWebSocket streams using WebSockets, e.g. to pass through proxies
+	// that don't support SPDY. The closing of stdin is not sent to the
+	// command, so commands that read stdin until it is closed don't exit.
+	ProtocolWebSocket Protocol = "WebSocket"
+)
+
+// Options are the options of an Executor.
+type Options struct {
+	// Protocol is the protocol to stream with. Defaults to ProtocolSPDY.
+	Protocol Protocol
+
+	// HTTPClient is the HTTP client used to build the URLs of the requests.
+	// Defaults to rest.HTTPClientFor(config). The streams themselves use
+	// their own connections.
+	HTTPClient *http.Client
+}
+
+// New returns an Executor that runs commands using the given config.
+func New(config *rest.Config, opts Options) (Executor, error) {
+	if config == nil {
+		return nil, fmt.Errorf("must provide non-nil rest.Config to podexec.New")
+	}
+
+	switch opts.Protocol {
+	case "":
+		opts.Protocol = ProtocolSPDY
+	case ProtocolSPDY, ProtocolWebSocket:
+	default:
+		return nil, fmt.Errorf("unknown protocol %q", opts.Protocol)
+	}
+
+	if opts.HTTPClient == nil {
+		var err error
+		opts.HTTPClient, err = rest.HTTPClientFor(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	restClient, err := apiutil.RESTClientForGVK(corev1.SchemeGroupVersion.WithKind("Pod"), false, config, scheme.Codecs, opts.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &executor{config: config, protocol: opts.Protocol, restClient: restClient}, nil
+}
+
+type executor struct {
+	config     *rest.Config
+	protocol   Protocol
+	restClient rest.Interface
+}
+
+// Exec implements Executor.
+func (e *executor) Exec(ctx context.Context, pod client.Object, opts *corev1.PodExecOptions, streams Streams) error {
+	opts = opts.DeepCopy()
+	opts.Stdin, opts.Stdout, opts.Stderr, opts.TTY = streams.Stdin != nil, streams.Stdout != nil, streams.Stderr != nil, streams.Tty
+	return e.stream(ctx, pod, "exec", opts, streams)
+}
+
+// Attach implements Executor.
+func (e *executor) Attach(ctx context.Context, pod client.Object, opts *corev1.PodAttachOptions, streams Streams) error {
+	opts = opts.DeepCopy()
+	opts.Stdin, opts.Stdout, opts.Stderr, opts.TTY = streams.Stdin != nil, streams.Stdout != nil, streams.Stderr != nil, streams.Tty
+	return e.stream(ctx, pod, "attach", opts, streams)
+}
+
+// stream connects streams to the given subresource of pod.
+func (e *executor) stream(ctx context.Context, pod client.Object, subResource string, opts runtime.Object, streams Streams) error {
+	u := e.restClient.Post().
+		Namespace(pod.GetNamespace()).
+		Resource("pods").
+		Name(pod.GetName()).
+		SubResource(subResource).
+		VersionedParams(opts, scheme.ParameterCodec).
+		URL()
+
+	if e.protocol == ProtocolWebSocket {
+		return streamWebSocket(ctx, e.config, u, streams)
+	}
+	return streamSPDY(ctx, e.config, u, streams)
+}
+
+func streamSPDY(ctx context.Context, config *rest.Config, u *url.URL, streams Streams) error {
+	exec, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, u)
+	if err != nil {
+		return err
+	}
+	return exec.StreamWithContext(ctx, streams)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podexec_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/exec"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/podexec"
+)
+
+var _ = Describe("Executor", func() {
+	var server *httptest.Server
+	var requests []*http.Request
+	var status metav1.Status
+	ctx := context.Background()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+
+	BeforeEach(func() {
+		requests = nil
+		status = metav1.Status{Status: metav1.StatusSuccess}
+
+		// The server echoes the first message on stdin to stdout, writes the
+		// command to stderr and then exits with the status.
+		upgrader := websocket.Upgrader{Subprotocols: []string{"v4.channel.k8s.io"}}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			if r.URL.Query().Get("stdin") == "true" {
+				_, msg, err := conn.ReadMessage()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(msg[0]).To(BeEquivalentTo(0))
+				Expect(conn.WriteMessage(websocket.BinaryMessage, append([]byte{1}, msg[1:]...))).To(Succeed())
+			}
+			command := strings.Join(r.URL.Query()["command"], " ")
+			Expect(conn.WriteMessage(websocket.BinaryMessage, append([]byte{2}, command...))).To(Succeed())
+
+			data, err := json.Marshal(status)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.WriteMessage(websocket.BinaryMessage, append([]byte{3}, data...))).To(Succeed())
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should run commands over websockets", func() {
+		executor, err := podexec.New(&rest.Config{Host: server.URL, BearerToken: "token"}, podexec.Options{Protocol: podexec.ProtocolWebSocket})
+		Expect(err).NotTo(HaveOccurred())
+
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		err = executor.Exec(ctx, pod, &corev1.PodExecOptions{Container: "app", Command: []string{"cat", "-"}}, podexec.Streams{
+			Stdin:  strings.NewReader("hello"),
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("hello"))
+		Expect(stderr.String()).To(Equal("cat -"))
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.Path).To(Equal("/api/v1/namespaces/default/pods/pod/exec"))
+		Expect(requests[0].URL.Query()).To(HaveKeyWithValue("container", []string{"app"}))
+		Expect(requests[0].URL.Query()).To(HaveKeyWithValue("stdout", []string{"true"}))
+		Expect(requests[0].URL.Query()).NotTo(HaveKey("tty"))
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+	})
+
+	It("should return the exit code of failed commands", func() {
+		status = metav1.Status{
+			Status: metav1.StatusFailure,
+			Reason: "NonZeroExitCode",
+			Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{{
+				Type:    "ExitCode",
+				Message: "3",
+			}}},
+		}
+		executor, err := podexec.New(&rest.Config{Host: server.URL}, podexec.Options{Protocol: podexec.ProtocolWebSocket})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = executor.Attach(ctx, pod, &corev1.PodAttachOptions{}, podexec.Streams{Stdout: &bytes.Buffer{}})
+		var exitErr exec.ExitError
+		Expect(errors.As(err, &exitErr)).To(BeTrue())
+		Expect(exitErr.ExitStatus()).To(Equal(3))
+		Expect(requests[0].URL.Path).To(Equal("/api/v1/namespaces/default/pods/pod/attach"))
+	})
+
+	It("should reject unknown protocols", func() {
+		_, err := podexec.New(&rest.Config{Host: server.URL}, podexec.Options{Protocol: "HTTP/3"})
+		Expect(err).To(HaveOccurred())
+	})
+})
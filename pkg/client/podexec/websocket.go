@@ -0,0 +1,247 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	remotecommandconsts "k8s.io/apimachinery/pkg/util/remotecommand"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/exec"
+)
+
+// channelProtocolV4 is the WebSocket subprotocol of the API server in which
+// every message is prefixed with the channel it belongs to.
+const channelProtocolV4 = "v4.channel.k8s.io"
+
+// The channels of channelProtocolV4.
+const (
+	stdinChannel byte = iota
+	stdoutChannel
+	stderrChannel
+	errorChannel
+	resizeChannel
+)
+
+// streamWebSocket connects streams to the command at u using WebSockets.
+func streamWebSocket(ctx context.Context, config *rest.Config, u *url.URL, streams Streams) error {
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return err
+	}
+	headers, err := requestHeaders(config, u)
+	if err != nil {
+		return err
+	}
+
+	dialer := &websocket.Dialer{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+		Subprotocols:    []string{channelProtocolV4},
+	}
+	if config.Proxy != nil {
+		dialer.Proxy = config.Proxy
+	}
+
+	wsURL := *u
+	switch wsURL.Scheme {
+	case "https":
+		wsURL.Scheme = "wss"
+	case "http":
+		wsURL.Scheme = "ws"
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, wsURL.String(), headers)
+	if err != nil {
+		if resp != nil {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+			return fmt.Errorf("failed to open websocket: %s: %s", resp.Status, body)
+		}
+		return fmt.Errorf("failed to open websocket: %w", err)
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != channelProtocolV4 {
+		return fmt.Errorf("server does not support the %s websocket protocol", channelProtocolV4)
+	}
+
+	return runWebSocket(ctx, conn, streams)
+}
+
+// requestHeaders returns the headers the transport of config sets on requests
+// to u, e.g. for authentication and impersonation.
+func requestHeaders(config *rest.Config, u *url.URL) (http.Header, error) {
+	var headers http.Header
+	rt, err := rest.HTTPWrappersForConfig(config, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		headers = req.Header.Clone()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return headers, nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// runWebSocket copies streams to and from conn until the command exits.
+func runWebSocket(ctx context.Context, conn *websocket.Conn, streams Streams) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	var writeLock sync.Mutex
+	write := func(channel byte, data []byte) error {
+		writeLock.Lock()
+		defer writeLock.Unlock()
+		return conn.WriteMessage(websocket.BinaryMessage, append([]byte{channel}, data...))
+	}
+
+	// Like with SPDY, these goroutines are blocked in Read and Next until
+	// stdin is closed and the queue is drained, even after the command exited.
+	if streams.Stdin != nil {
+		go func() {
+			buf := make([]byte, 32*1024)
+			for {
+				n, err := streams.Stdin.Read(buf)
+				if n > 0 {
+					if write(stdinChannel, buf[:n]) != nil {
+						return
+					}
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	if streams.TerminalSizeQueue != nil {
+		go func() {
+			for {
+				size := streams.TerminalSizeQueue.Next()
+				if size == nil {
+					return
+				}
+				data, err := json.Marshal(size)
+				if err != nil || write(resizeChannel, data) != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+				return nil
+			}
+			return err
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		var out io.Writer
+		switch data[0] {
+		case stdoutChannel:
+			out = streams.Stdout
+		case stderrChannel:
+			out = streams.Stderr
+		case errorChannel:
+			// The API server sends the status once the command exited.
+			return decodeStatus(data[1:])
+		}
+		if out != nil && len(data) > 1 {
+			if _, err := out.Write(data[1:]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// decodeStatus converts the status sent on the error channel to an error,
+// like the SPDY executor of client-go does.
+func decodeStatus(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	status := metav1.Status{}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("error stream protocol error: %w in %q", err, string(data))
+	}
+	switch status.Status {
+	case metav1.StatusSuccess:
+		return nil
+	case metav1.StatusFailure:
+		if status.Reason != remotecommandconsts.NonZeroExitCodeReason {
+			return fmt.Errorf("error executing remote command: %s", status.Message)
+		}
+		if status.Details != nil {
+			for _, cause := range status.Details.Causes {
+				if cause.Type != remotecommandconsts.ExitCodeCauseType {
+					continue
+				}
+				code, err := strconv.ParseUint(cause.Message, 10, 8)
+				if err != nil {
+					return fmt.Errorf("error stream protocol error: invalid exit code value %q", cause.Message)
+				}
+				return exec.CodeExitError{
+					Err:  fmt.Errorf("command terminated with exit code %d", code),
+					Code: int(code),
+				}
+			}
+		}
+		return fmt.Errorf("error stream protocol error: no %s cause given", remotecommandconsts.ExitCodeCauseType)
+	default:
+		return errors.New("error stream protocol error: unknown error")
+	}
+}
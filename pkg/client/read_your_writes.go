@@ -0,0 +1,431 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/controller-runtime/pkg/internal/applyconfiguration"
+)
+
+const (
+	// defaultReadYourWritesTimeout is the default time a cached read waits
+	// for the cache to observe the writes of the client.
+	defaultReadYourWritesTimeout = 5 * time.Second
+
+	// pendingWriteExpiry is the time after which a write that was never read
+	// again is assumed to have been observed by the cache and is forgotten.
+	pendingWriteExpiry = time.Minute
+
+	// cachePollInterval is the interval in which the cache is checked for
+	// pending writes.
+	cachePollInterval = 10 * time.Millisecond
+)
+
+// ScopedReader is implemented by readers that only hold some of the objects
+// of a kind, like the caches of package cache that are restricted to some
+// namespaces or by label or field selectors. Cached reads of clients with
+// ReadYourWrites enabled don't wait for writes of objects the reader doesn't
+// hold, as it never observes them.
+type ScopedReader interface {
+	Reader
+
+	// InScope returns whether the reader holds obj if it exists. obj has its
+	// GroupVersionKind, namespace, name and labels set.
+	InScope(obj Object) Scope
+}
+
+// Scope tells whether a ScopedReader holds an object.
+type Scope int
+
+const (
+	// ScopeUnknown means that it can't be decided from the metadata of an
+	// object whether the reader holds it, e.g. because the reader is restricted
+	// by a field selector on a field other than the name and namespace. Reads
+	// that would wait for writes of such objects are performed live instead.
+	ScopeUnknown Scope = iota
+	// ScopeIn means that the reader holds the object.
+	ScopeIn
+	// ScopeOut means that the reader doesn't hold the object.
+	ScopeOut
+)
+
+// writeKey identifies an object written by the client.
+type writeKey struct {
+	gvk schema.GroupVersionKind
+	key ObjectKey
+}
+
+// pendingWrite is a write the cache might not have observed yet.
+type pendingWrite struct {
+	// resourceVersion is the resourceVersion the server returned for the write.
+	resourceVersion string
+	// deleted is true if the write was a deletion.
+	deleted bool
+	// uid is the UID of the deleted object, if known.
+	uid types.UID
+	// labels are the labels of the written object, if known.
+	labels map[string]string
+	// writtenAt is the time the write happened.
+	writtenAt time.Time
+}
+
+// observedBy returns whether the given object, read from the cache, reflects
+// the write. obj is nil if the object was not found in the cache.
+func (w pendingWrite) observedBy(obj Object) bool {
+	if w.deleted {
+		return obj == nil || obj.GetDeletionTimestamp() != nil || (w.uid != "" && obj.GetUID() != w.uid)
+	}
+	return obj != nil && resourceVersionAtLeast(obj.GetResourceVersion(), w.resourceVersion)
+}
+
+// sameAs returns whether w and other are the same write.
+func (w pendingWrite) sameAs(other pendingWrite) bool {
+	return w.resourceVersion == other.resourceVersion && w.deleted == other.deleted && w.uid == other.uid && w.writtenAt.Equal(other.writtenAt)
+}
+
+// object returns the metadata of the written object.
+func (w pendingWrite) object(key writeKey) Object {
+	obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Namespace: key.key.Namespace,
+		Name:      key.key.Name,
+		Labels:    w.labels,
+	}}
+	obj.SetGroupVersionKind(key.gvk)
+	return obj
+}
+
+// resourceVersionAtLeast returns whether the observed resourceVersion is at
+// least the written one. ResourceVersions are opaque, but the ones of the
+// apiserver are integers that increase with every write; if either of them
+// isn't, only an identical resourceVersion counts as observed.
+func resourceVersionAtLeast(observed, written string) bool {
+	observedVersion, err := strconv.ParseUint(observed, 10, 64)
+	if err != nil {
+		return observed == written
+	}
+	writtenVersion, err := strconv.ParseUint(written, 10, 64)
+	if err != nil {
+		return observed == written
+	}
+	return observedVersion >= writtenVersion
+}
+
+// writeTracker remembers the writes of a client, so that reads from the cache
+// can wait until the cache has observed them.
+type writeTracker struct {
+	timeout time.Duration
+
+	mu        sync.Mutex
+	writes    map[writeKey]pendingWrite
+	lastPrune time.Time
+}
+
+func newWriteTracker(timeout time.Duration) *writeTracker {
+	if timeout <= 0 {
+		timeout = defaultReadYourWritesTimeout
+	}
+	return &writeTracker{
+		timeout:   timeout,
+		writes:    map[writeKey]pendingWrite{},
+		lastPrune: time.Now(),
+	}
+}
+
+// record remembers a write of the object with the given key.
+func (t *writeTracker) record(key writeKey, write pendingWrite) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	write.writtenAt = now
+	t.writes[key] = write
+
+	if now.Sub(t.lastPrune) < pendingWriteExpiry {
+		return
+	}
+	for k, w := range t.writes {
+		if now.Sub(w.writtenAt) > pendingWriteExpiry {
+			delete(t.writes, k)
+		}
+	}
+	t.lastPrune = now
+}
+
+// pending returns the writes of objects of the given kind the cache might not
+// have observed yet. If namespace is not empty, only writes of objects in that
+// namespace are returned.
+func (t *writeTracker) pending(gvk schema.GroupVersionKind, namespace string) map[writeKey]pendingWrite {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var writes map[writeKey]pendingWrite
+	for k, w := range t.writes {
+		if k.gvk != gvk || (namespace != "" && k.key.Namespace != namespace) {
+			continue
+		}
+		if writes == nil {
+			writes = map[writeKey]pendingWrite{}
+		}
+		writes[k] = w
+	}
+	return writes
+}
+
+// forget forgets the given write, unless the object was written again since.
+func (t *writeTracker) forget(key writeKey, write pendingWrite) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if current, ok := t.writes[key]; ok && current.sameAs(write) {
+		delete(t.writes, key)
+	}
+}
+
+// waitForCache waits until the cache has observed all the given writes. It
+// returns false if the cache didn't observe them within the timeout of the
+// tracker or can't read the written objects, in which case the caller should
+// fall back to a live read. newObj returns an empty object of the kind of the
+// writes to read from the cache into.
+func (t *writeTracker) waitForCache(ctx context.Context, cache Reader, writes map[writeKey]pendingWrite, newObj func() (Object, error)) (bool, error) {
+	if len(writes) == 0 {
+		return true, nil
+	}
+
+	var unobservable bool
+	err := wait.PollUntilContextTimeout(ctx, cachePollInterval, t.timeout, true, func(ctx context.Context) (bool, error) {
+		for key, write := range writes {
+			obj, err := newObj()
+			if err != nil {
+				return false, err
+			}
+			if err := cache.Get(ctx, key.key, obj); err != nil {
+				if !apierrors.IsNotFound(err) {
+					// E.g. the object is in a namespace the cache doesn't
+					// hold, so the write can't be observed.
+					unobservable = true
+					return true, nil
+				}
+				obj = nil
+			}
+			if !write.observedBy(obj) {
+				return false, nil
+			}
+			t.forget(key, write)
+			delete(writes, key)
+		}
+		return true, nil
+	})
+	switch {
+	case err == nil && !unobservable:
+		return true, nil
+	case err == nil || (ctx.Err() == nil && wait.Interrupted(err)):
+		// The cache didn't catch up in time or can't observe the writes,
+		// forget them as the caller falls back to a live read, which
+		// observes them.
+		for key, write := range writes {
+			t.forget(key, write)
+		}
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// recordWrite remembers the resourceVersion of the given object, which was
+// just returned by the server for a write, if read-your-writes is enabled.
+func (c *client) recordWrite(obj Object, err error) {
+	if c.writes == nil || err != nil || obj.GetResourceVersion() == "" {
+		return
+	}
+	gvk, gvkErr := c.GroupVersionKindFor(obj)
+	if gvkErr != nil {
+		return
+	}
+	c.writes.record(writeKey{gvk: gvk, key: ObjectKeyFromObject(obj)}, pendingWrite{resourceVersion: obj.GetResourceVersion(), labels: copyLabels(obj)})
+}
+
+// recordApply remembers the resourceVersion of the object the given apply
+// configuration was applied to, if read-your-writes is enabled.
+func (c *client) recordApply(obj ApplyConfiguration, err error) {
+	if c.writes == nil || err != nil {
+		return
+	}
+	u, convertErr := applyconfiguration.ToUnstructured(obj)
+	if convertErr != nil {
+		return
+	}
+	c.recordWrite(u, nil)
+}
+
+// recordDelete remembers the deletion of the given object, if read-your-writes
+// is enabled. Deletions are observed once the object is gone from the cache or
+// has its deletionTimestamp set.
+func (c *client) recordDelete(obj Object, err error) {
+	if c.writes == nil || (err != nil && !apierrors.IsNotFound(err)) {
+		return
+	}
+	gvk, gvkErr := c.GroupVersionKindFor(obj)
+	if gvkErr != nil {
+		return
+	}
+	c.writes.record(writeKey{gvk: gvk, key: ObjectKeyFromObject(obj)}, pendingWrite{deleted: true, uid: obj.GetUID(), labels: copyLabels(obj)})
+}
+
+// waitForCachedObject waits until the cache has observed the writes of the
+// client to the object with the given key. It returns false if the object
+// must be read live instead.
+func (c *client) waitForCachedObject(ctx context.Context, key ObjectKey, obj Object) (bool, error) {
+	if c.writes == nil {
+		return true, nil
+	}
+	gvk, err := c.GroupVersionKindFor(obj)
+	if err != nil {
+		return false, err
+	}
+
+	k := writeKey{gvk: gvk, key: key}
+	write, ok := c.writes.pending(gvk, key.Namespace)[k]
+	if !ok {
+		return true, nil
+	}
+	switch c.cacheScope(k, write) {
+	case ScopeOut:
+		return true, nil
+	case ScopeUnknown:
+		c.writes.forget(k, write)
+		return false, nil
+	}
+	return c.writes.waitForCache(ctx, c.cache, map[writeKey]pendingWrite{k: write}, func() (Object, error) {
+		return obj.DeepCopyObject().(Object), nil
+	})
+}
+
+// waitForCachedList waits until the cache has observed the writes of the
+// client to all objects that might be part of the given list. It returns
+// false if the list must be read live instead.
+func (c *client) waitForCachedList(ctx context.Context, list ObjectList, opts ...ListOption) (bool, error) {
+	if c.writes == nil {
+		return true, nil
+	}
+	gvk, err := c.GroupVersionKindFor(list)
+	if err != nil {
+		return false, err
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	listOpts := (&ListOptions{}).ApplyOptions(opts)
+	writes := c.writes.pending(gvk, listOpts.Namespace)
+	for key, write := range writes {
+		// Writes of objects that aren't part of the list, or that the cache
+		// never observes, don't need to be waited for.
+		if !write.matches(key, listOpts) {
+			delete(writes, key)
+			continue
+		}
+		switch c.cacheScope(key, write) {
+		case ScopeOut:
+			delete(writes, key)
+		case ScopeUnknown:
+			// The live read observes all the writes.
+			for k, w := range writes {
+				c.writes.forget(k, w)
+			}
+			return false, nil
+		}
+	}
+	return c.writes.waitForCache(ctx, c.cache, writes, func() (Object, error) {
+		return c.newListItem(list, gvk)
+	})
+}
+
+// cacheScope returns whether the cache holds the object of the given write if
+// it exists. Caches that don't implement ScopedReader are assumed to hold all
+// objects.
+func (c *client) cacheScope(key writeKey, write pendingWrite) Scope {
+	scoped, ok := c.cache.(ScopedReader)
+	if !ok {
+		return ScopeIn
+	}
+	return scoped.InScope(write.object(key))
+}
+
+// matches returns whether the object of the write matches the selectors of
+// the given list options. Field selectors can only be evaluated for the name
+// and namespace, so objects are assumed to match selectors on other fields.
+func (w pendingWrite) matches(key writeKey, listOpts *ListOptions) bool {
+	if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Matches(labels.Set(w.labels)) {
+		return false
+	}
+	if listOpts.FieldSelector == nil {
+		return true
+	}
+	for _, req := range listOpts.FieldSelector.Requirements() {
+		if req.Field != "metadata.name" && req.Field != "metadata.namespace" {
+			return true
+		}
+	}
+	return listOpts.FieldSelector.Matches(fields.Set{"metadata.name": key.key.Name, "metadata.namespace": key.key.Namespace})
+}
+
+// copyLabels returns a copy of the labels of obj.
+func copyLabels(obj Object) map[string]string {
+	if len(obj.GetLabels()) == 0 {
+		return nil
+	}
+	return labels.Merge(nil, obj.GetLabels())
+}
+
+// newListItem returns an empty object of the given kind that has the same
+// representation as the items of the given list.
+func (c *client) newListItem(list ObjectList, gvk schema.GroupVersionKind) (Object, error) {
+	switch list.(type) {
+	case runtime.Unstructured:
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		return u, nil
+	case *metav1.PartialObjectMetadataList:
+		p := &metav1.PartialObjectMetadata{}
+		p.SetGroupVersionKind(gvk)
+		return p, nil
+	default:
+		obj, err := c.scheme.New(gvk)
+		if err != nil {
+			return nil, err
+		}
+		o, ok := obj.(Object)
+		if !ok {
+			return nil, fmt.Errorf("%T does not implement client.Object", obj)
+		}
+		return o, nil
+	}
+}
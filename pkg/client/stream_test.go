@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("SubResourceStream", func() {
+	var server *httptest.Server
+	var requests []*http.Request
+	var c client.Client
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("line 1\nline 2\n"))
+		}))
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), meta.RESTScopeNamespace)
+
+		var err error
+		c, err = client.New(&rest.Config{Host: server.URL}, client.Options{Mapper: mapper})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should stream the logs of a pod", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+		logs, err := client.StreamSubResource(ctx, c.SubResource("log"), pod, &client.SubResourceStreamOptions{
+			Raw: &corev1.PodLogOptions{Container: "app", Follow: true},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer logs.Close()
+
+		Expect(io.ReadAll(logs)).To(BeEquivalentTo("line 1\nline 2\n"))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.Path).To(Equal("/api/v1/namespaces/default/pods/pod/log"))
+		Expect(requests[0].URL.Query().Get("container")).To(Equal("app"))
+		Expect(requests[0].URL.Query().Get("follow")).To(Equal("true"))
+	})
+
+	It("should stream subresources of unstructured objects", func() {
+		pod := &unstructured.Unstructured{}
+		pod.SetAPIVersion("v1")
+		pod.SetKind("Pod")
+		pod.SetNamespace("default")
+		pod.SetName("pod")
+		logs, err := client.StreamSubResource(ctx, c.SubResource("log"), pod)
+		Expect(err).NotTo(HaveOccurred())
+		defer logs.Close()
+
+		Expect(io.ReadAll(logs)).To(BeEquivalentTo("line 1\nline 2\n"))
+		Expect(requests[0].URL.Path).To(Equal("/api/v1/namespaces/default/pods/pod/log"))
+	})
+
+	It("should fail to stream subresources using only metadata", func() {
+		pod := &metav1.PartialObjectMetadata{}
+		pod.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Pod"))
+		_, err := client.StreamSubResource(ctx, c.SubResource("log"), pod)
+		Expect(err).To(HaveOccurred())
+	})
+})
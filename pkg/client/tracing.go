@@ -0,0 +1,253 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/internal/applyconfiguration"
+)
+
+// tracerName is the name of the tracer used by WithTracing, following the
+// OpenTelemetry convention of naming tracers after the instrumenting package.
+const tracerName = "sigs.k8s.io/controller-runtime/pkg/client"
+
+// WithTracing wraps an existing client and creates an OpenTelemetry span for
+// every call, using tracers from the given TracerProvider. Spans are children
+// of the span in the context passed to the call, if any, and carry the
+// GroupVersionKind, namespace and name of the object as attributes.
+func WithTracing(c Client, tracerProvider trace.TracerProvider) Client {
+	return &tracingClient{
+		client: c,
+		tracer: tracerProvider.Tracer(tracerName),
+	}
+}
+
+var _ Client = &tracingClient{}
+
+// tracingClient is a Client that wraps another Client in order to trace all calls.
+type tracingClient struct {
+	client Client
+	tracer trace.Tracer
+}
+
+// startSpan starts a span for the given operation on obj. obj may be nil
+// if the operation doesn't act on a single object.
+func (c *tracingClient) startSpan(ctx context.Context, operation string, obj runtime.Object, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if obj != nil {
+		if gvk, err := c.client.GroupVersionKindFor(obj); err == nil {
+			attrs = append(attrs, attribute.String("gvk", gvk.String()))
+		}
+		if o, ok := obj.(Object); ok {
+			attrs = append(attrs, objectAttributes(o.GetNamespace(), o.GetName())...)
+		}
+	}
+	return c.tracer.Start(ctx, "client."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// objectAttributes returns the span attributes identifying an object.
+func objectAttributes(namespace, name string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if namespace != "" {
+		attrs = append(attrs, attribute.String("namespace", namespace))
+	}
+	if name != "" {
+		attrs = append(attrs, attribute.String("name", name))
+	}
+	return attrs
+}
+
+// endSpan records the outcome of a call on the span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Scheme returns the scheme this client is using.
+func (c *tracingClient) Scheme() *runtime.Scheme {
+	return c.client.Scheme()
+}
+
+// RESTMapper returns the rest mapper this client is using.
+func (c *tracingClient) RESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GroupVersionKindFor returns the GroupVersionKind for the given object.
+func (c *tracingClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return c.client.GroupVersionKindFor(obj)
+}
+
+// IsObjectNamespaced returns true if the GroupVersionKind of the object is namespaced.
+func (c *tracingClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return c.client.IsObjectNamespaced(obj)
+}
+
+// Create implements client.Client.
+func (c *tracingClient) Create(ctx context.Context, obj Object, opts ...CreateOption) (err error) {
+	ctx, span := c.startSpan(ctx, "Create", obj)
+	defer func() { endSpan(span, err) }()
+	return c.client.Create(ctx, obj, opts...)
+}
+
+// Update implements client.Client.
+func (c *tracingClient) Update(ctx context.Context, obj Object, opts ...UpdateOption) (err error) {
+	ctx, span := c.startSpan(ctx, "Update", obj)
+	defer func() { endSpan(span, err) }()
+	return c.client.Update(ctx, obj, opts...)
+}
+
+// Delete implements client.Client.
+func (c *tracingClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption) (err error) {
+	ctx, span := c.startSpan(ctx, "Delete", obj)
+	defer func() { endSpan(span, err) }()
+	return c.client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf implements client.Client.
+func (c *tracingClient) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) (err error) {
+	deleteAllOfOpts := (&DeleteAllOfOptions{}).ApplyOptions(opts)
+	ctx, span := c.startSpan(ctx, "DeleteAllOf", obj, objectAttributes(deleteAllOfOpts.Namespace, "")...)
+	defer func() { endSpan(span, err) }()
+	return c.client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Patch implements client.Client.
+func (c *tracingClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) (err error) {
+	ctx, span := c.startSpan(ctx, "Patch", obj, attribute.String("patchType", string(patch.Type())))
+	defer func() { endSpan(span, err) }()
+	return c.client.Patch(ctx, obj, patch, opts...)
+}
+
+// Apply implements client.Client.
+func (c *tracingClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) (err error) {
+	ctx, span := c.startApplySpan(ctx, "Apply", obj)
+	defer func() { endSpan(span, err) }()
+	return c.client.Apply(ctx, obj, opts...)
+}
+
+// startApplySpan starts a span for applying the given apply configuration.
+// Invalid apply configurations are rejected by the wrapped client, so their
+// span simply doesn't carry any object attributes.
+func (c *tracingClient) startApplySpan(ctx context.Context, operation string, obj ApplyConfiguration) (context.Context, trace.Span) {
+	u, err := applyconfiguration.ToUnstructured(obj)
+	if err != nil {
+		return c.startSpan(ctx, operation, nil)
+	}
+	return c.startSpan(ctx, operation, u)
+}
+
+// Get implements client.Client.
+func (c *tracingClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) (err error) {
+	ctx, span := c.startSpan(ctx, "Get", obj, objectAttributes(key.Namespace, key.Name)...)
+	defer func() { endSpan(span, err) }()
+	return c.client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Client.
+func (c *tracingClient) List(ctx context.Context, obj ObjectList, opts ...ListOption) (err error) {
+	listOpts := (&ListOptions{}).ApplyOptions(opts)
+	ctx, span := c.startSpan(ctx, "List", obj, objectAttributes(listOpts.Namespace, "")...)
+	defer func() { endSpan(span, err) }()
+	return c.client.List(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient.
+func (c *tracingClient) Status() SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClientConstructor.
+func (c *tracingClient) SubResource(subResource string) SubResourceClient {
+	return &tracingSubResourceClient{
+		client:      c,
+		subResource: subResource,
+		wrapped:     c.client.SubResource(subResource),
+	}
+}
+
+var _ SubResourceClient = &tracingSubResourceClient{}
+var _ SubResourceStreamer = &tracingSubResourceClient{}
+
+// tracingSubResourceClient is a SubResourceClient that traces all calls.
+type tracingSubResourceClient struct {
+	client      *tracingClient
+	subResource string
+	wrapped     SubResourceClient
+}
+
+// startSpan starts a span for the given operation on the subresource of obj.
+func (sc *tracingSubResourceClient) startSpan(ctx context.Context, operation string, obj runtime.Object) (context.Context, trace.Span) {
+	return sc.client.startSpan(ctx, "SubResource"+operation, obj, attribute.String("subresource", sc.subResource))
+}
+
+// Get implements client.SubResourceReader.
+func (sc *tracingSubResourceClient) Get(ctx context.Context, obj, subResource Object, opts ...SubResourceGetOption) (err error) {
+	ctx, span := sc.startSpan(ctx, "Get", obj)
+	defer func() { endSpan(span, err) }()
+	return sc.wrapped.Get(ctx, obj, subResource, opts...)
+}
+
+// Stream implements client.SubResourceStreamer. The span ends once the
+// stream is opened.
+func (sc *tracingSubResourceClient) Stream(ctx context.Context, obj Object, opts ...SubResourceStreamOption) (_ io.ReadCloser, err error) {
+	ctx, span := sc.startSpan(ctx, "Stream", obj)
+	defer func() { endSpan(span, err) }()
+	return StreamSubResource(ctx, sc.wrapped, obj, opts...)
+}
+
+// Create implements client.SubResourceWriter.
+func (sc *tracingSubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) (err error) {
+	ctx, span := sc.startSpan(ctx, "Create", obj)
+	defer func() { endSpan(span, err) }()
+	return sc.wrapped.Create(ctx, obj, subResource, opts...)
+}
+
+// Update implements client.SubResourceWriter.
+func (sc *tracingSubResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) (err error) {
+	ctx, span := sc.startSpan(ctx, "Update", obj)
+	defer func() { endSpan(span, err) }()
+	return sc.wrapped.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.SubResourceWriter.
+func (sc *tracingSubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) (err error) {
+	ctx, span := sc.startSpan(ctx, "Patch", obj)
+	span.SetAttributes(attribute.String("patchType", string(patch.Type())))
+	defer func() { endSpan(span, err) }()
+	return sc.wrapped.Patch(ctx, obj, patch, opts...)
+}
+
+// Apply implements client.SubResourceWriter.
+func (sc *tracingSubResourceClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...SubResourceApplyOption) (err error) {
+	var span trace.Span
+	ctx, span = sc.client.startApplySpan(ctx, "SubResourceApply", obj)
+	span.SetAttributes(attribute.String("subresource", sc.subResource))
+	defer func() { endSpan(span, err) }()
+	return sc.wrapped.Apply(ctx, obj, opts...)
+}
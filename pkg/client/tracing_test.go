@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1applyconfigurations "k8s.io/client-go/applyconfigurations/apps/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("TracingClient", func() {
+	var dep *appsv1.Deployment
+	var recorder *tracetest.SpanRecorder
+	var c client.Client
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		dep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "default"},
+		}
+		recorder = tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		c = client.WithTracing(fake.NewClientBuilder().WithObjects(dep).WithStatusSubresource(dep).Build(), tracerProvider)
+	})
+
+	It("should create a span for every call", func() {
+		actual := &appsv1.Deployment{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(dep), actual)).To(Succeed())
+		Expect(c.List(ctx, &appsv1.DeploymentList{}, client.InNamespace("default"))).To(Succeed())
+		Expect(c.Update(ctx, actual)).To(Succeed())
+		Expect(c.Status().Update(ctx, actual)).To(Succeed())
+		Expect(c.Patch(ctx, actual, client.MergeFrom(actual.DeepCopy()))).To(Succeed())
+		Expect(c.Delete(ctx, actual)).To(Succeed())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(6))
+		Expect(spans[0].Name()).To(Equal("client.Get"))
+		Expect(spans[0].SpanKind()).To(Equal(trace.SpanKindClient))
+		Expect(spans[0].Attributes()).To(ConsistOf(
+			attribute.String("gvk", "apps/v1, Kind=Deployment"),
+			attribute.String("namespace", "default"),
+			attribute.String("name", "deployment"),
+		))
+		Expect(spans[1].Name()).To(Equal("client.List"))
+		Expect(spans[1].Attributes()).To(ConsistOf(
+			attribute.String("gvk", "apps/v1, Kind=DeploymentList"),
+			attribute.String("namespace", "default"),
+		))
+		Expect(spans[2].Name()).To(Equal("client.Update"))
+		Expect(spans[3].Name()).To(Equal("client.SubResourceUpdate"))
+		Expect(spans[3].Attributes()).To(ContainElement(attribute.String("subresource", "status")))
+		Expect(spans[4].Name()).To(Equal("client.Patch"))
+		Expect(spans[4].Attributes()).To(ContainElement(attribute.String("patchType", "application/merge-patch+json")))
+		Expect(spans[5].Name()).To(Equal("client.Delete"))
+	})
+
+	It("should trace applies", func() {
+		obj := appsv1applyconfigurations.Deployment("deployment", "default")
+		Expect(c.Apply(ctx, obj, client.FieldOwner("test"))).To(Succeed())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("client.Apply"))
+		Expect(spans[0].Attributes()).To(ConsistOf(
+			attribute.String("gvk", "apps/v1, Kind=Deployment"),
+			attribute.String("namespace", "default"),
+			attribute.String("name", "deployment"),
+		))
+	})
+
+	It("should record errors on the span", func() {
+		err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "missing"}, &appsv1.Deployment{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Status().Description).To(Equal(err.Error()))
+		Expect(spans[0].Events()).To(ContainElement(HaveField("Name", "exception")))
+	})
+
+	It("should create child spans of the span in the context", func() {
+		tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+		parentCtx, parent := tracer.Start(ctx, "parent")
+		Expect(c.Get(parentCtx, client.ObjectKeyFromObject(dep), &appsv1.Deployment{})).To(Succeed())
+		parent.End()
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Parent()).To(Equal(parent.SpanContext()))
+	})
+})
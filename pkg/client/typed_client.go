@@ -18,8 +18,13 @@ package client
 
 import (
 	"context"
+	"io"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"sigs.k8s.io/controller-runtime/pkg/internal/applyconfiguration"
 )
 
 var _ Reader = &typedClient{}
@@ -132,6 +137,39 @@ func (c *typedClient) Patch(ctx context.Context, obj Object, patch Patch, opts .
 		Into(obj)
 }
 
+// Apply implements client.Client.
+func (c *typedClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) error {
+	u, err := applyconfiguration.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	o, err := c.resources.getObjMeta(u)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	applyOpts := &ApplyOptions{}
+	applyOpts.ApplyOptions(opts)
+
+	result, err := o.Patch(types.ApplyPatchType).
+		NamespaceIfScoped(o.GetNamespace(), o.isNamespaced()).
+		Resource(o.resource()).
+		Name(o.GetName()).
+		VersionedParams(applyOpts.AsPatchOptions(), c.paramCodec).
+		Body(data).
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return err
+	}
+	return decodeApplyResult(result, obj)
+}
+
 // Get implements client.Client.
 func (c *typedClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
 	r, err := c.resources.getResource(obj)
@@ -188,6 +226,26 @@ func (c *typedClient) GetSubResource(ctx context.Context, obj, subResourceObj Ob
 		Into(subResourceObj)
 }
 
+func (c *typedClient) StreamSubResource(ctx context.Context, obj Object, subResource string, opts ...SubResourceStreamOption) (io.ReadCloser, error) {
+	o, err := c.resources.getObjMeta(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	streamOpts := &SubResourceStreamOptions{}
+	streamOpts.ApplyOptions(opts)
+
+	req := o.Get().
+		NamespaceIfScoped(o.GetNamespace(), o.isNamespaced()).
+		Resource(o.resource()).
+		Name(o.GetName()).
+		SubResource(subResource)
+	if streamOpts.Raw != nil {
+		req = req.VersionedParams(streamOpts.Raw, c.paramCodec)
+	}
+	return req.Stream(ctx)
+}
+
 func (c *typedClient) CreateSubResource(ctx context.Context, obj Object, subResourceObj Object, subResource string, opts ...SubResourceCreateOption) error {
 	o, err := c.resources.getObjMeta(obj)
 	if err != nil {
@@ -277,3 +335,42 @@ func (c *typedClient) PatchSubResource(ctx context.Context, obj Object, subResou
 		Do(ctx).
 		Into(body)
 }
+
+// ApplySubResource used by SubResourceWriter to apply subresource.
+func (c *typedClient) ApplySubResource(ctx context.Context, obj ApplyConfiguration, subResource string, opts ...SubResourceApplyOption) error {
+	u, err := applyconfiguration.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	o, err := c.resources.getObjMeta(u)
+	if err != nil {
+		return err
+	}
+
+	applyOpts := &SubResourceApplyOptions{}
+	applyOpts.ApplyOpts(opts)
+
+	body := obj
+	if applyOpts.SubResourceBody != nil {
+		body = applyOpts.SubResourceBody
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	result, err := o.Patch(types.ApplyPatchType).
+		NamespaceIfScoped(o.GetNamespace(), o.isNamespaced()).
+		Resource(o.resource()).
+		Name(o.GetName()).
+		SubResource(subResource).
+		Body(data).
+		VersionedParams(applyOpts.AsPatchOptions(), c.paramCodec).
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return err
+	}
+	return decodeApplyResult(result, body)
+}
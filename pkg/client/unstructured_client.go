@@ -19,9 +19,12 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
 )
 
 var _ Reader = &unstructuredClient{}
@@ -166,6 +169,41 @@ func (uc *unstructuredClient) Patch(ctx context.Context, obj Object, patch Patch
 		Into(obj)
 }
 
+// Apply implements client.Client.
+func (uc *unstructuredClient) Apply(ctx context.Context, obj ApplyConfiguration, opts ...ApplyOption) error {
+	u, ok := obj.(runtime.Unstructured)
+	if !ok {
+		return fmt.Errorf("unstructured client did not understand object: %T", obj)
+	}
+
+	gvk := u.GetObjectKind().GroupVersionKind()
+
+	o, err := uc.resources.getObjMeta(u)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	applyOpts := &ApplyOptions{}
+	applyOpts.ApplyOptions(opts)
+
+	result := o.Patch(types.ApplyPatchType).
+		NamespaceIfScoped(o.GetNamespace(), o.isNamespaced()).
+		Resource(o.resource()).
+		Name(o.GetName()).
+		VersionedParams(applyOpts.AsPatchOptions(), uc.paramCodec).
+		Body(data).
+		Do(ctx).
+		Into(u)
+
+	u.GetObjectKind().SetGroupVersionKind(gvk)
+	return result
+}
+
 // Get implements client.Client.
 func (uc *unstructuredClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
 	u, ok := obj.(runtime.Unstructured)
@@ -253,6 +291,30 @@ func (uc *unstructuredClient) GetSubResource(ctx context.Context, obj, subResour
 		Into(subResourceObj)
 }
 
+func (uc *unstructuredClient) StreamSubResource(ctx context.Context, obj Object, subResource string, opts ...SubResourceStreamOption) (io.ReadCloser, error) {
+	if _, ok := obj.(runtime.Unstructured); !ok {
+		return nil, fmt.Errorf("unstructured client did not understand object: %T", obj)
+	}
+
+	o, err := uc.resources.getObjMeta(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	streamOpts := &SubResourceStreamOptions{}
+	streamOpts.ApplyOptions(opts)
+
+	req := o.Get().
+		NamespaceIfScoped(o.GetNamespace(), o.isNamespaced()).
+		Resource(o.resource()).
+		Name(o.GetName()).
+		SubResource(subResource)
+	if streamOpts.Raw != nil {
+		req = req.VersionedParams(streamOpts.Raw, uc.paramCodec)
+	}
+	return req.Stream(ctx)
+}
+
 func (uc *unstructuredClient) CreateSubResource(ctx context.Context, obj, subResourceObj Object, subResource string, opts ...SubResourceCreateOption) error {
 	if _, ok := obj.(runtime.Unstructured); !ok {
 		return fmt.Errorf("unstructured client did not understand object: %T", obj)
@@ -359,3 +421,46 @@ func (uc *unstructuredClient) PatchSubResource(ctx context.Context, obj Object,
 	u.GetObjectKind().SetGroupVersionKind(gvk)
 	return result
 }
+
+func (uc *unstructuredClient) ApplySubResource(ctx context.Context, obj ApplyConfiguration, subResource string, opts ...SubResourceApplyOption) error {
+	u, ok := obj.(runtime.Unstructured)
+	if !ok {
+		return fmt.Errorf("unstructured client did not understand object: %T", obj)
+	}
+
+	gvk := u.GetObjectKind().GroupVersionKind()
+
+	o, err := uc.resources.getObjMeta(u)
+	if err != nil {
+		return err
+	}
+
+	applyOpts := &SubResourceApplyOptions{}
+	applyOpts.ApplyOpts(opts)
+
+	body := u
+	if applyOpts.SubResourceBody != nil {
+		body, ok = applyOpts.SubResourceBody.(runtime.Unstructured)
+		if !ok {
+			return fmt.Errorf("unstructured client did not understand object: %T", applyOpts.SubResourceBody)
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	result := o.Patch(types.ApplyPatchType).
+		NamespaceIfScoped(o.GetNamespace(), o.isNamespaced()).
+		Resource(o.resource()).
+		Name(o.GetName()).
+		SubResource(subResource).
+		Body(data).
+		VersionedParams(applyOpts.AsPatchOptions(), uc.paramCodec).
+		Do(ctx).
+		Into(body)
+
+	u.GetObjectKind().SetGroupVersionKind(gvk)
+	return result
+}
@@ -18,7 +18,6 @@ package client
 
 import (
 	"context"
-	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -44,7 +43,7 @@ func (w *watchingClient) Watch(ctx context.Context, list ObjectList, opts ...Lis
 	case runtime.Unstructured:
 		return w.unstructuredWatch(ctx, l, opts...)
 	case *metav1.PartialObjectMetadataList:
-		return w.metadataWatch(ctx, l, opts...)
+		return w.client.metadataClient.Watch(ctx, l, opts...)
 	default:
 		return w.typedWatch(ctx, l, opts...)
 	}
@@ -61,20 +60,6 @@ func (w *watchingClient) listOpts(opts ...ListOption) ListOptions {
 	return listOpts
 }
 
-func (w *watchingClient) metadataWatch(ctx context.Context, obj *metav1.PartialObjectMetadataList, opts ...ListOption) (watch.Interface, error) {
-	gvk := obj.GroupVersionKind()
-	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
-
-	listOpts := w.listOpts(opts...)
-
-	resInt, err := w.client.metadataClient.getResourceInterface(gvk, listOpts.Namespace)
-	if err != nil {
-		return nil, err
-	}
-
-	return resInt.Watch(ctx, *listOpts.AsListOptions())
-}
-
 func (w *watchingClient) unstructuredWatch(ctx context.Context, obj runtime.Unstructured, opts ...ListOption) (watch.Interface, error) {
 	r, err := w.client.unstructuredClient.resources.getResource(obj)
 	if err != nil {
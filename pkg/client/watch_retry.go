@@ -0,0 +1,242 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// WatchResync is the type of the events a watch returned by WatchWithRetry
+// emits after it had to list the objects again, because the resourceVersion
+// it would have resumed from was too old. The object of the event is the new
+// list, which replaces all state built from previous events.
+const WatchResync watch.EventType = "RESYNC"
+
+const (
+	watchRetryInitialDelay = 100 * time.Millisecond
+	watchRetryMaxDelay     = 30 * time.Second
+)
+
+// WatchWithRetry starts a watch like c.Watch, but instead of ending when the
+// API server closes it, e.g. on timeouts and restarts, the watch is resumed
+// from the resourceVersion of the last event or bookmark it received. Failed
+// attempts and watches that end without receiving any event are retried with
+// an exponential backoff.
+//
+// If the resourceVersion to resume from is too old (410 Gone), the objects
+// are listed again and a WatchResync event with the list is emitted before the
+// watch is resumed from the resourceVersion of the list. The same happens if
+// the watch has to be resumed before it received any resourceVersion.
+//
+// Bookmarks are always requested to keep the resourceVersion current, but
+// only emitted if opts request them. Error events are not emitted.
+//
+// The watch ends when ctx is cancelled or it is stopped. If the first watch
+// can't be started, e.g. because the resourceVersion in opts is too old, its
+// error is returned.
+func WatchWithRetry(ctx context.Context, c WithWatch, list ObjectList, opts ...ListOption) (watch.Interface, error) {
+	listOpts := (&ListOptions{}).ApplyOptions(opts)
+	raw := metav1.ListOptions{}
+	if listOpts.Raw != nil {
+		raw = *listOpts.Raw
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &retryWatcher{
+		ctx:             ctx,
+		cancel:          cancel,
+		client:          c,
+		list:            list,
+		opts:            *listOpts,
+		bookmarks:       raw.AllowWatchBookmarks,
+		resourceVersion: raw.ResourceVersion,
+		result:          make(chan watch.Event),
+	}
+
+	// The first watch is established synchronously, to surface errors like a
+	// missing RESTMapping immediately.
+	watcher, err := w.watch()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go w.run(watcher)
+	return w, nil
+}
+
+// retryWatcher is a watch.Interface that resumes watches when they end.
+type retryWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	client WithWatch
+	list   ObjectList
+	opts   ListOptions
+
+	// bookmarks is whether bookmarks are emitted.
+	bookmarks bool
+	// resourceVersion is the resourceVersion to resume from.
+	resourceVersion string
+
+	result chan watch.Event
+}
+
+// Stop implements watch.Interface.
+func (w *retryWatcher) Stop() {
+	w.cancel()
+}
+
+// ResultChan implements watch.Interface.
+func (w *retryWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+// listOptions returns the options for a list or watch request.
+func (w *retryWatcher) listOptions(resourceVersion string, isWatch bool) *ListOptions {
+	opts := w.opts
+	raw := metav1.ListOptions{}
+	if w.opts.Raw != nil {
+		raw = *w.opts.Raw
+	}
+	raw.ResourceVersion = resourceVersion
+	raw.Watch = isWatch
+	raw.AllowWatchBookmarks = isWatch
+	raw.Limit, raw.Continue = 0, ""
+	opts.Raw = &raw
+	opts.Limit, opts.Continue = 0, ""
+	return &opts
+}
+
+// watch starts a watch from the current resourceVersion.
+func (w *retryWatcher) watch() (watch.Interface, error) {
+	return w.client.Watch(w.ctx, w.list, w.listOptions(w.resourceVersion, true))
+}
+
+// relist lists the objects, emits them in a WatchResync event and updates
+// the resourceVersion to resume from.
+func (w *retryWatcher) relist() error {
+	list, ok := w.list.DeepCopyObject().(ObjectList)
+	if !ok {
+		// Can't happen, ObjectLists deep copy to themselves.
+		panic("list does not deep copy to an ObjectList")
+	}
+	if err := w.client.List(w.ctx, list, w.listOptions("", false)); err != nil {
+		return err
+	}
+	w.resourceVersion = list.GetResourceVersion()
+	w.send(watch.Event{Type: WatchResync, Object: list})
+	return nil
+}
+
+// run forwards the events of watcher and resumes it until the watch is
+// stopped.
+func (w *retryWatcher) run(watcher watch.Interface) {
+	defer close(w.result)
+
+	delay := watchRetryInitialDelay
+	for {
+		received, err := w.forward(watcher)
+		watcher.Stop()
+		if received {
+			delay = watchRetryInitialDelay
+		}
+
+		// Relist if there is no resourceVersion to resume from, and back off
+		// if the watch failed for any other reason. Watches that end without
+		// any event are backed off as well, so servers or proxies that close
+		// them right away aren't flooded with requests.
+		relist := isWatchExpired(err) || w.resourceVersion == ""
+		wait := !received || (err != nil && !relist)
+		for {
+			if wait {
+				select {
+				case <-w.ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+				delay *= 2
+				if delay > watchRetryMaxDelay {
+					delay = watchRetryMaxDelay
+				}
+			}
+			if w.ctx.Err() != nil {
+				return
+			}
+
+			wait = true
+			if relist {
+				if err := w.relist(); err != nil {
+					continue
+				}
+				relist = false
+			}
+			watcher, err = w.watch()
+			if err == nil {
+				break
+			}
+			if isWatchExpired(err) {
+				relist, wait = true, false
+			}
+		}
+	}
+}
+
+// forward forwards the events of watcher until it ends, and returns whether
+// it received any event other than an error and the error it ended with, if
+// any.
+func (w *retryWatcher) forward(watcher watch.Interface) (received bool, err error) {
+	for {
+		select {
+		case <-w.ctx.Done():
+			return received, nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return received, nil
+			}
+			if event.Type == watch.Error {
+				return received, apierrors.FromObject(event.Object)
+			}
+			received = true
+			if accessor, err := meta.Accessor(event.Object); err == nil && accessor.GetResourceVersion() != "" {
+				w.resourceVersion = accessor.GetResourceVersion()
+			}
+			if event.Type == watch.Bookmark && !w.bookmarks {
+				continue
+			}
+			w.send(event)
+		}
+	}
+}
+
+// send emits event, unless the watch is stopped.
+func (w *retryWatcher) send(event watch.Event) {
+	select {
+	case w.result <- event:
+	case <-w.ctx.Done():
+	}
+}
+
+// isWatchExpired returns whether err means that the resourceVersion a watch
+// was started from is too old.
+func isWatchExpired(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("WatchWithRetry", func() {
+	var mu sync.Mutex
+	var watchers []*watch.FakeWatcher
+	var watchOpts []*metav1.ListOptions
+	var closeWatches bool
+	var c client.WithWatch
+
+	configMap := func(name, resourceVersion string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", ResourceVersion: resourceVersion}}
+	}
+
+	lastWatcher := func() *watch.FakeWatcher {
+		mu.Lock()
+		defer mu.Unlock()
+		return watchers[len(watchers)-1]
+	}
+
+	watchCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(watchers)
+	}
+
+	BeforeEach(func() {
+		mu.Lock()
+		watchers, watchOpts, closeWatches = nil, nil, false
+		mu.Unlock()
+		c = interceptor.NewClient(fake.NewClientBuilder().WithObjects(configMap("existing", "")).Build(), interceptor.Funcs{
+			Watch: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				w := watch.NewFake()
+				watchers = append(watchers, w)
+				watchOpts = append(watchOpts, (&client.ListOptions{}).ApplyOptions(opts).AsListOptions())
+				if closeWatches {
+					w.Stop()
+				}
+				return w, nil
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if err := c.List(ctx, list, opts...); err != nil {
+					return err
+				}
+				list.SetResourceVersion("20")
+				return nil
+			},
+		})
+	})
+
+	It("should resume the watch from the last resourceVersion", func() {
+		w, err := client.WatchWithRetry(context.Background(), c, &corev1.ConfigMapList{}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		lastWatcher().Add(configMap("a", "5"))
+		Expect((<-w.ResultChan()).Object).To(Equal(configMap("a", "5")))
+		lastWatcher().Action(watch.Bookmark, configMap("", "7"))
+		lastWatcher().Stop()
+
+		Eventually(watchCount).Should(Equal(2))
+		lastWatcher().Modify(configMap("a", "8"))
+		event := <-w.ResultChan()
+		Expect(event.Type).To(Equal(watch.Modified))
+		Expect(event.Object).To(Equal(configMap("a", "8")))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(watchOpts[0].ResourceVersion).To(BeEmpty())
+		Expect(watchOpts[0].AllowWatchBookmarks).To(BeTrue())
+		Expect(watchOpts[1].ResourceVersion).To(Equal("7"))
+	})
+
+	It("should relist and emit a resync event if the resourceVersion is too old", func() {
+		w, err := client.WatchWithRetry(context.Background(), c, &corev1.ConfigMapList{}, &client.ListOptions{
+			Raw: &metav1.ListOptions{ResourceVersion: "3"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		lastWatcher().Error(&apierrors.NewResourceExpired("too old resource version").ErrStatus)
+
+		event := <-w.ResultChan()
+		Expect(event.Type).To(Equal(client.WatchResync))
+		list, ok := event.Object.(*corev1.ConfigMapList)
+		Expect(ok).To(BeTrue())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Name).To(Equal("existing"))
+
+		Eventually(watchCount).Should(Equal(2))
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(watchOpts[0].ResourceVersion).To(Equal("3"))
+		Expect(watchOpts[1].ResourceVersion).To(Equal("20"))
+	})
+
+	It("should back off if watches end without any event", func() {
+		mu.Lock()
+		closeWatches = true
+		mu.Unlock()
+
+		w, err := client.WatchWithRetry(context.Background(), c, &corev1.ConfigMapList{}, &client.ListOptions{
+			Raw: &metav1.ListOptions{ResourceVersion: "3"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		// The watches are retried after 100ms, 200ms, 400ms, ...
+		Eventually(watchCount).Should(BeNumerically(">=", 2))
+		Consistently(watchCount, 500*time.Millisecond).Should(BeNumerically("<=", 4))
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, opts := range watchOpts {
+			Expect(opts.ResourceVersion).To(Equal("3"))
+		}
+	})
+
+	It("should work with unstructured lists", func() {
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion("v1")
+		list.SetKind("ConfigMapList")
+		w, err := client.WatchWithRetry(context.Background(), c, list)
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		// The watch ends before it received a resourceVersion to resume from.
+		lastWatcher().Stop()
+		event := <-w.ResultChan()
+		Expect(event.Type).To(Equal(client.WatchResync))
+		Expect(event.Object.(*unstructured.UnstructuredList).Items).To(HaveLen(1))
+	})
+
+	It("should close the result channel when stopped", func() {
+		w, err := client.WatchWithRetry(context.Background(), c, &corev1.ConfigMapList{})
+		Expect(err).NotTo(HaveOccurred())
+		w.Stop()
+		Eventually(w.ResultChan()).Should(BeClosed())
+	})
+
+	It("should watch the fake client", func() {
+		c := fake.NewClientBuilder().Build()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w, err := client.WatchWithRetry(ctx, c, &corev1.ConfigMapList{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Create(ctx, configMap("new", ""))).To(Succeed())
+		event := <-w.ResultChan()
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Object.(*corev1.ConfigMap).Name).To(Equal("new"))
+
+		cancel()
+		Eventually(w.ResultChan()).Should(BeClosed())
+	})
+})
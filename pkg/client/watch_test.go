@@ -91,9 +91,6 @@ var _ = Describe("ClientWithWatch", func() {
 			Expect(event.Type).To(BeIdenticalTo(watch.Added))
 			Expect(event.Object).To(BeAssignableToTypeOf(expectedType))
 
-			// The metadata client doesn't set GVK so we just use the
-			// name and UID as a proxy to confirm that we got the right
-			// object.
 			metaObject, ok := event.Object.(metav1.Object)
 			Expect(ok).To(BeTrue())
 			Expect(metaObject.GetName()).To(Equal(dep.Name))
@@ -126,7 +123,7 @@ var _ = Describe("ClientWithWatch", func() {
 
 		It("should receive a create event when watching the metadata object", func() {
 			m := &metav1.PartialObjectMetadataList{TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"}}
-			watchSuite(m, &metav1.PartialObjectMetadata{}, false)
+			watchSuite(m, &metav1.PartialObjectMetadata{}, true)
 		})
 	})
 
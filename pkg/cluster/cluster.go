@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -32,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/internal/dryrun"
 	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
 	intrec "sigs.k8s.io/controller-runtime/pkg/internal/recorder"
 )
@@ -132,6 +134,19 @@ type Options struct {
 	// Only use a custom NewClient if you know what you are doing.
 	NewClient client.NewClientFunc
 
+	// TracerProvider is used to trace all calls of the Client and the API Reader
+	// of the cluster, see client.WithTracing. If unset, calls are not traced.
+	TracerProvider trace.TracerProvider
+
+	// DryRun makes the Client of the cluster and its event recorders send all
+	// writes as server-side dry-run requests, so that they are validated by the
+	// API server, but not persisted. Every write is logged, including the diff
+	// or patch it would have applied, and counted in the
+	// controller_runtime_dry_run_suppressed_writes_total metric per controller.
+	// The API Reader and custom clients created from the rest.Config of the
+	// cluster are not affected.
+	DryRun bool
+
 	// EventBroadcaster records Events emitted by the manager and sends them to the Kubernetes API
 	// Use this to customize the event correlator and spam filter
 	//
@@ -239,6 +254,15 @@ func New(config *rest.Config, opts ...Option) (Cluster, error) {
 		return nil, err
 	}
 
+	if options.DryRun {
+		clientWriter = dryrun.NewClient(clientWriter, options.Logger.WithName("dry-run"))
+	}
+
+	if options.TracerProvider != nil {
+		clientWriter = client.WithTracing(clientWriter, options.TracerProvider)
+		clientReader = client.WithTracing(clientReader, options.TracerProvider)
+	}
+
 	// Create the recorder provider to inject event recorders for the components.
 	// TODO(directxman12): the log for the event provider should have a context (name, tags, etc) specific
 	// to the particular controller that it's being injected into, rather than a generic one like is here.
@@ -246,6 +270,9 @@ func New(config *rest.Config, opts ...Option) (Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
+	if options.DryRun {
+		recorderProvider.SetDryRun()
+	}
 
 	return &cluster{
 		config:           originalConfig,
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
@@ -62,6 +63,10 @@ type Options struct {
 	// LogConstructor is used to construct a logger used for this controller and passed
 	// to each reconciliation via the context field.
 	LogConstructor func(request *reconcile.Request) logr.Logger
+
+	// TracerProvider is used to create a span for every reconciliation.
+	// Defaults to the TracerProvider of the Manager if unset.
+	TracerProvider trace.TracerProvider
 }
 
 // Controller implements a Kubernetes API.  A Controller manages a work queue fed reconcile.Requests
@@ -155,6 +160,14 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 		options.NeedLeaderElection = mgr.GetControllerOptions().NeedLeaderElection
 	}
 
+	if options.TracerProvider == nil {
+		if getter, ok := mgr.(manager.TracerProviderGetter); ok {
+			options.TracerProvider = getter.GetTracerProvider()
+		} else {
+			options.TracerProvider = trace.NewNoopTracerProvider()
+		}
+	}
+
 	// Create controller with dependencies set
 	return &controller.Controller{
 		Do: options.Reconciler,
@@ -169,6 +182,7 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 		LogConstructor:          options.LogConstructor,
 		RecoverPanic:            options.RecoverPanic,
 		LeaderElected:           options.NeedLeaderElection,
+		TracerProvider:          options.TracerProvider,
 	}, nil
 }
 
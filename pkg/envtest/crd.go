@@ -17,14 +17,8 @@ limitations under the License.
 package envtest
 
 import (
-	"bufio"
-	"bytes"
 	"context"
-	"errors"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
 	"time"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -34,14 +28,13 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
-	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/utils/pointer"
-	"sigs.k8s.io/yaml"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/internal/manifest"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 )
 
@@ -280,64 +273,7 @@ func CreateCRDs(config *rest.Config, crds []*apiextensionsv1.CustomResourceDefin
 
 // renderCRDs iterate through options.Paths and extract all CRD files.
 func renderCRDs(options *CRDInstallOptions) ([]*apiextensionsv1.CustomResourceDefinition, error) {
-	type GVKN struct {
-		GVK  schema.GroupVersionKind
-		Name string
-	}
-
-	crds := map[GVKN]*apiextensionsv1.CustomResourceDefinition{}
-
-	for _, path := range options.Paths {
-		var (
-			err      error
-			info     os.FileInfo
-			files    []string
-			filePath = path
-		)
-
-		// Return the error if ErrorIfPathMissing exists
-		if info, err = os.Stat(path); os.IsNotExist(err) {
-			if options.ErrorIfPathMissing {
-				return nil, err
-			}
-			continue
-		}
-
-		if !info.IsDir() {
-			filePath, files = filepath.Dir(path), []string{info.Name()}
-		} else {
-			entries, err := os.ReadDir(path)
-			if err != nil {
-				return nil, err
-			}
-			for _, e := range entries {
-				files = append(files, e.Name())
-			}
-		}
-
-		log.V(1).Info("reading CRDs from path", "path", path)
-		crdList, err := readCRDs(filePath, files)
-		if err != nil {
-			return nil, err
-		}
-
-		for i, crd := range crdList {
-			gvkn := GVKN{GVK: crd.GroupVersionKind(), Name: crd.GetName()}
-			if _, found := crds[gvkn]; found {
-				// Currently, we only print a log when there are duplicates. We may want to error out if that makes more sense.
-				log.Info("there are more than one CRD definitions with the same <Group, Version, Kind, Name>", "GVKN", gvkn)
-			}
-			// We always use the CRD definition that we found last.
-			crds[gvkn] = crdList[i]
-		}
-	}
-
-	// Converting map to a list to return
-	res := []*apiextensionsv1.CustomResourceDefinition{}
-	for _, obj := range crds {
-		res = append(res, obj)
-	}
-	return res, nil
+	return manifest.ReadCRDs(options.Paths, options.ErrorIfPathMissing, log)
 }
 
 // modifyConversionWebhooks takes all the registered CustomResourceDefinitions and applies modifications
@@ -394,65 +330,3 @@ func modifyConversionWebhooks(crds []*apiextensionsv1.CustomResourceDefinition,
 
 	return nil
 }
-
-// readCRDs reads the CRDs from files and Unmarshals them into structs.
-func readCRDs(basePath string, files []string) ([]*apiextensionsv1.CustomResourceDefinition, error) {
-	var crds []*apiextensionsv1.CustomResourceDefinition
-
-	// White list the file extensions that may contain CRDs
-	crdExts := sets.NewString(".json", ".yaml", ".yml")
-
-	for _, file := range files {
-		// Only parse allowlisted file types
-		if !crdExts.Has(filepath.Ext(file)) {
-			continue
-		}
-
-		// Unmarshal CRDs from file into structs
-		docs, err := readDocuments(filepath.Join(basePath, file))
-		if err != nil {
-			return nil, err
-		}
-
-		for _, doc := range docs {
-			crd := &apiextensionsv1.CustomResourceDefinition{}
-			if err = yaml.Unmarshal(doc, crd); err != nil {
-				return nil, err
-			}
-
-			if crd.Kind != "CustomResourceDefinition" || crd.Spec.Names.Kind == "" || crd.Spec.Group == "" {
-				continue
-			}
-			crds = append(crds, crd)
-		}
-
-		log.V(1).Info("read CRDs from file", "file", file)
-	}
-	return crds, nil
-}
-
-// readDocuments reads documents from file.
-func readDocuments(fp string) ([][]byte, error) {
-	b, err := os.ReadFile(fp)
-	if err != nil {
-		return nil, err
-	}
-
-	docs := [][]byte{}
-	reader := k8syaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
-	for {
-		// Read document
-		doc, err := reader.Read()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-
-			return nil, err
-		}
-
-		docs = append(docs, doc)
-	}
-
-	return docs, nil
-}
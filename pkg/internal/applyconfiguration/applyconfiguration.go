@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package applyconfiguration contains helpers for the apply configurations
+// that are passed to client.Client.Apply.
+package applyconfiguration
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ToUnstructured returns the unstructured representation of the given apply
+// configuration, which is used to look up the resource and the name and
+// namespace the request has to be sent to. Unstructured objects are returned
+// as they are.
+func ToUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	if u, isUnstructured := obj.(*unstructured.Unstructured); isUnstructured {
+		return u, nil
+	}
+	if obj == nil || reflect.ValueOf(obj).Kind() != reflect.Ptr || reflect.ValueOf(obj).IsNil() {
+		return nil, fmt.Errorf("apply configuration must be a non-nil pointer, got %T", obj)
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize apply configuration %T: %w", obj, err)
+	}
+	u := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &u.Object); err != nil {
+		return nil, fmt.Errorf("failed to deserialize apply configuration %T: %w", obj, err)
+	}
+	if u.GetAPIVersion() == "" || u.GetKind() == "" {
+		return nil, fmt.Errorf("apply configuration %T must have apiVersion and kind set", obj)
+	}
+	if u.GetName() == "" {
+		return nil, fmt.Errorf("apply configuration %T must have metadata.name set", obj)
+	}
+	return u, nil
+}
@@ -24,11 +24,15 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/util/workqueue"
 
+	"sigs.k8s.io/controller-runtime/pkg/client/audit"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -90,6 +94,10 @@ type Controller struct {
 
 	// LeaderElected indicates whether the controller is leader elected or always running.
 	LeaderElected *bool
+
+	// TracerProvider is used to create a span for every reconciliation.
+	// Defaults to a no-op TracerProvider, i.e. no spans are recorded.
+	TracerProvider trace.TracerProvider
 }
 
 // watchDescription contains all the information necessary to start a watch.
@@ -274,6 +282,9 @@ const (
 	labelSuccess      = "success"
 )
 
+// tracerName is the name of the tracer used to trace reconciliations.
+const tracerName = "sigs.k8s.io/controller-runtime/pkg/controller"
+
 func (c *Controller) initMetrics() {
 	ctrlmetrics.ActiveWorkers.WithLabelValues(c.Name).Set(0)
 	ctrlmetrics.ReconcileErrors.WithLabelValues(c.Name).Add(0)
@@ -309,6 +320,15 @@ func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
 	log = log.WithValues("reconcileID", reconcileID)
 	ctx = logf.IntoContext(ctx, log)
 	ctx = addReconcileID(ctx, reconcileID)
+	ctx = audit.WithController(ctx, c.Name, reconcileID)
+
+	ctx, span := c.tracer().Start(ctx, "Reconcile", trace.WithAttributes(
+		attribute.String("controller", c.Name),
+		attribute.String("namespace", req.Namespace),
+		attribute.String("name", req.Name),
+		attribute.String("reconcileID", string(reconcileID)),
+	))
+	defer span.End()
 
 	// RunInformersAndControllers the syncHandler, passing it the Namespace/Name string of the
 	// resource to be synced.
@@ -316,6 +336,8 @@ func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
 	result, err := c.Reconcile(ctx, req)
 	switch {
 	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		if errors.Is(err, reconcile.TerminalError(nil)) {
 			ctrlmetrics.TerminalReconcileErrors.WithLabelValues(c.Name).Inc()
 		} else {
@@ -349,6 +371,17 @@ func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
 	}
 }
 
+// noopTracer is used to trace reconciliations if no TracerProvider is set.
+var noopTracer = trace.NewNoopTracerProvider().Tracer(tracerName)
+
+// tracer returns the tracer used to trace reconciliations.
+func (c *Controller) tracer() trace.Tracer {
+	if c.TracerProvider == nil {
+		return noopTracer
+	}
+	return c.TracerProvider.Tracer(tracerName)
+}
+
 // GetLogger returns this controller's logger.
 func (c *Controller) GetLogger() logr.Logger {
 	return c.LogConstructor(nil)
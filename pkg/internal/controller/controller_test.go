@@ -28,6 +28,11 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -670,6 +675,67 @@ var _ = Describe("controller", func() {
 			})
 		})
 
+		Context("tracing", func() {
+			var recorder *tracetest.SpanRecorder
+
+			BeforeEach(func() {
+				recorder = tracetest.NewSpanRecorder()
+				ctrl.Name = "test-controller"
+				ctrl.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			})
+
+			It("should create a span for every reconciliation", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+				}()
+				queue.Add(request)
+
+				By("Invoking Reconciler which will give an error")
+				fakeReconcile.AddResult(reconcile.Result{}, fmt.Errorf("expected error: reconcile"))
+				Expect(<-reconciled).To(Equal(request))
+				Eventually(recorder.Ended).Should(HaveLen(1))
+
+				span := recorder.Ended()[0]
+				Expect(span.Name()).To(Equal("Reconcile"))
+				Expect(span.Attributes()).To(ContainElements(
+					attribute.String("controller", "test-controller"),
+					attribute.String("namespace", "foo"),
+					attribute.String("name", "bar"),
+					HaveField("Key", attribute.Key("reconcileID")),
+				))
+				Expect(span.Status().Code).To(Equal(codes.Error))
+				Expect(span.Status().Description).To(Equal("expected error: reconcile"))
+
+				By("Invoking Reconciler a second time without error")
+				fakeReconcile.AddResult(reconcile.Result{}, nil)
+				Expect(<-reconciled).To(Equal(request))
+				Eventually(recorder.Ended).Should(HaveLen(2))
+				Expect(recorder.Ended()[1].Status().Code).To(Equal(codes.Unset))
+			})
+
+			It("should pass the span to the Reconciler via the context", func() {
+				var reconcileSpan trace.SpanContext
+				ctrl.Do = reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+					reconcileSpan = trace.SpanContextFromContext(ctx)
+					return reconcile.Result{}, nil
+				})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+				}()
+				queue.Add(request)
+
+				Eventually(recorder.Ended).Should(HaveLen(1))
+				Expect(reconcileSpan).To(Equal(recorder.Ended()[0].SpanContext()))
+			})
+		})
+
 		Context("should update prometheus metrics", func() {
 			It("should requeue a Request if there is an error and continue processing items", func() {
 				var reconcileErrs dto.Metric
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dryrun contains the building blocks of the dry-run mode of the
+// manager, in which all writes are sent as server-side dry-run requests.
+package dryrun
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/audit"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// SuppressedWrites is a prometheus counter metrics which holds the total
+	// number of writes that were only sent as dry-run requests in dry-run mode,
+	// per controller and operation.
+	SuppressedWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_dry_run_suppressed_writes_total",
+		Help: "Total number of writes suppressed in dry-run mode per controller and operation",
+	}, []string{"controller", "operation"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(SuppressedWrites)
+}
+
+// NewClient returns a client that sends all writes of c as server-side dry-run
+// requests. Every write is logged to log, including the diff or patch it would
+// have applied, and counted in SuppressedWrites.
+func NewClient(c client.Client, log logr.Logger) client.Client {
+	logSink := audit.NewLogSink(log)
+	return audit.NewClient(client.NewDryRunClient(c), audit.SinkFunc(func(ctx context.Context, entry audit.Entry) error {
+		SuppressedWrites.WithLabelValues(entry.Controller, string(entry.Operation)).Inc()
+		return logSink.Record(ctx, entry)
+	}), audit.Options{DiffUpdates: true})
+}
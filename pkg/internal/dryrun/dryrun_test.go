@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/internal/dryrun"
+)
+
+var _ = Describe("NewClient", func() {
+	It("should only send writes as dry-run requests, log and count them", func() {
+		ctx := context.Background()
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+			Data:       map[string]string{"a": "1"},
+		}
+		fakeClient := fake.NewClientBuilder().WithObjects(cm).Build()
+
+		var logged []string
+		log := funcr.New(func(prefix, args string) { logged = append(logged, args) }, funcr.Options{})
+		c := dryrun.NewClient(fakeClient, log)
+
+		suppressed := dryrun.SuppressedWrites.WithLabelValues("", "Update")
+		before := testutil.ToFloat64(suppressed)
+
+		updated := cm.DeepCopy()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), updated)).To(Succeed())
+		updated.Data["a"] = "2"
+		Expect(c.Update(ctx, updated)).To(Succeed())
+
+		actual := &corev1.ConfigMap{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cm), actual)).To(Succeed())
+		Expect(actual.Data).To(HaveKeyWithValue("a", "1"))
+
+		Expect(testutil.ToFloat64(suppressed)).To(Equal(before + 1))
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0]).To(ContainSubstring(`"operation"="Update"`))
+		Expect(logged[0]).To(ContainSubstring(`"diff"={"data":{"a":"2"}}`))
+	})
+})
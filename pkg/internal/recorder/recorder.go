@@ -24,10 +24,14 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/internal/dryrun"
 )
 
 // EventBroadcasterProducer makes an event broadcaster, returning
@@ -46,8 +50,12 @@ type Provider struct {
 	// logger is the logger to use when logging diagnostic event info
 	logger          logr.Logger
 	evtClient       corev1client.EventInterface
+	eventsGetter    corev1client.EventsGetter
 	makeBroadcaster EventBroadcasterProducer
 
+	// dryRun makes the provider send all events as dry-run requests
+	dryRun bool
+
 	broadcasterOnce sync.Once
 	broadcaster     record.EventBroadcaster
 	stopBroadcaster bool
@@ -98,7 +106,11 @@ func (p *Provider) getBroadcaster() record.EventBroadcaster {
 
 	p.broadcasterOnce.Do(func() {
 		broadcaster, stop := p.makeBroadcaster()
-		broadcaster.StartRecordingToSink(&corev1client.EventSinkImpl{Interface: p.evtClient})
+		var sink record.EventSink = &corev1client.EventSinkImpl{Interface: p.evtClient}
+		if p.dryRun {
+			sink = &dryRunEventSink{events: p.eventsGetter}
+		}
+		broadcaster.StartRecordingToSink(sink)
 		broadcaster.StartEventWatcher(
 			func(e *corev1.Event) {
 				p.logger.V(1).Info(e.Message, "type", e.Type, "object", e.InvolvedObject, "reason", e.Reason)
@@ -121,10 +133,44 @@ func NewProvider(config *rest.Config, httpClient *http.Client, scheme *runtime.S
 		return nil, fmt.Errorf("failed to init client: %w", err)
 	}
 
-	p := &Provider{scheme: scheme, logger: logger, makeBroadcaster: makeBroadcaster, evtClient: corev1Client.Events("")}
+	p := &Provider{scheme: scheme, logger: logger, makeBroadcaster: makeBroadcaster, evtClient: corev1Client.Events(""), eventsGetter: corev1Client}
 	return p, nil
 }
 
+// SetDryRun makes the provider send all events as server-side dry-run requests,
+// so that they are validated, but not persisted. It must be called before any
+// event is emitted.
+func (p *Provider) SetDryRun() {
+	p.dryRun = true
+}
+
+// dryRunEventSink is a record.EventSink that sends all events as server-side
+// dry-run requests and counts them as suppressed writes of the component that
+// emitted them.
+type dryRunEventSink struct {
+	events corev1client.EventsGetter
+}
+
+var dryRunAll = []string{metav1.DryRunAll}
+
+// Create implements record.EventSink.
+func (s *dryRunEventSink) Create(event *corev1.Event) (*corev1.Event, error) {
+	dryrun.SuppressedWrites.WithLabelValues(event.Source.Component, "Create").Inc()
+	return s.events.Events(event.Namespace).Create(context.TODO(), event, metav1.CreateOptions{DryRun: dryRunAll})
+}
+
+// Update implements record.EventSink.
+func (s *dryRunEventSink) Update(event *corev1.Event) (*corev1.Event, error) {
+	dryrun.SuppressedWrites.WithLabelValues(event.Source.Component, "Update").Inc()
+	return s.events.Events(event.Namespace).Update(context.TODO(), event, metav1.UpdateOptions{DryRun: dryRunAll})
+}
+
+// Patch implements record.EventSink.
+func (s *dryRunEventSink) Patch(event *corev1.Event, data []byte) (*corev1.Event, error) {
+	dryrun.SuppressedWrites.WithLabelValues(event.Source.Component, "Patch").Inc()
+	return s.events.Events(event.Namespace).Patch(context.TODO(), event.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{DryRun: dryRunAll})
+}
+
 // GetEventRecorderFor returns an event recorder that broadcasts to this provider's
 // broadcaster.  All events will be associated with a component of the given name.
 func (p *Provider) GetEventRecorderFor(name string) record.EventRecorder {
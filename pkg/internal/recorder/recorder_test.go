@@ -17,11 +17,17 @@ limitations under the License.
 package recorder_test
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/internal/dryrun"
 	"sigs.k8s.io/controller-runtime/pkg/internal/recorder"
 )
 
@@ -52,4 +58,22 @@ var _ = Describe("recorder.Provider", func() {
 			Expect(recorder).NotTo(BeNil())
 		})
 	})
+	Describe("SetDryRun", func() {
+		It("should only send events as dry-run requests", func() {
+			provider, err := recorder.NewProvider(cfg, httpClient, scheme.Scheme, logr.Discard(), makeBroadcaster)
+			Expect(err).NotTo(HaveOccurred())
+			provider.SetDryRun()
+			defer provider.Stop(context.Background())
+
+			suppressed := dryrun.SuppressedWrites.WithLabelValues("dry-run-recorder", "Create")
+			before := testutil.ToFloat64(suppressed)
+			obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dry-run", Namespace: "default", UID: "dry-run-uid"}}
+			provider.GetEventRecorderFor("dry-run-recorder").Event(obj, corev1.EventTypeNormal, "dry-run-reason", "test-msg")
+			Eventually(func() float64 { return testutil.ToFloat64(suppressed) }).Should(Equal(before + 1))
+
+			events, err := clientset.CoreV1().Events("default").List(context.Background(), metav1.ListOptions{FieldSelector: "reason=dry-run-reason"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events.Items).To(BeEmpty())
+		})
+	})
 })
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -59,6 +60,7 @@ const (
 )
 
 var _ Runnable = &controllerManager{}
+var _ TracerProviderGetter = &controllerManager{}
 
 type controllerManager struct {
 	sync.Mutex
@@ -110,6 +112,9 @@ type controllerManager struct {
 	// If none is set, it defaults to log.Log global logger.
 	logger logr.Logger
 
+	// tracerProvider is the TracerProvider used by the controllers and webhooks of this manager.
+	tracerProvider trace.TracerProvider
+
 	// leaderElectionStopped is an internal channel used to signal the stopping procedure that the
 	// LeaderElection.Run(...) function has returned and the shutdown can proceed.
 	leaderElectionStopped chan struct{}
@@ -269,6 +274,10 @@ func (cm *controllerManager) GetControllerOptions() config.Controller {
 	return cm.controllerConfig
 }
 
+func (cm *controllerManager) GetTracerProvider() trace.TracerProvider {
+	return cm.tracerProvider
+}
+
 func (cm *controllerManager) addHealthProbeServer() error {
 	mux := http.NewServeMux()
 	srv := httpserver.New(mux)
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -89,6 +90,15 @@ type Manager interface {
 	GetControllerOptions() config.Controller
 }
 
+// TracerProviderGetter is implemented by managers that have a TracerProvider,
+// like the ones returned by New. Controllers and webhooks that are built with
+// such a manager use its TracerProvider unless they have one set themselves.
+type TracerProviderGetter interface {
+	// GetTracerProvider returns the TracerProvider used by this manager and the
+	// controllers and webhooks registered with it.
+	GetTracerProvider() trace.TracerProvider
+}
+
 // Options are the arguments for creating a new Manager.
 type Options struct {
 	// Scheme is the scheme used to resolve runtime.Objects to GroupVersionKinds / Resources.
@@ -137,6 +147,24 @@ type Options struct {
 	// If none is set, it defaults to log.Log global logger.
 	Logger logr.Logger
 
+	// TracerProvider is used to create OpenTelemetry spans for the calls of the
+	// default Client and API Reader and for the reconciliations of controllers and
+	// the requests of webhooks created with the builder.
+	// If none is set, it defaults to a no-op TracerProvider and nothing is traced.
+	TracerProvider trace.TracerProvider
+
+	// DryRun runs the manager in observe-only mode: all writes of the Client of
+	// the manager and of its event recorders are sent as server-side dry-run
+	// requests, so that they are validated by the API server, but not persisted.
+	// This includes the writes of finalizer helpers, which use the Client.
+	// Every write is logged, including the diff or patch it would have applied,
+	// and counted in the controller_runtime_dry_run_suppressed_writes_total
+	// metric per controller.
+	//
+	// Leader election is not affected by DryRun, so a manager in dry-run mode
+	// must not use the leader election ID of the manager it is observing.
+	DryRun bool
+
 	// LeaderElection determines whether or not to use leader election when
 	// starting the manager.
 	LeaderElection bool
@@ -326,6 +354,8 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		clusterOptions.Cache = options.Cache
 		clusterOptions.Client = options.Client
 		clusterOptions.EventBroadcaster = options.EventBroadcaster //nolint:staticcheck
+		clusterOptions.TracerProvider = options.TracerProvider
+		clusterOptions.DryRun = options.DryRun
 	})
 	if err != nil {
 		return nil, err
@@ -343,6 +373,9 @@ func New(config *rest.Config, options Options) (Manager, error) {
 	if err != nil {
 		return nil, err
 	}
+	if options.DryRun {
+		recorderProvider.SetDryRun()
+	}
 
 	// Create the resource lock to enable leader election)
 	var leaderConfig *rest.Config
@@ -357,6 +390,9 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		if err != nil {
 			return nil, err
 		}
+		if options.DryRun {
+			leaderRecorderProvider.SetDryRun()
+		}
 	}
 
 	var resourceLock resourcelock.Interface
@@ -397,6 +433,11 @@ func New(config *rest.Config, options Options) (Manager, error) {
 	errChan := make(chan error)
 	runnables := newRunnables(options.BaseContext, errChan)
 
+	tracerProvider := options.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = trace.NewNoopTracerProvider()
+	}
+
 	return &controllerManager{
 		stopProcedureEngaged:          pointer.Int64(0),
 		cluster:                       cluster,
@@ -407,6 +448,7 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		metricsServer:                 metricsServer,
 		controllerConfig:              options.Controller,
 		logger:                        options.Logger,
+		tracerProvider:                tracerProvider,
 		elected:                       make(chan struct{}),
 		webhookServer:                 options.WebhookServer,
 		leaderElectionID:              options.LeaderElectionID,
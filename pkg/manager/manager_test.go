@@ -37,6 +37,7 @@ import (
 	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -112,6 +113,16 @@ var _ = Describe("manger.Manager", func() {
 			Expect(m.GetClient()).To(BeNil())
 		})
 
+		It("should send all writes of the client as dry-run requests in dry-run mode", func() {
+			m, err := New(cfg, Options{DryRun: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dry-run", Namespace: "default"}}
+			Expect(m.GetClient().Create(context.Background(), cm)).To(Succeed())
+			err = m.GetAPIReader().Get(context.Background(), client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
 		It("should return an error it can't create a recorder.Provider", func() {
 			m, err := New(cfg, Options{
 				newRecorderProvider: func(_ *rest.Config, _ *http.Client, _ *runtime.Scheme, _ logr.Logger, _ intrec.EventBroadcasterProducer) (*intrec.Provider, error) {
@@ -24,6 +24,9 @@ import (
 	"sync"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gomodules.xyz/jsonpatch/v2"
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,6 +42,9 @@ var (
 	errUnableToEncodeResponse = errors.New("unable to encode response")
 )
 
+// tracerName is the name of the tracer used for admission webhooks.
+const tracerName = "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
 // Request defines the input for an admission handler.
 // It contains information to identify the object in
 // question (group, version, kind, resource, subresource,
@@ -136,6 +142,10 @@ type Webhook struct {
 	// outside the context of requests.
 	LogConstructor func(base logr.Logger, req *Request) logr.Logger
 
+	// TracerProvider is used to create a span for every admission request the webhook handles.
+	// Defaults to a no-op TracerProvider, i.e. no spans are recorded.
+	TracerProvider trace.TracerProvider
+
 	setupLogOnce sync.Once
 	log          logr.Logger
 }
@@ -151,6 +161,15 @@ func (wh *Webhook) WithRecoverPanic(recoverPanic bool) *Webhook {
 // If the webhook is validating type, it delegates the AdmissionRequest to each handler and
 // deny the request if anyone denies.
 func (wh *Webhook) Handle(ctx context.Context, req Request) (response Response) {
+	ctx, span := wh.startSpan(ctx, &req)
+	defer func() {
+		span.SetAttributes(attribute.Bool("allowed", response.Allowed))
+		if response.Result != nil && response.Result.Code >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, response.Result.Message)
+		}
+		span.End()
+	}()
+
 	if wh.RecoverPanic {
 		defer func() {
 			if r := recover(); r != nil {
@@ -175,6 +194,27 @@ func (wh *Webhook) Handle(ctx context.Context, req Request) (response Response)
 	return resp
 }
 
+// noopTracer is used to trace requests if no TracerProvider is set.
+var noopTracer = trace.NewNoopTracerProvider().Tracer(tracerName)
+
+// startSpan starts the span for handling the given request with the tracer from the TracerProvider.
+func (wh *Webhook) startSpan(ctx context.Context, req *Request) (context.Context, trace.Span) {
+	tracer := noopTracer
+	if wh.TracerProvider != nil {
+		tracer = wh.TracerProvider.Tracer(tracerName)
+	}
+	return tracer.Start(ctx, "admission.Webhook.Handle",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("namespace", req.Namespace),
+			attribute.String("name", req.Name),
+			attribute.String("resource", req.Resource.String()),
+			attribute.String("operation", string(req.Operation)),
+			attribute.String("requestID", string(req.UID)),
+		),
+	)
+}
+
 // getLogger constructs a logger from the injected log and LogConstructor.
 func (wh *Webhook) getLogger(req *Request) logr.Logger {
 	wh.setupLogOnce.Do(func() {
@@ -25,6 +25,11 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"gomodules.xyz/jsonpatch/v2"
 	admissionv1 "k8s.io/api/admission/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
@@ -198,6 +203,95 @@ var _ = Describe("Admission Webhooks", func() {
 		Eventually(logBuffer).Should(gbytes.Say(`"msg":"Received request","operation":"CREATE","requestID":"test123"}`))
 	})
 
+	Describe("tracing", func() {
+		var recorder *tracetest.SpanRecorder
+		var tracerProvider *sdktrace.TracerProvider
+
+		BeforeEach(func() {
+			recorder = tracetest.NewSpanRecorder()
+			tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		})
+
+		It("should create a span for every request", func() {
+			By("setting up a webhook with a TracerProvider")
+			webhook := allowHandler()
+			webhook.TracerProvider = tracerProvider
+
+			By("invoking the webhook")
+			resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       "test123",
+				Name:      "foo",
+				Namespace: "bar",
+				Operation: admissionv1.Create,
+				Resource: metav1.GroupVersionResource{
+					Group:    "apps",
+					Version:  "v1",
+					Resource: "deployments",
+				},
+			}})
+			Expect(resp.Allowed).To(BeTrue())
+
+			By("checking that a span was recorded with the request's attributes")
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Name()).To(Equal("admission.Webhook.Handle"))
+			Expect(spans[0].Attributes()).To(ContainElements(
+				attribute.String("namespace", "bar"),
+				attribute.String("name", "foo"),
+				attribute.String("resource", "apps/v1, Resource=deployments"),
+				attribute.String("operation", "CREATE"),
+				attribute.String("requestID", "test123"),
+				attribute.Bool("allowed", true),
+			))
+			Expect(spans[0].Status().Code).To(Equal(codes.Unset))
+		})
+
+		It("should pass the span to the handler via the context", func() {
+			By("setting up a webhook with a handler that records the span from the context")
+			var handlerSpan trace.SpanContext
+			webhook := &Webhook{
+				Handler: &fakeHandler{
+					fn: func(ctx context.Context, req Request) Response {
+						handlerSpan = trace.SpanContextFromContext(ctx)
+						return Allowed("")
+					},
+				},
+				TracerProvider: tracerProvider,
+			}
+
+			By("invoking the webhook")
+			webhook.Handle(context.Background(), Request{})
+
+			By("checking that the handler got the recorded span")
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(handlerSpan).To(Equal(spans[0].SpanContext()))
+		})
+
+		It("should mark the span as failed if the panic of the handler is recovered", func() {
+			By("setting up a webhook with a panicking handler")
+			webhook := &Webhook{
+				Handler: &fakeHandler{
+					fn: func(ctx context.Context, req Request) Response {
+						panic("fake panic test")
+					},
+				},
+				RecoverPanic:   true,
+				TracerProvider: tracerProvider,
+			}
+
+			By("invoking the webhook")
+			webhook.Handle(context.Background(), Request{})
+
+			By("checking that the span records the error")
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Attributes()).To(ContainElement(attribute.Bool("allowed", false)))
+			Expect(spans[0].Status().Code).To(Equal(codes.Error))
+			Expect(spans[0].Status().Description).To(Equal("panic: fake panic test [recovered]"))
+		})
+	})
+
 	Describe("panic recovery", func() {
 		It("should recover panic if RecoverPanic is true", func() {
 			panicHandler := func() *Webhook {